@@ -14,16 +14,21 @@ import (
 	"golang.org/x/exp/slog"
 
 	"github.com/joho/godotenv"
+	"github.com/mikepersonal/speed-reader/backend/internal/account"
 	"github.com/mikepersonal/speed-reader/backend/internal/auth"
 	"github.com/mikepersonal/speed-reader/backend/internal/config"
 	"github.com/mikepersonal/speed-reader/backend/internal/database"
 	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/goals"
 	httpHandler "github.com/mikepersonal/speed-reader/backend/internal/http"
+	"github.com/mikepersonal/speed-reader/backend/internal/library"
 	"github.com/mikepersonal/speed-reader/backend/internal/logging"
 	"github.com/mikepersonal/speed-reader/backend/internal/settings"
 	"github.com/mikepersonal/speed-reader/backend/internal/sharing"
+	"github.com/mikepersonal/speed-reader/backend/internal/stats"
 	"github.com/mikepersonal/speed-reader/backend/internal/storage"
 	"github.com/mikepersonal/speed-reader/backend/internal/telemetry"
+	"github.com/mikepersonal/speed-reader/backend/internal/webfetch"
 )
 
 const (
@@ -135,19 +140,53 @@ func main() {
 	docRepo := documents.NewRepository(db)
 	docService := documents.NewService(docRepo, chunkStore, cfg.GuestDocTTLDays)
 
+	// Start background document processing workers
+	processor := documents.NewProcessor(docService, documents.ProcessorConfig{
+		Workers:      cfg.DocWorkers,
+		PerUserLimit: cfg.DocWorkerUserLimit,
+	}, logger)
+	processor.Start()
+	logger.Info("document processor started",
+		slog.Int("workers", cfg.DocWorkers),
+		slog.Int("per_user_limit", cfg.DocWorkerUserLimit),
+	)
+
 	// Initialize sharing service
 	sharingService := sharing.NewService(db, cfg.FrontendURL)
 
+	// Initialize reading statistics
+	statsRepo := stats.NewRepository(db)
+	statsService := stats.NewService(statsRepo)
+
+	// Initialize reading goals
+	goalRepo := goals.NewRepository(db)
+	goalService := goals.NewService(goalRepo, statsRepo)
+
 	// Initialize settings service
 	settingsRepo := settings.NewRepository(db)
 	settingsService := settings.NewService(settingsRepo)
 
+	// Initialize tags and collections
+	libraryRepo := library.NewRepository(db)
+	libraryService := library.NewService(libraryRepo)
+
+	// Initialize account export and import
+	accountService := account.NewService(docService, settingsService)
+
+	// Initialize URL import fetcher (blocks private and internal addresses)
+	fetcher := webfetch.New(webfetch.Config{})
+
 	// Create router with all dependencies
 	router := httpHandler.NewRouter(&httpHandler.RouterDeps{
 		DocService:      docService,
 		AuthService:     authService,
 		SharingService:  sharingService,
 		SettingsService: settingsService,
+		GoalService:     goalService,
+		LibraryService:  libraryService,
+		StatsService:    statsService,
+		AccountService:  accountService,
+		Fetcher:         fetcher,
 		FrontendURL:     cfg.FrontendURL,
 		SecureCookie:    cfg.SecureCookie,
 		Logger:          logger,
@@ -190,6 +229,11 @@ func main() {
 		logger.Error("server shutdown failed", slog.String("error", err.Error()))
 	}
 
+	// Drain in-flight document jobs; unfinished ones are reclaimed after their lease
+	if err := processor.Shutdown(shutdownCtx); err != nil {
+		logger.Error("document processor shutdown failed", slog.String("error", err.Error()))
+	}
+
 	// Shutdown telemetry
 	if err := otelShutdown(shutdownCtx); err != nil {
 		logger.Error("telemetry shutdown failed", slog.String("error", err.Error()))
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/config"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+)
+
+// Sets the content hash used for duplicate detection on documents created
+// before it was stored. Documents without stored content can't be hashed and
+// are left alone.
+func main() {
+	dryRun := flag.Bool("dry-run", true, "Run in dry-run mode (don't write changes)")
+	batchSize := flag.Int("batch-size", 500, "Number of documents read per batch")
+	flag.Parse()
+
+	log.Println("Content Hash Backfill")
+	log.Println("=====================")
+	log.Printf("Dry Run: %v", *dryRun)
+	log.Printf("Batch Size: %d", *batchSize)
+	log.Println()
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Connect to database
+	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		log.Fatalf("Failed to ping database: %v", err)
+	}
+
+	docRepo := documents.NewRepository(db)
+	ctx := context.Background()
+	startTime := time.Now()
+
+	var hashed, errors int
+	after := uuid.Nil
+	for {
+		batch, err := docRepo.ListUnhashed(ctx, after, *batchSize)
+		if err != nil {
+			log.Fatalf("Failed to list documents: %v", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, doc := range batch {
+			after = doc.ID
+			if *dryRun {
+				hashed++
+				continue
+			}
+			if err := docRepo.SetContentHash(ctx, doc.ID, documents.ContentHash(doc.Content)); err != nil {
+				log.Printf("Error hashing doc %s: %v", doc.ID, err)
+				errors++
+				continue
+			}
+			hashed++
+		}
+
+		log.Printf("Hashed %d documents so far", hashed)
+	}
+
+	// Print summary
+	log.Println()
+	log.Println("Backfill Summary")
+	log.Println("================")
+	log.Printf("Documents Hashed: %d", hashed)
+	log.Printf("Errors: %d", errors)
+	log.Printf("Duration: %v", time.Since(startTime))
+
+	if *dryRun {
+		log.Println()
+		log.Println("This was a dry run. No documents were modified.")
+		log.Println("Run with --dry-run=false to apply changes.")
+	}
+}
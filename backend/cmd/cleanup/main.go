@@ -56,7 +56,33 @@ func main() {
 		}
 	}
 
+	// Purge documents that have sat in the trash past the retention period
+	cutoff := time.Now().AddDate(0, 0, -cfg.TrashRetentionDays)
+	purgedIDs, err := docRepo.PurgeTrash(ctx, cutoff)
+	if err != nil {
+		log.Fatalf("Failed to purge trash: %v", err)
+	}
+
+	log.Printf("Purged %d documents deleted before %s from trash", len(purgedIDs), cutoff.Format(time.RFC3339))
+
+	for _, id := range purgedIDs {
+		if err := chunkStore.DeleteDocument(id); err != nil {
+			log.Printf("Warning: failed to delete chunks for document %s: %v", id, err)
+			chunkDeleteErrors++
+		}
+	}
+
+	// Forget deletions old enough that no valid sync cursor predates them
+	tombstoneCutoff := time.Now().Add(-documents.TombstoneRetention)
+	purgedTombstones, err := docRepo.PurgeTombstones(ctx, tombstoneCutoff)
+	if err != nil {
+		log.Printf("Warning: failed to purge tombstones: %v", err)
+	} else {
+		log.Printf("Purged %d tombstones recorded before %s", purgedTombstones, tombstoneCutoff.Format(time.RFC3339))
+	}
+
 	duration := time.Since(startTime)
 	log.Printf("Cleanup completed in %v", duration)
-	log.Printf("Summary: %d documents deleted, %d chunk deletion errors", len(deletedIDs), chunkDeleteErrors)
+	log.Printf("Summary: %d expired documents deleted, %d trashed documents purged, %d chunk deletion errors",
+		len(deletedIDs), len(purgedIDs), chunkDeleteErrors)
 }
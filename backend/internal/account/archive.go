@@ -0,0 +1,206 @@
+package account
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/settings"
+)
+
+// errContentTooLarge indicates a document's text exceeds the size limit
+var errContentTooLarge = errors.New("content too large")
+
+// contentPath is where a document's text is stored in an archive
+func contentPath(id uuid.UUID) string {
+	return "documents/" + id.String() + ".txt"
+}
+
+// newDocumentEntry describes an exported document in the manifest
+func newDocumentEntry(doc *documents.ExportedDocument) DocumentEntry {
+	entry := DocumentEntry{
+		ID:          doc.ID,
+		Title:       doc.Title,
+		SourceType:  doc.SourceType,
+		Author:      doc.Author,
+		SourceURL:   doc.SourceURL,
+		Language:    doc.Language,
+		Visibility:  doc.Visibility,
+		CreatedAt:   doc.CreatedAt,
+		TokenCount:  doc.TokenCount,
+		ContentFile: contentPath(doc.ID),
+	}
+	if rs := doc.ReadingState; rs != nil {
+		entry.ReadingState = &ReadingStateEntry{
+			TokenIndex: rs.TokenIndex,
+			WPM:        rs.WPM,
+			ChunkSize:  rs.ChunkSize,
+			UpdatedAt:  rs.UpdatedAt,
+		}
+	}
+	return entry
+}
+
+// writeJSONFile adds a JSON file to an archive
+func writeJSONFile(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeTextFile adds a text file to an archive
+func writeTextFile(zw *zip.Writer, name, text string) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := io.WriteString(f, text); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// archive is an uploaded account export
+type archive struct {
+	manifest Manifest
+	settings *settings.Settings // nil if the archive has none
+	files    map[string]*zip.File
+}
+
+// openArchive reads and validates the manifest of an account export
+func openArchive(r io.ReaderAt, size int64) (*archive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a zip file", ErrInvalidArchive)
+	}
+
+	a := &archive{files: make(map[string]*zip.File, len(zr.File))}
+	for _, f := range zr.File {
+		a.files[f.Name] = f
+	}
+
+	mf, ok := a.files[manifestFile]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is missing", ErrInvalidArchive, manifestFile)
+	}
+	if err := readJSONFile(mf, &a.manifest); err != nil {
+		return nil, err
+	}
+
+	switch m := &a.manifest; {
+	case m.Version < 1 || m.Version > ArchiveVersion:
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidArchive, m.Version)
+	case len(m.Documents) > MaxImportDocuments:
+		return nil, fmt.Errorf("%w: more than %d documents", ErrInvalidArchive, MaxImportDocuments)
+	}
+
+	if sf, ok := a.files[settingsFile]; ok {
+		a.settings = &settings.Settings{}
+		if err := readJSONFile(sf, a.settings); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// readJSONFile decodes a JSON file from an archive
+func readJSONFile(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: cannot read %s", ErrInvalidArchive, f.Name)
+	}
+	defer rc.Close()
+
+	if err := json.NewDecoder(io.LimitReader(rc, maxMetadataSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s is malformed", ErrInvalidArchive, f.Name)
+	}
+	return nil
+}
+
+// readContent returns a document's text from the archive, refusing text over
+// limit bytes whatever size the archive claims for it
+func (a *archive) readContent(entry DocumentEntry, limit int64) (string, error) {
+	f, ok := a.files[entry.ContentFile]
+	if !ok {
+		return "", errors.New("content missing from archive")
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("cannot read content: %w", err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return "", fmt.Errorf("cannot read content: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return "", errContentTooLarge
+	}
+	if !utf8.Valid(data) {
+		return "", errors.New("content is not valid UTF-8")
+	}
+
+	return string(data), nil
+}
+
+// importSourceType keeps a recorded source type this server knows, treating
+// anything else as pasted text
+func importSourceType(t documents.SourceType) documents.SourceType {
+	switch t {
+	case documents.SourceEPUB, documents.SourceHTML, documents.SourceDOCX, documents.SourceODT, documents.SourcePDF:
+		return t
+	default:
+		return documents.SourcePaste
+	}
+}
+
+// importReadingState converts an exported position for a new document,
+// keeping it within the exported token count and falling back to the
+// defaults for values that can't be right
+func importReadingState(docID uuid.UUID, entry *ReadingStateEntry, tokenCount int) *documents.ReadingState {
+	state := &documents.ReadingState{
+		DocID:      docID,
+		TokenIndex: max(min(entry.TokenIndex, tokenCount), 0),
+		WPM:        entry.WPM,
+		ChunkSize:  entry.ChunkSize,
+	}
+	if state.WPM <= 0 {
+		state.WPM = 300
+	}
+	if state.ChunkSize <= 0 {
+		state.ChunkSize = 1
+	}
+	return state
+}
+
+// settingsUpdate expresses exported settings as a full update so they go
+// through the same validation as a PUT /api/settings
+func settingsUpdate(s *settings.Settings) *settings.UpdateSettingsRequest {
+	return &settings.UpdateSettingsRequest{
+		DefaultWPM:       &s.DefaultWPM,
+		DefaultChunkSize: &s.DefaultChunkSize,
+		AutoPlayOnOpen:   &s.AutoPlayOnOpen,
+		PauseMultipliers: &settings.PauseMultipliersUpdate{
+			Comma:     &s.PauseMultipliers.Comma,
+			Sentence:  &s.PauseMultipliers.Sentence,
+			Paragraph: &s.PauseMultipliers.Paragraph,
+		},
+		FontSize: &s.FontSize,
+	}
+}
@@ -0,0 +1,170 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/settings"
+)
+
+// buildArchive writes a zip holding the given files, JSON-encoding non-strings
+func buildArchive(t *testing.T, files map[string]interface{}) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, v := range files {
+		var err error
+		if text, ok := v.(string); ok {
+			err = writeTextFile(zw, name, text)
+		} else {
+			err = writeJSONFile(zw, name, v)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	doc := &documents.ExportedDocument{
+		Document: documents.Document{
+			ID:         uuid.New(),
+			Title:      "Essay",
+			SourceType: documents.SourceEPUB,
+			Author:     "A. Writer",
+			Visibility: documents.VisibilityPrivate,
+			CreatedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			TokenCount: 3,
+		},
+		ReadingState: &documents.ReadingState{TokenIndex: 2, WPM: 450, ChunkSize: 2},
+	}
+	entry := newDocumentEntry(doc)
+	userSettings := settings.DefaultSettings()
+	userSettings.DefaultWPM = 500
+
+	r := buildArchive(t, map[string]interface{}{
+		manifestFile:      Manifest{Version: ArchiveVersion, Documents: []DocumentEntry{entry}},
+		settingsFile:      userSettings,
+		entry.ContentFile: "One two three.",
+	})
+
+	a, err := openArchive(r, r.Size())
+	if err != nil {
+		t.Fatalf("openArchive() error = %v", err)
+	}
+	if len(a.manifest.Documents) != 1 {
+		t.Fatalf("expected 1 document, got %d", len(a.manifest.Documents))
+	}
+	got := a.manifest.Documents[0]
+	if got.ID != doc.ID || got.Title != "Essay" || got.Author != "A. Writer" || got.SourceType != documents.SourceEPUB {
+		t.Errorf("metadata not preserved: %+v", got)
+	}
+	if got.ReadingState == nil || got.ReadingState.TokenIndex != 2 || got.ReadingState.WPM != 450 {
+		t.Errorf("reading state not preserved: %+v", got.ReadingState)
+	}
+	if a.settings == nil || a.settings.DefaultWPM != 500 {
+		t.Errorf("settings not preserved: %+v", a.settings)
+	}
+
+	content, err := a.readContent(got, 1024)
+	if err != nil || content != "One two three." {
+		t.Errorf("readContent() = %q, %v", content, err)
+	}
+}
+
+func TestOpenArchiveRejectsInvalidUploads(t *testing.T) {
+	tests := map[string]*bytes.Reader{
+		"not a zip":        bytes.NewReader([]byte("plain text")),
+		"missing manifest": buildArchive(t, map[string]interface{}{settingsFile: settings.DefaultSettings()}),
+		"future version":   buildArchive(t, map[string]interface{}{manifestFile: Manifest{Version: ArchiveVersion + 1}}),
+		"malformed":        buildArchive(t, map[string]interface{}{manifestFile: "{not json"}),
+	}
+
+	for name, r := range tests {
+		if _, err := openArchive(r, r.Size()); !errors.Is(err, ErrInvalidArchive) {
+			t.Errorf("%s: expected ErrInvalidArchive, got %v", name, err)
+		}
+	}
+}
+
+func TestOpenArchiveWithoutSettings(t *testing.T) {
+	r := buildArchive(t, map[string]interface{}{manifestFile: Manifest{Version: ArchiveVersion}})
+
+	a, err := openArchive(r, r.Size())
+	if err != nil {
+		t.Fatalf("openArchive() error = %v", err)
+	}
+	if a.settings != nil {
+		t.Errorf("expected no settings, got %+v", a.settings)
+	}
+}
+
+func TestReadContentLimits(t *testing.T) {
+	entry := DocumentEntry{ContentFile: contentPath(uuid.New())}
+	r := buildArchive(t, map[string]interface{}{
+		manifestFile:      Manifest{Version: ArchiveVersion, Documents: []DocumentEntry{entry}},
+		entry.ContentFile: "0123456789",
+	})
+	a, err := openArchive(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.readContent(entry, 9); !errors.Is(err, errContentTooLarge) {
+		t.Errorf("expected errContentTooLarge over the limit, got %v", err)
+	}
+	if _, err := a.readContent(entry, 10); err != nil {
+		t.Errorf("expected content at the limit to be read, got %v", err)
+	}
+	if _, err := a.readContent(DocumentEntry{ContentFile: "documents/missing.txt"}, 10); err == nil {
+		t.Error("expected an error for missing content")
+	}
+}
+
+func TestImportSourceType(t *testing.T) {
+	if got := importSourceType(documents.SourcePDF); got != documents.SourcePDF {
+		t.Errorf("importSourceType(pdf) = %q", got)
+	}
+	if got := importSourceType("scroll"); got != documents.SourcePaste {
+		t.Errorf("importSourceType(scroll) = %q, want paste", got)
+	}
+}
+
+func TestImportReadingState(t *testing.T) {
+	docID := uuid.New()
+
+	state := importReadingState(docID, &ReadingStateEntry{TokenIndex: 900, WPM: 0, ChunkSize: -1}, 500)
+	if state.DocID != docID || state.TokenIndex != 500 || state.WPM != 300 || state.ChunkSize != 1 {
+		t.Errorf("unexpected state %+v", state)
+	}
+
+	state = importReadingState(docID, &ReadingStateEntry{TokenIndex: -5, WPM: 420, ChunkSize: 3}, 500)
+	if state.TokenIndex != 0 || state.WPM != 420 || state.ChunkSize != 3 {
+		t.Errorf("unexpected state %+v", state)
+	}
+}
+
+func TestSettingsUpdateAppliesEveryField(t *testing.T) {
+	exported := &settings.Settings{
+		DefaultWPM:       420,
+		DefaultChunkSize: 2,
+		AutoPlayOnOpen:   true,
+		PauseMultipliers: settings.PauseMultipliers{Comma: 1.1, Sentence: 2, Paragraph: 3},
+		FontSize:         settings.FontSizeLarge,
+	}
+
+	merged := settings.DefaultSettings().Merge(settingsUpdate(exported))
+	if *merged != *exported {
+		t.Errorf("Merge(settingsUpdate()) = %+v, want %+v", merged, exported)
+	}
+}
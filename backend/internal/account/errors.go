@@ -0,0 +1,12 @@
+package account
+
+import "errors"
+
+var (
+	// ErrInvalidArchive indicates an upload that isn't a readable account export.
+	ErrInvalidArchive = errors.New("invalid archive")
+
+	// ErrGuestImport indicates a guest trying to import a library, which needs
+	// an account to keep it.
+	ErrGuestImport = errors.New("sign in to import a library")
+)
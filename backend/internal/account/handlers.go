@@ -0,0 +1,151 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+	"golang.org/x/exp/slog"
+)
+
+// maxUploadMemory is how much of an uploaded archive is buffered in memory before spilling to disk
+const maxUploadMemory = 8 << 20
+
+// Handlers contains HTTP handlers for account export and import
+type Handlers struct {
+	service   *Service
+	logger    *slog.Logger
+	sanitizer *logging.Sanitizer
+}
+
+// NewHandlers creates a new account Handlers instance
+func NewHandlers(service *Service, logger *slog.Logger, sanitizer *logging.Sanitizer) *Handlers {
+	return &Handlers{
+		service:   service,
+		logger:    logger,
+		sanitizer: sanitizer,
+	}
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
+
+func mapAccountError(err error, internalMessage string) (status int, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidArchive):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, ErrGuestImport):
+		return http.StatusForbidden, err.Error()
+	}
+
+	return http.StatusInternalServerError, internalMessage
+}
+
+// exportFilename names the downloaded archive after the export date
+func exportFilename(exp *Export) string {
+	return fmt.Sprintf("speed-reader-export-%s.zip", exp.ExportedAt.Format("2006-01-02"))
+}
+
+// Export handles GET /api/account/export
+// Streams a zip of every document's text and metadata, reading positions and settings
+func (h *Handlers) Export(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+
+	if we != nil {
+		we.AddString("user.id", h.sanitizer.UserID(userID.String()))
+	}
+
+	exp, err := h.service.PrepareExport(r.Context(), userID)
+	if err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		status, message := mapAccountError(err, "failed to export account")
+		writeError(w, status, message)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exportFilename(exp)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	// The response has started, so a failure can only cut the archive short
+	skipped, err := h.service.WriteExport(r.Context(), exp, w)
+	if we != nil {
+		we.AddInt("export.documents", len(exp.Documents))
+		we.AddInt("export.skipped", skipped)
+		if err != nil {
+			we.AddError(err)
+		}
+	}
+}
+
+// Import handles POST /api/account/import
+// Accepts an export as a multipart "file" field and adds its documents and
+// settings to the current account
+func (h *Handlers) Import(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusBadRequest, "invalid upload")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+
+	if we != nil {
+		we.AddInt64("import.size", header.Size)
+	}
+
+	result, err := h.service.Import(r.Context(), file, header.Size)
+	if err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		status, message := mapAccountError(err, "failed to import account")
+		writeError(w, status, message)
+		return
+	}
+
+	if we != nil {
+		we.AddInt("import.imported", result.Imported)
+		we.AddInt("import.duplicates", result.Duplicates)
+		we.AddInt("import.failed", result.Failed)
+		we.AddBool("import.settings", result.SettingsImported)
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,44 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMapAccountErrorInvalidArchive(t *testing.T) {
+	err := fmt.Errorf("%w: manifest.json is missing", ErrInvalidArchive)
+
+	status, message := mapAccountError(err, "internal fallback")
+	if status != 400 {
+		t.Fatalf("expected 400 status, got %d", status)
+	}
+	if message != "invalid archive: manifest.json is missing" {
+		t.Fatalf("expected archive problem in message, got %q", message)
+	}
+}
+
+func TestMapAccountErrorGuest(t *testing.T) {
+	status, _ := mapAccountError(ErrGuestImport, "internal fallback")
+	if status != 403 {
+		t.Fatalf("expected 403 status, got %d", status)
+	}
+}
+
+func TestMapAccountErrorInternalFallback(t *testing.T) {
+	status, message := mapAccountError(errors.New("db down"), "internal fallback")
+	if status != 500 {
+		t.Fatalf("expected 500 status, got %d", status)
+	}
+	if message != "internal fallback" {
+		t.Fatalf("expected fallback message, got %q", message)
+	}
+}
+
+func TestExportFilename(t *testing.T) {
+	exp := &Export{ExportedAt: time.Date(2026, 5, 4, 23, 0, 0, 0, time.UTC)}
+	if got, want := exportFilename(exp), "speed-reader-export-2026-05-04.zip"; got != want {
+		t.Fatalf("exportFilename() = %q, want %q", got, want)
+	}
+}
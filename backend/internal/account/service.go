@@ -0,0 +1,188 @@
+package account
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+	"github.com/mikepersonal/speed-reader/backend/internal/config"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/settings"
+)
+
+// Service builds and restores account exports
+type Service struct {
+	docs     *documents.Service
+	settings *settings.Service
+}
+
+// NewService creates a new account service
+func NewService(docService *documents.Service, settingsService *settings.Service) *Service {
+	return &Service{
+		docs:     docService,
+		settings: settingsService,
+	}
+}
+
+// PrepareExport gathers the current user's documents and settings. Nothing is
+// written yet, so a failure here can still be reported as an error response.
+func (s *Service) PrepareExport(ctx context.Context, userID uuid.UUID) (*Export, error) {
+	docs, err := s.docs.ExportDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userSettings, err := s.settings.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Export{
+		ExportedAt: time.Now().UTC(),
+		Settings:   userSettings,
+		Documents:  docs,
+	}, nil
+}
+
+// WriteExport streams an export to w as a zip archive. A document whose text
+// can't be read is listed as skipped in the manifest rather than failing the
+// whole export; the returned count says how many were.
+func (s *Service) WriteExport(ctx context.Context, exp *Export, w io.Writer) (int, error) {
+	zw := zip.NewWriter(w)
+
+	manifest := Manifest{
+		Version:    ArchiveVersion,
+		ExportedAt: exp.ExportedAt,
+		Documents:  make([]DocumentEntry, 0, len(exp.Documents)),
+	}
+
+	for i := range exp.Documents {
+		doc := &exp.Documents[i]
+		if err := ctx.Err(); err != nil {
+			return len(manifest.Skipped), err
+		}
+
+		text, err := s.docs.DocumentText(ctx, &doc.Document)
+		if err != nil {
+			manifest.Skipped = append(manifest.Skipped, SkippedDocument{ID: doc.ID, Title: doc.Title, Reason: "content unavailable"})
+			continue
+		}
+
+		entry := newDocumentEntry(doc)
+		if err := writeTextFile(zw, entry.ContentFile, text); err != nil {
+			return len(manifest.Skipped), err
+		}
+		manifest.Documents = append(manifest.Documents, entry)
+	}
+
+	if err := writeJSONFile(zw, settingsFile, exp.Settings); err != nil {
+		return len(manifest.Skipped), err
+	}
+	if err := writeJSONFile(zw, manifestFile, manifest); err != nil {
+		return len(manifest.Skipped), err
+	}
+
+	if err := zw.Close(); err != nil {
+		return len(manifest.Skipped), fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return len(manifest.Skipped), nil
+}
+
+// Import recreates the documents, reading positions and settings of an
+// account export for the current user. Documents are added alongside any the
+// user already has and come in private; each is queued for processing like a
+// new upload. Text the user already has is matched to their existing copy
+// rather than imported again, so re-importing an export is harmless. One
+// document failing doesn't stop the others.
+func (s *Service) Import(ctx context.Context, r io.ReaderAt, size int64) (*ImportResult, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("user not found in context")
+	}
+	if user.IsGuest {
+		return nil, ErrGuestImport
+	}
+
+	a, err := openArchive(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Documents: make([]ImportedDocument, 0, len(a.manifest.Documents))}
+
+	for _, entry := range a.manifest.Documents {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		item := ImportedDocument{SourceID: entry.ID, Title: entry.Title}
+		id, duplicate, err := s.importDocument(ctx, a, entry)
+		switch {
+		case err != nil:
+			item.Error = err.Error()
+			result.Failed++
+		case duplicate:
+			item.ID = &id
+			item.Duplicate = true
+			result.Duplicates++
+		default:
+			item.ID = &id
+			result.Imported++
+		}
+		result.Documents = append(result.Documents, item)
+	}
+
+	if a.settings != nil {
+		if _, err := s.settings.UpdateSettings(ctx, user.ID, settingsUpdate(a.settings)); err != nil {
+			var validationErr *settings.ValidationError
+			if !errors.As(err, &validationErr) {
+				return nil, fmt.Errorf("failed to import settings: %w", err)
+			}
+			result.SettingsError = validationErr.Error()
+		} else {
+			result.SettingsImported = true
+		}
+	}
+
+	return result, nil
+}
+
+// importDocument creates one document from an archive entry, returning its
+// new ID, or the ID of the user's copy and true if they already have the text.
+// Errors are worded for the import result.
+func (s *Service) importDocument(ctx context.Context, a *archive, entry DocumentEntry) (uuid.UUID, bool, error) {
+	content, err := a.readContent(entry, config.MaxAuthPasteSize)
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	if content == "" {
+		return uuid.Nil, false, errors.New("content is empty")
+	}
+
+	doc, err := s.docs.CreateDocument(ctx, &documents.CreateDocumentInput{
+		Title:      entry.Title,
+		Content:    content,
+		SourceType: importSourceType(entry.SourceType),
+		Author:     entry.Author,
+		SourceURL:  entry.SourceURL,
+	})
+	var dupErr *documents.DuplicateError
+	if errors.As(err, &dupErr) {
+		return dupErr.Existing.ID, true, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, errors.New("failed to create document")
+	}
+
+	// A lost reading position isn't worth failing the document over
+	if entry.ReadingState != nil {
+		_ = s.docs.RestoreReadingState(ctx, importReadingState(doc.ID, entry.ReadingState, entry.TokenCount))
+	}
+
+	return doc.ID, false, nil
+}
@@ -0,0 +1,96 @@
+package account
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/settings"
+)
+
+const (
+	// ArchiveVersion is the export format written by this server. Imports
+	// accept this version and any earlier one.
+	ArchiveVersion = 1
+
+	// MaxImportDocuments caps the documents read from one archive
+	MaxImportDocuments = 5000
+)
+
+// Archive layout: manifest.json lists the documents, settings.json holds the
+// user's preferences and each document's text is in documents/<id>.txt
+const (
+	manifestFile = "manifest.json"
+	settingsFile = "settings.json"
+
+	// maxMetadataSize bounds the decompressed size of the JSON files
+	maxMetadataSize = 16 << 20
+)
+
+// Manifest describes an account export
+type Manifest struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exportedAt"`
+	Documents  []DocumentEntry `json:"documents"`
+
+	// Skipped lists documents whose text couldn't be read at export time
+	Skipped []SkippedDocument `json:"skipped,omitempty"`
+}
+
+// DocumentEntry is one document's metadata in an export
+type DocumentEntry struct {
+	ID           uuid.UUID            `json:"id"`
+	Title        string               `json:"title"`
+	SourceType   documents.SourceType `json:"sourceType"`
+	Author       string               `json:"author,omitempty"`
+	SourceURL    string               `json:"sourceUrl,omitempty"`
+	Language     string               `json:"language,omitempty"`
+	Visibility   documents.Visibility `json:"visibility"`
+	CreatedAt    time.Time            `json:"createdAt"`
+	TokenCount   int                  `json:"tokenCount"`
+	ContentFile  string               `json:"contentFile"`
+	ReadingState *ReadingStateEntry   `json:"readingState,omitempty"`
+}
+
+// ReadingStateEntry is the user's position in an exported document
+type ReadingStateEntry struct {
+	TokenIndex int       `json:"tokenIndex"`
+	WPM        int       `json:"wpm"`
+	ChunkSize  int       `json:"chunkSize"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// SkippedDocument is a document left out of an export
+type SkippedDocument struct {
+	ID     uuid.UUID `json:"id"`
+	Title  string    `json:"title"`
+	Reason string    `json:"reason"`
+}
+
+// Export is everything gathered for an account export before it is written
+type Export struct {
+	ExportedAt time.Time
+	Settings   *settings.Settings
+	Documents  []documents.ExportedDocument
+}
+
+// ImportResult reports what an import created
+type ImportResult struct {
+	Imported         int                `json:"imported"`
+	Duplicates       int                `json:"duplicates"`
+	Failed           int                `json:"failed"`
+	SettingsImported bool               `json:"settingsImported"`
+	SettingsError    string             `json:"settingsError,omitempty"`
+	Documents        []ImportedDocument `json:"documents"`
+}
+
+// ImportedDocument is the outcome for one document in an archive. ID is the
+// new document's ID when it was created, or the ID of the user's existing
+// copy when Duplicate is set; otherwise Error says why it wasn't imported.
+type ImportedDocument struct {
+	SourceID  uuid.UUID  `json:"sourceId"`
+	ID        *uuid.UUID `json:"id,omitempty"`
+	Title     string     `json:"title"`
+	Duplicate bool       `json:"duplicate,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
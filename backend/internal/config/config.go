@@ -12,6 +12,12 @@ const (
 	// MaxAuthPasteSize is the maximum allowed paste size for authenticated users (10MB)
 	MaxAuthPasteSize = 10 * 1024 * 1024
 
+	// MaxUploadSize is the maximum allowed file upload size for authenticated users (25MB)
+	MaxUploadSize = 25 * 1024 * 1024
+
+	// MaxAccountImportSize is the maximum allowed size of an uploaded account export (250MB)
+	MaxAccountImportSize = 250 * 1024 * 1024
+
 	// MaxPasteSize is kept for backward compatibility (same as guest limit)
 	// Deprecated: Use MaxGuestPasteSize or MaxAuthPasteSize instead
 	MaxPasteSize = MaxGuestPasteSize
@@ -21,6 +27,15 @@ const (
 
 	// DefaultGuestDocTTLDays is the default TTL for guest documents
 	DefaultGuestDocTTLDays = 30
+
+	// DefaultTrashRetentionDays is how long deleted documents stay restorable
+	DefaultTrashRetentionDays = 30
+
+	// DefaultDocWorkers is the default number of document processing workers
+	DefaultDocWorkers = 4
+
+	// DefaultDocWorkerUserLimit is the default number of documents processed concurrently per user
+	DefaultDocWorkerUserLimit = 2
 )
 
 // Config holds the application configuration
@@ -37,8 +52,13 @@ type Config struct {
 	CSRFSecret         string
 	FrontendURL        string
 	GuestDocTTLDays    int
+	TrashRetentionDays int // days before trashed documents are purged
 	SecureCookie       bool
 
+	// Background processing configuration
+	DocWorkers         int // number of document processing workers
+	DocWorkerUserLimit int // max documents processed concurrently per user
+
 	// Observability configuration
 	LogLevel     string // debug, info, warn, error
 	LogSalt      string // secret for PII pseudonymization
@@ -58,8 +78,23 @@ func Load() *Config {
 		guestTTL = DefaultGuestDocTTLDays
 	}
 
+	trashRetention, _ := strconv.Atoi(getEnv("TRASH_RETENTION_DAYS", "30"))
+	if trashRetention <= 0 {
+		trashRetention = DefaultTrashRetentionDays
+	}
+
 	secureCookie := getEnv("SECURE_COOKIE", "false") == "true"
 
+	docWorkers, _ := strconv.Atoi(getEnv("DOC_WORKERS", "4"))
+	if docWorkers <= 0 {
+		docWorkers = DefaultDocWorkers
+	}
+
+	docWorkerUserLimit, _ := strconv.Atoi(getEnv("DOC_WORKER_USER_LIMIT", "2"))
+	if docWorkerUserLimit <= 0 {
+		docWorkerUserLimit = DefaultDocWorkerUserLimit
+	}
+
 	// Check SERVER_PORT first (to avoid Railway PostgreSQL PORT conflict), then PORT
 	port := getEnv("SERVER_PORT", "")
 	if port == "" {
@@ -77,7 +112,10 @@ func Load() *Config {
 		CSRFSecret:         getEnv("CSRF_SECRET", "dev-csrf-secret-change-in-production"),
 		FrontendURL:        getEnv("FRONTEND_URL", "http://localhost:5173"),
 		GuestDocTTLDays:    guestTTL,
+		TrashRetentionDays: trashRetention,
 		SecureCookie:       secureCookie,
+		DocWorkers:         docWorkers,
+		DocWorkerUserLimit: docWorkerUserLimit,
 
 		// Observability
 		LogLevel:     getEnv("LOG_LEVEL", "info"),
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_document_jobs_running_user;
+DROP INDEX IF EXISTS idx_document_jobs_runnable;
+DROP TABLE IF EXISTS document_jobs;
@@ -0,0 +1,18 @@
+-- Background processing queue for document tokenization
+-- One row per document; the row is removed once processing succeeds
+CREATE TABLE document_jobs (
+    doc_id UUID PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+    last_error TEXT,
+    run_after TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Workers poll for runnable jobs and count running jobs per user
+CREATE INDEX idx_document_jobs_runnable ON document_jobs(run_after) WHERE status = 'pending';
+CREATE INDEX idx_document_jobs_running_user ON document_jobs(user_id) WHERE status = 'running';
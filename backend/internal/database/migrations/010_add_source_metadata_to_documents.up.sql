@@ -0,0 +1,3 @@
+-- Record where a document's text came from (pasted or imported file) and its author
+ALTER TABLE documents ADD COLUMN source_type TEXT NOT NULL DEFAULT 'paste';
+ALTER TABLE documents ADD COLUMN author TEXT;
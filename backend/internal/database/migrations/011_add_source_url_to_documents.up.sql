@@ -0,0 +1,2 @@
+-- Record the page a document was imported from
+ALTER TABLE documents ADD COLUMN source_url TEXT;
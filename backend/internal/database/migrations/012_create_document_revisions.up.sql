@@ -0,0 +1,11 @@
+-- Immutable snapshots of a document's previous content, taken before each content edit
+CREATE TABLE document_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    doc_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    token_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (doc_id, revision)
+);
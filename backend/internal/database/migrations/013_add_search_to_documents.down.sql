@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_documents_search_vector;
+ALTER TABLE documents DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE documents DROP COLUMN IF EXISTS search_config;
+ALTER TABLE documents DROP COLUMN IF EXISTS language;
@@ -0,0 +1,14 @@
+-- Full-text search over each document's title and content.
+-- language is the detected ISO 639-1 code; search_config is the matching
+-- Postgres text search configuration used to stem both the index and queries.
+ALTER TABLE documents ADD COLUMN language TEXT;
+ALTER TABLE documents ADD COLUMN search_config REGCONFIG NOT NULL DEFAULT 'simple';
+
+-- Titles rank above body text. Content is capped because a tsvector can't
+-- exceed 1MB, and positions past 16383 aren't recorded anyway.
+ALTER TABLE documents ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector(search_config, COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector(search_config, LEFT(COALESCE(content, ''), 262144)), 'B')
+) STORED;
+
+CREATE INDEX idx_documents_search_vector ON documents USING GIN (search_vector);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS collection_documents;
+DROP TABLE IF EXISTS collections;
+DROP TABLE IF EXISTS document_tags;
+DROP TABLE IF EXISTS tags;
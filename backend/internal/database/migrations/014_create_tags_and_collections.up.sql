@@ -0,0 +1,41 @@
+-- User-defined tags, applied to any number of documents
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    color TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_tags_user_name ON tags (user_id, LOWER(name));
+
+CREATE TABLE document_tags (
+    doc_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (doc_id, tag_id)
+);
+
+-- Filtering the library by tag looks documents up from the tag side
+CREATE INDEX idx_document_tags_tag_id ON document_tags (tag_id);
+
+-- Named collections grouping documents, e.g. a reading list or a course
+CREATE TABLE collections (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_collections_user_name ON collections (user_id, LOWER(name));
+
+CREATE TABLE collection_documents (
+    collection_id UUID NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    doc_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (collection_id, doc_id)
+);
+
+CREATE INDEX idx_collection_documents_doc_id ON collection_documents (doc_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_reading_state_user_updated;
+DROP INDEX IF EXISTS idx_documents_user_visibility;
+DROP INDEX IF EXISTS idx_documents_user_status;
+DROP INDEX IF EXISTS idx_documents_user_token_count;
+DROP INDEX IF EXISTS idx_documents_user_title;
+DROP INDEX IF EXISTS idx_documents_user_created;
@@ -0,0 +1,12 @@
+-- Keyset pagination over a user's library: each sort key is indexed together
+-- with the user and the id tie-breaker so a page is a single index range scan
+CREATE INDEX idx_documents_user_created ON documents (user_id, created_at, id);
+CREATE INDEX idx_documents_user_title ON documents (user_id, LOWER(title), id);
+CREATE INDEX idx_documents_user_token_count ON documents (user_id, token_count, id);
+
+-- Status and visibility filters
+CREATE INDEX idx_documents_user_status ON documents (user_id, status);
+CREATE INDEX idx_documents_user_visibility ON documents (user_id, visibility);
+
+-- Sorting by last read starts from the user's reading state
+CREATE INDEX idx_reading_state_user_updated ON reading_state (user_id, updated_at);
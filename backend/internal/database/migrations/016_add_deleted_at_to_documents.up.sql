@@ -0,0 +1,6 @@
+-- Soft deletion: trashed documents keep their content, chunks and progress
+-- until restored or purged
+ALTER TABLE documents ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Trash listings and the cleanup job's purge only look at trashed rows
+CREATE INDEX idx_documents_deleted_at ON documents (user_id, deleted_at) WHERE deleted_at IS NOT NULL;
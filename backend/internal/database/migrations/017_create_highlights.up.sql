@@ -0,0 +1,21 @@
+-- Bookmarks and highlights a reader saved in a document, anchored to an
+-- inclusive range of token indices. A bookmark marks a single token.
+CREATE TABLE highlights (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    doc_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL DEFAULT 'highlight' CHECK (kind IN ('bookmark', 'highlight')),
+    start_index INT NOT NULL CHECK (start_index >= 0),
+    end_index INT NOT NULL,
+    color TEXT,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (end_index >= start_index)
+);
+
+-- Readers list their highlights for one document in reading order
+CREATE INDEX idx_highlights_user_doc ON highlights (user_id, doc_id, start_index);
+
+-- Content edits remap every reader's highlights in a document
+CREATE INDEX idx_highlights_doc_id ON highlights (doc_id);
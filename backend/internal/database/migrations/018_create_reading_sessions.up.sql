@@ -0,0 +1,20 @@
+-- Stretches of continuous reading, recorded as reading state advances.
+-- words_read counts tokens advanced while reading, excluding seeks.
+CREATE TABLE reading_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    doc_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    start_index INT NOT NULL,
+    end_index INT NOT NULL,
+    words_read INT NOT NULL DEFAULT 0,
+    wpm INT NOT NULL,
+    client TEXT NOT NULL
+);
+
+-- Session history is listed by time across a user's library
+CREATE INDEX idx_reading_sessions_user_started ON reading_sessions (user_id, started_at DESC);
+
+-- Each progress save looks up the reader's latest session in the document
+CREATE INDEX idx_reading_sessions_user_doc_ended ON reading_sessions (user_id, doc_id, ended_at DESC);
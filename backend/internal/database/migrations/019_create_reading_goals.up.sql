@@ -0,0 +1,13 @@
+-- Reading goals: a target number of words or minutes per day or week,
+-- measured in the reader's timezone. One goal per metric and period.
+CREATE TABLE reading_goals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    metric TEXT NOT NULL CHECK (metric IN ('words', 'minutes')),
+    period TEXT NOT NULL CHECK (period IN ('daily', 'weekly')),
+    target INT NOT NULL CHECK (target > 0),
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, metric, period)
+);
@@ -0,0 +1,7 @@
+-- Normalized SHA-256 of a document's content, used to spot the same text
+-- being saved twice. NULL for documents created before content was stored.
+ALTER TABLE documents ADD COLUMN content_hash TEXT;
+
+-- Duplicate checks look up a user's live documents by hash. Not unique:
+-- existing libraries may already hold copies and duplicates can be allowed.
+CREATE INDEX idx_documents_content_hash ON documents (user_id, content_hash) WHERE deleted_at IS NULL;
@@ -0,0 +1,3 @@
+-- Word, sentence and paragraph counts, readability scores and the
+-- pause-weighted length of a document, computed when it is tokenized
+ALTER TABLE documents ADD COLUMN analytics JSONB;
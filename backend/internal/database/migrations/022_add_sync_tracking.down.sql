@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS document_tombstones;
+DROP INDEX IF EXISTS idx_documents_user_updated;
+ALTER TABLE documents DROP COLUMN IF EXISTS updated_at;
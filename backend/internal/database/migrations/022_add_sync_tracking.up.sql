@@ -0,0 +1,18 @@
+-- When a document's metadata or content last changed, so clients can sync
+-- only what changed since they last asked
+ALTER TABLE documents ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE;
+UPDATE documents SET updated_at = COALESCE(deleted_at, created_at);
+ALTER TABLE documents ALTER COLUMN updated_at SET NOT NULL;
+ALTER TABLE documents ALTER COLUMN updated_at SET DEFAULT NOW();
+
+CREATE INDEX idx_documents_user_updated ON documents (user_id, updated_at);
+
+-- Documents deleted for good, kept long enough for other devices to hear
+-- about it. Trashed documents still have their row and need no tombstone.
+CREATE TABLE document_tombstones (
+    doc_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    deleted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_document_tombstones_user_deleted ON document_tombstones (user_id, deleted_at);
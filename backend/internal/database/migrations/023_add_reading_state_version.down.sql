@@ -0,0 +1,2 @@
+ALTER TABLE reading_state DROP COLUMN IF EXISTS client_updated_at;
+ALTER TABLE reading_state DROP COLUMN IF EXISTS version;
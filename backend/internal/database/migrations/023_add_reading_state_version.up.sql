@@ -0,0 +1,8 @@
+-- Guard reading positions against stale writes from other devices. version
+-- counts writes to a position; client_updated_at is when the position was
+-- reached on the device that saved it, which can be well before it arrived.
+ALTER TABLE reading_state ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE reading_state ADD COLUMN client_updated_at TIMESTAMP WITH TIME ZONE;
+UPDATE reading_state SET client_updated_at = updated_at;
+ALTER TABLE reading_state ALTER COLUMN client_updated_at SET NOT NULL;
+ALTER TABLE reading_state ALTER COLUMN client_updated_at SET DEFAULT NOW();
@@ -0,0 +1,3 @@
+-- Counts edits to a document's title or content, served as its ETag so
+-- clients can make edits conditional on nobody else having edited first
+ALTER TABLE documents ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
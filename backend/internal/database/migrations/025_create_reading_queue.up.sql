@@ -0,0 +1,11 @@
+-- A user's "up next" list: documents to read, in the order they chose.
+-- Positions only order the queue and may have gaps or go negative.
+CREATE TABLE reading_queue (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    doc_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    queued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, doc_id)
+);
+
+CREATE INDEX idx_reading_queue_user_position ON reading_queue (user_id, position);
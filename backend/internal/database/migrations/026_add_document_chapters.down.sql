@@ -0,0 +1,2 @@
+ALTER TABLE documents DROP COLUMN IF EXISTS chapters;
+ALTER TABLE documents DROP COLUMN IF EXISTS headings;
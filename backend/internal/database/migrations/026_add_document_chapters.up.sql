@@ -0,0 +1,4 @@
+-- Section titles of an imported document with the byte offset of each in its
+-- content, and the chapters they become once the content is tokenized
+ALTER TABLE documents ADD COLUMN headings JSONB;
+ALTER TABLE documents ADD COLUMN chapters JSONB;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_reading_state_user_changed;
+ALTER TABLE reading_state DROP COLUMN IF EXISTS changed_at;
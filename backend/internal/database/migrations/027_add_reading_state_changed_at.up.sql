@@ -0,0 +1,8 @@
+-- When a reading position last changed for any reason, which delta sync
+-- follows. updated_at stays the time the reader last read, so positions the
+-- server moves after an edit don't count as reading.
+ALTER TABLE reading_state ADD COLUMN changed_at TIMESTAMP WITH TIME ZONE;
+UPDATE reading_state SET changed_at = updated_at;
+ALTER TABLE reading_state ALTER COLUMN changed_at SET NOT NULL;
+ALTER TABLE reading_state ALTER COLUMN changed_at SET DEFAULT NOW();
+CREATE INDEX idx_reading_state_user_changed ON reading_state (user_id, changed_at);
@@ -0,0 +1,202 @@
+package documents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+	"github.com/mikepersonal/speed-reader/backend/internal/storage"
+	"github.com/mikepersonal/speed-reader/backend/internal/tokenizer"
+)
+
+// EstimateWPM is the reading speed EstimatedSeconds assumes
+const EstimateWPM = 300
+
+// Analytics describes a document's text, computed when it is tokenized
+type Analytics struct {
+	WordCount      int     `json:"wordCount"`
+	SentenceCount  int     `json:"sentenceCount"`
+	ParagraphCount int     `json:"paragraphCount"`
+	AvgWordLength  float64 `json:"avgWordLength"` // letters per word
+
+	// Readability formulas are calibrated on English and left out for
+	// documents in other or undetected languages
+	FleschReadingEase  *float64 `json:"fleschReadingEase,omitempty"`
+	FleschKincaidGrade *float64 `json:"fleschKincaidGrade,omitempty"`
+
+	// PauseWeight is the token count with each token weighted by its pause
+	// multiplier, i.e. how many words long the text reads at a given speed
+	PauseWeight      float64 `json:"pauseWeight"`
+	EstimatedSeconds int     `json:"estimatedSeconds"` // at EstimateWPM
+}
+
+// Analyze computes the analytics of a tokenized text. lang is its detected
+// language, which decides whether readability scores apply.
+func Analyze(tokens []storage.Token, lang string) *Analytics {
+	a := &Analytics{}
+	if len(tokens) == 0 {
+		return a
+	}
+
+	letters, syllables := 0, 0
+	for _, t := range tokens {
+		a.PauseWeight += t.PauseMultiplier
+
+		word := tokenizer.StripPunctuation(t.Text)
+		n := countLetters(word)
+		if n == 0 {
+			continue // dashes and other bare punctuation
+		}
+		a.WordCount++
+		letters += n
+		syllables += countSyllables(word)
+	}
+
+	last := tokens[len(tokens)-1]
+	a.SentenceCount = last.SentenceIndex + 1
+	a.ParagraphCount = last.ParagraphIndex + 1
+	a.PauseWeight = round(a.PauseWeight, 1)
+	a.EstimatedSeconds = readingSeconds(a.PauseWeight, EstimateWPM)
+
+	if a.WordCount == 0 {
+		return a
+	}
+	a.AvgWordLength = round(float64(letters)/float64(a.WordCount), 2)
+
+	if lang == "en" {
+		wordsPerSentence := float64(a.WordCount) / float64(a.SentenceCount)
+		syllablesPerWord := float64(syllables) / float64(a.WordCount)
+		ease := round(206.835-1.015*wordsPerSentence-84.6*syllablesPerWord, 1)
+		grade := round(0.39*wordsPerSentence+11.8*syllablesPerWord-15.59, 1)
+		a.FleschReadingEase = &ease
+		a.FleschKincaidGrade = &grade
+	}
+
+	return a
+}
+
+// RemainingSeconds estimates how long the rest of a document takes to read
+// from tokenIndex at wpm, counting pauses when the document's analytics are
+// known and one word per token otherwise
+func RemainingSeconds(a *Analytics, tokenIndex, tokenCount, wpm int) int {
+	if tokenCount <= 0 || wpm <= 0 {
+		return 0
+	}
+
+	weight := float64(tokenCount)
+	if a != nil {
+		weight = a.PauseWeight
+	}
+	remaining := 1 - float64(min(max(tokenIndex, 0), tokenCount))/float64(tokenCount)
+	return readingSeconds(weight*remaining, wpm)
+}
+
+// readingSeconds converts a pause-weighted word count to seconds at wpm
+func readingSeconds(weight float64, wpm int) int {
+	return int(math.Round(weight * 60 / float64(wpm)))
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+// countLetters counts the letters and digits in a word
+func countLetters(word string) int {
+	n := 0
+	for _, r := range word {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// countSyllables estimates the syllables in an English word by counting vowel
+// groups, discounting a silent final "e". Every word has at least one.
+func countSyllables(word string) int {
+	word = strings.ToLower(word)
+	isVowel := func(r rune) bool { return strings.ContainsRune("aeiouy", r) }
+
+	count := 0
+	prevVowel := false
+	for _, r := range word {
+		v := isVowel(r)
+		if v && !prevVowel {
+			count++
+		}
+		prevVowel = v
+	}
+
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	return max(count, 1)
+}
+
+// SetAnalytics stores a document's analytics
+func (r *Repository) SetAnalytics(ctx context.Context, id uuid.UUID, a *Analytics) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to encode analytics: %w", err)
+	}
+
+	query := `UPDATE documents SET analytics = $2 WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id, data); err != nil {
+		return fmt.Errorf("failed to set analytics: %w", err)
+	}
+	return nil
+}
+
+// ReadingSpeed returns a user's position in a document and the speed they
+// read it at: their saved speed for it, else their default, else EstimateWPM
+func (r *Repository) ReadingSpeed(ctx context.Context, userID, docID uuid.UUID) (int, int, error) {
+	query := `
+		SELECT COALESCE(rs.token_index, 0), COALESCE(rs.wpm, (u.settings->>'defaultWpm')::int, $3)
+		FROM users u
+		LEFT JOIN reading_state rs ON rs.user_id = u.id AND rs.doc_id = $2
+		WHERE u.id = $1
+	`
+
+	var tokenIndex, wpm int
+	if err := r.db.QueryRowContext(ctx, query, userID, docID, EstimateWPM).Scan(&tokenIndex, &wpm); err != nil {
+		return 0, 0, fmt.Errorf("failed to get reading speed: %w", err)
+	}
+	return tokenIndex, wpm, nil
+}
+
+// scanAnalytics decodes the analytics column, which is NULL until a
+// document has been tokenized since analytics were introduced
+func scanAnalytics(data []byte) *Analytics {
+	if data == nil {
+		return nil
+	}
+	var a Analytics
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil
+	}
+	return &a
+}
+
+// FillRemainingTime sets how long the current user has left in a document
+// at their reading speed
+func (s *Service) FillRemainingTime(ctx context.Context, doc *Document) error {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("user not found in context")
+	}
+
+	tokenIndex, wpm, err := s.repo.ReadingSpeed(ctx, user.ID, doc.ID)
+	if err != nil {
+		return err
+	}
+
+	remaining := RemainingSeconds(doc.Analytics, tokenIndex, doc.TokenCount, wpm)
+	doc.RemainingSeconds = &remaining
+	return nil
+}
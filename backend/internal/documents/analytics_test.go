@@ -0,0 +1,96 @@
+package documents
+
+import (
+	"testing"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/tokenizer"
+)
+
+func TestAnalyze_Counts(t *testing.T) {
+	tokens := tokenizer.Tokenize("The cat sat on the mat. It was happy.\n\nThen it slept - soundly.")
+	a := Analyze(tokens, "en")
+
+	if a.WordCount != 13 {
+		t.Errorf("WordCount = %d, want 13 (dash is not a word)", a.WordCount)
+	}
+	if a.SentenceCount != 3 {
+		t.Errorf("SentenceCount = %d, want 3", a.SentenceCount)
+	}
+	if a.ParagraphCount != 2 {
+		t.Errorf("ParagraphCount = %d, want 2", a.ParagraphCount)
+	}
+	if a.AvgWordLength <= 2 || a.AvgWordLength >= 5 {
+		t.Errorf("AvgWordLength = %v, want a short-word average", a.AvgWordLength)
+	}
+	if a.PauseWeight <= float64(len(tokens)) {
+		t.Errorf("PauseWeight = %v, want more than the %d tokens for its pauses", a.PauseWeight, len(tokens))
+	}
+	if a.EstimatedSeconds != readingSeconds(a.PauseWeight, EstimateWPM) {
+		t.Errorf("EstimatedSeconds = %d, inconsistent with PauseWeight", a.EstimatedSeconds)
+	}
+}
+
+func TestAnalyze_ReadabilityOnlyForEnglish(t *testing.T) {
+	tokens := tokenizer.Tokenize("The cat sat on the mat. The dog ran to the park.")
+
+	en := Analyze(tokens, "en")
+	if en.FleschReadingEase == nil || en.FleschKincaidGrade == nil {
+		t.Fatal("expected readability scores for English")
+	}
+	// Short, one-syllable sentences read very easily
+	if *en.FleschReadingEase < 90 || *en.FleschKincaidGrade > 3 {
+		t.Errorf("scores = %v / %v, want easy text", *en.FleschReadingEase, *en.FleschKincaidGrade)
+	}
+
+	for _, lang := range []string{"de", ""} {
+		if a := Analyze(tokens, lang); a.FleschReadingEase != nil || a.FleschKincaidGrade != nil {
+			t.Errorf("expected no readability scores for %q", lang)
+		}
+	}
+}
+
+func TestAnalyze_Empty(t *testing.T) {
+	a := Analyze(nil, "en")
+	if a.WordCount != 0 || a.EstimatedSeconds != 0 || a.FleschReadingEase != nil {
+		t.Errorf("expected zero analytics, got %+v", a)
+	}
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := map[string]int{
+		"cat":       1,
+		"make":      1,
+		"table":     2,
+		"reading":   2,
+		"beautiful": 3,
+		"the":       1,
+		"rhythm":    1,
+	}
+
+	for word, want := range tests {
+		if got := countSyllables(word); got != want {
+			t.Errorf("countSyllables(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestRemainingSeconds(t *testing.T) {
+	a := &Analytics{PauseWeight: 1200}
+
+	if got := RemainingSeconds(a, 0, 1000, 300); got != 240 {
+		t.Errorf("unread = %d, want 240", got)
+	}
+	if got := RemainingSeconds(a, 500, 1000, 600); got != 60 {
+		t.Errorf("half read at double speed = %d, want 60", got)
+	}
+	if got := RemainingSeconds(a, 2000, 1000, 300); got != 0 {
+		t.Errorf("past the end = %d, want 0", got)
+	}
+	// Without analytics every token counts once
+	if got := RemainingSeconds(nil, 0, 600, 300); got != 120 {
+		t.Errorf("no analytics = %d, want 120", got)
+	}
+	if got := RemainingSeconds(a, 0, 0, 300); got != 0 {
+		t.Errorf("unprocessed = %d, want 0", got)
+	}
+}
@@ -0,0 +1,129 @@
+package documents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+)
+
+// MaxBatchCreate caps the documents created by one batch request
+const MaxBatchCreate = 100
+
+// BatchCreateResult is the outcome of one input to CreateDocuments: the new
+// document, or an error, which is a *DuplicateError when the user already
+// has the text
+type BatchCreateResult struct {
+	Document *Document
+	Err      error
+}
+
+// FindByContentHashes returns the IDs of the user's most recent documents
+// outside the trash with each of the given content hashes, keyed by hash.
+// Hashes with no document are absent.
+func (r *Repository) FindByContentHashes(ctx context.Context, userID uuid.UUID, hashes []string) (map[string]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT ON (content_hash) content_hash, id
+		FROM documents
+		WHERE user_id = $1 AND content_hash = ANY($2) AND deleted_at IS NULL
+		ORDER BY content_hash, created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(hashes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]uuid.UUID)
+	for rows.Next() {
+		var hash string
+		var id uuid.UUID
+		if err := rows.Scan(&hash, &id); err != nil {
+			return nil, fmt.Errorf("failed to scan duplicate: %w", err)
+		}
+		ids[hash] = id
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating duplicates: %w", err)
+	}
+
+	return ids, nil
+}
+
+// CreateDocuments creates several documents at once, as CreateDocument would
+// one at a time, returning a result per input in the same order. Duplicates
+// are looked up in one query and include repeats within the batch. Workers
+// are woken once all are queued. Only a failure affecting the whole batch
+// returns an error.
+func (s *Service) CreateDocuments(ctx context.Context, inputs []*CreateDocumentInput) ([]BatchCreateResult, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("user not found in context")
+	}
+
+	hashes := make([]string, len(inputs))
+	var lookup []string
+	for i, input := range inputs {
+		hashes[i] = ContentHash(input.Content)
+		if !input.AllowDuplicate && hashes[i] != "" {
+			lookup = append(lookup, hashes[i])
+		}
+	}
+
+	existingIDs := map[string]uuid.UUID{}
+	if len(lookup) > 0 {
+		var err error
+		existingIDs, err = s.repo.FindByContentHashes(ctx, user.ID, lookup)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Documents by hash, from the library or created earlier in this batch
+	known := make(map[string]*Document, len(inputs))
+	results := make([]BatchCreateResult, len(inputs))
+	created := 0
+
+	for i, input := range inputs {
+		hash := hashes[i]
+		if !input.AllowDuplicate && hash != "" {
+			existing := known[hash]
+			if existing == nil {
+				if id, ok := existingIDs[hash]; ok {
+					doc, err := s.repo.GetByID(ctx, id)
+					if err != nil {
+						results[i].Err = err
+						continue
+					}
+					existing = doc
+					known[hash] = doc
+				}
+			}
+			if existing != nil {
+				results[i].Err = &DuplicateError{Existing: existing}
+				continue
+			}
+		}
+
+		doc, err := s.createDocument(ctx, user, input)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Document = doc
+		created++
+		if hash != "" && known[hash] == nil {
+			known[hash] = doc
+		}
+	}
+
+	if created > 0 {
+		s.wakeWorkers()
+	}
+
+	return results, nil
+}
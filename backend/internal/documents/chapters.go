@@ -0,0 +1,95 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/tokenizer"
+)
+
+// Heading is a section title in an imported document's content and the byte
+// offset it starts at, kept so the chapter can be found once the content is
+// tokenized
+type Heading struct {
+	Title  string `json:"title"`
+	Offset int    `json:"offset"`
+}
+
+// Chapter is a titled section of a document, such as a book chapter, and the
+// token it starts at
+type Chapter struct {
+	Title      string `json:"title"`
+	TokenIndex int    `json:"tokenIndex"`
+}
+
+// locateChapters finds the token each heading starts at. Headings must be in
+// content order and start paragraphs, as extracted documents' do, so the text
+// between two of them tokenizes to the same words it does within the whole.
+func locateChapters(content string, headings []Heading) []Chapter {
+	chapters := make([]Chapter, 0, len(headings))
+	index, prev := 0, 0
+	for _, h := range headings {
+		if h.Offset < prev || h.Offset > len(content) {
+			continue
+		}
+		index += len(tokenizer.Tokenize(content[prev:h.Offset]))
+		prev = h.Offset
+		chapters = append(chapters, Chapter{Title: h.Title, TokenIndex: index})
+	}
+	return chapters
+}
+
+// remapChapters carries chapters across a content edit. Chapters whose first
+// word was deleted are dropped, and none survive when the old token stream
+// is unknown.
+func remapChapters(chapters []Chapter, remap *tokenRemap) []Chapter {
+	if remap.mapped == nil {
+		return nil
+	}
+
+	mapped := make([]Chapter, 0, len(chapters))
+	for _, c := range chapters {
+		if c.TokenIndex >= 0 && c.TokenIndex < len(remap.mapped) && remap.mapped[c.TokenIndex] < 0 {
+			continue
+		}
+		mapped = append(mapped, Chapter{Title: c.Title, TokenIndex: remap.Map(c.TokenIndex)})
+	}
+	return mapped
+}
+
+// GetHeadings retrieves the section titles recorded when a document was
+// imported. They're cleared when its content is edited.
+func (r *Repository) GetHeadings(ctx context.Context, id uuid.UUID) ([]Heading, error) {
+	var data []byte
+	err := r.db.QueryRowContext(ctx, `SELECT headings FROM documents WHERE id = $1`, id).Scan(&data)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("document not found")
+		}
+		return nil, fmt.Errorf("failed to get headings: %w", err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	var headings []Heading
+	if err := json.Unmarshal(data, &headings); err != nil {
+		return nil, fmt.Errorf("failed to decode headings: %w", err)
+	}
+	return headings, nil
+}
+
+// scanChapters decodes the chapters column, which is NULL for documents without any
+func scanChapters(data []byte) []Chapter {
+	if data == nil {
+		return nil
+	}
+	var chapters []Chapter
+	if err := json.Unmarshal(data, &chapters); err != nil {
+		return nil
+	}
+	return chapters
+}
@@ -0,0 +1,76 @@
+package documents
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/extract"
+	"github.com/mikepersonal/speed-reader/backend/internal/tokenizer"
+)
+
+func TestLocateChapters(t *testing.T) {
+	doc := &extract.Document{Sections: []extract.Section{
+		{Paragraphs: []string{"A short preface\nover two lines."}},
+		{Title: "Chapter One", Paragraphs: []string{"It was a dark night.", "", "Dr. Smith arrived — late."}},
+		{Title: "Empty"},
+		{Title: "Chapter Three", Paragraphs: []string{"The end."}},
+	}}
+	content := doc.Text()
+	var headings []Heading
+	for _, h := range doc.Headings() {
+		headings = append(headings, Heading{Title: h.Title, Offset: h.Offset})
+	}
+
+	chapters := locateChapters(content, headings)
+	if len(chapters) != 3 {
+		t.Fatalf("expected 3 chapters, got %+v", chapters)
+	}
+
+	tokens := tokenizer.Tokenize(content)
+	for _, c := range chapters {
+		if c.TokenIndex >= len(tokens) {
+			t.Fatalf("chapter %q starts past the end at %d", c.Title, c.TokenIndex)
+		}
+		if first := strings.Fields(c.Title)[0]; tokens[c.TokenIndex].Text != first {
+			t.Errorf("chapter %q starts at token %d %q, want %q", c.Title, c.TokenIndex, tokens[c.TokenIndex].Text, first)
+		}
+	}
+}
+
+func TestLocateChapters_SkipsOutOfOrderHeadings(t *testing.T) {
+	content := "Intro text.\n\nPart One\n\nBody."
+	chapters := locateChapters(content, []Heading{
+		{Title: "Part One", Offset: 13},
+		{Title: "Bogus", Offset: 2},
+		{Title: "Past the end", Offset: len(content) + 1},
+	})
+
+	if len(chapters) != 1 || chapters[0].Title != "Part One" || chapters[0].TokenIndex != 2 {
+		t.Errorf("expected only Part One at token 2, got %+v", chapters)
+	}
+}
+
+func TestRemapChapters(t *testing.T) {
+	old := strings.Fields("Part One alpha beta Part Two gamma Part Three delta")
+	new := strings.Fields("Preface Part One alpha beta gamma Part Three delta")
+	chapters := []Chapter{
+		{Title: "Part One", TokenIndex: 0},
+		{Title: "Part Two", TokenIndex: 4},
+		{Title: "Part Three", TokenIndex: 7},
+	}
+
+	got := remapChapters(chapters, newTokenRemap(old, new))
+	want := []Chapter{{Title: "Part One", TokenIndex: 1}, {Title: "Part Three", TokenIndex: 6}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("chapter %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	if got := remapChapters(chapters, newTokenRemap(nil, new)); got != nil {
+		t.Errorf("expected no chapters when the old stream is unknown, got %+v", got)
+	}
+}
@@ -0,0 +1,126 @@
+package documents
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrDuplicate indicates the user already has a document with the same content
+var ErrDuplicate = errors.New("duplicate document")
+
+// DuplicateError reports the existing document a new one would duplicate
+type DuplicateError struct {
+	Existing *Document
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("duplicate of document %s", e.Existing.ID)
+}
+
+// Is makes a DuplicateError match ErrDuplicate
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrDuplicate
+}
+
+// ContentHash returns the hash documents are compared by. Whitespace is
+// collapsed first so the same text pasted with different line breaks or
+// indentation still matches. Empty content has no hash.
+func ContentHash(content string) string {
+	normalized := strings.Join(strings.Fields(content), " ")
+	if normalized == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// FindByContentHash returns the user's most recent document outside the
+// trash with the given content hash, or nil if there is none
+func (r *Repository) FindByContentHash(ctx context.Context, userID uuid.UUID, hash string) (*Document, error) {
+	query := `
+		SELECT id
+		FROM documents
+		WHERE user_id = $1 AND content_hash = $2 AND deleted_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var id uuid.UUID
+	err := r.db.QueryRowContext(ctx, query, userID, hash).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// ContentToHash is a stored document whose content hash hasn't been set
+type ContentToHash struct {
+	ID      uuid.UUID
+	Content string
+}
+
+// ListUnhashed retrieves up to limit documents after the given ID with stored
+// content but no content hash, for backfilling documents created before hashes
+// were kept. Pass uuid.Nil to start from the beginning.
+func (r *Repository) ListUnhashed(ctx context.Context, after uuid.UUID, limit int) ([]ContentToHash, error) {
+	query := `
+		SELECT id, content
+		FROM documents
+		WHERE content_hash IS NULL AND content IS NOT NULL AND id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unhashed documents: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []ContentToHash{}
+	for rows.Next() {
+		var d ContentToHash
+		if err := rows.Scan(&d.ID, &d.Content); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		docs = append(docs, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating documents: %w", err)
+	}
+
+	return docs, nil
+}
+
+// SetContentHash stores a document's content hash. Content with nothing to
+// hash is marked with an empty hash so a backfill doesn't revisit it.
+func (r *Repository) SetContentHash(ctx context.Context, id uuid.UUID, hash string) error {
+	query := `UPDATE documents SET content_hash = $2 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, hash); err != nil {
+		return fmt.Errorf("failed to set content hash: %w", err)
+	}
+	return nil
+}
+
+// findDuplicate returns the user's existing document with the same content,
+// or nil if the content is new to them
+func (s *Service) findDuplicate(ctx context.Context, userID uuid.UUID, content string) (*Document, error) {
+	hash := ContentHash(content)
+	if hash == "" {
+		return nil, nil
+	}
+	return s.repo.FindByContentHash(ctx, userID, hash)
+}
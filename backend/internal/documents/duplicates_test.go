@@ -0,0 +1,44 @@
+package documents
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestContentHash_IgnoresWhitespace(t *testing.T) {
+	a := ContentHash("The quick brown fox.\n\nJumps over the dog.")
+	b := ContentHash("  The quick  brown fox.\r\n\tJumps over the dog.\n")
+	if a == "" || a != b {
+		t.Errorf("expected whitespace variants to hash alike, got %q and %q", a, b)
+	}
+}
+
+func TestContentHash_DistinguishesText(t *testing.T) {
+	if ContentHash("Hello world") == ContentHash("hello world") {
+		t.Error("expected different text to hash differently")
+	}
+}
+
+func TestContentHash_Empty(t *testing.T) {
+	for _, content := range []string{"", " \n\t "} {
+		if got := ContentHash(content); got != "" {
+			t.Errorf("ContentHash(%q) = %q, want empty", content, got)
+		}
+	}
+}
+
+func TestDuplicateError_MatchesSentinel(t *testing.T) {
+	err := fmt.Errorf("create: %w", &DuplicateError{Existing: &Document{ID: uuid.New()}})
+
+	if !errors.Is(err, ErrDuplicate) {
+		t.Error("expected DuplicateError to match ErrDuplicate")
+	}
+
+	var dupErr *DuplicateError
+	if !errors.As(err, &dupErr) || dupErr.Existing == nil {
+		t.Error("expected to recover the existing document")
+	}
+}
@@ -0,0 +1,157 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+	"github.com/mikepersonal/speed-reader/backend/internal/storage"
+)
+
+// ExportedDocument is a document owned by the user together with their
+// reading state in it, or nil if they never opened it
+type ExportedDocument struct {
+	Document
+	ReadingState *ReadingState `json:"readingState,omitempty"`
+}
+
+// ListForExport retrieves every document a user owns outside the trash with
+// their reading state, oldest first
+func (r *Repository) ListForExport(ctx context.Context, userID uuid.UUID) ([]ExportedDocument, error) {
+	query := `
+		SELECT d.id, d.user_id, d.title, d.status, d.token_count, d.chunk_count, d.visibility, d.share_token, d.expires_at, d.created_at, d.content IS NOT NULL,
+			   d.source_type, COALESCE(d.author, ''), COALESCE(d.source_url, ''), COALESCE(d.language, ''),
+			   rs.token_index, rs.wpm, rs.chunk_size, rs.updated_at
+		FROM documents d
+		LEFT JOIN reading_state rs ON rs.doc_id = d.id AND rs.user_id = d.user_id
+		WHERE d.user_id = $1 AND d.deleted_at IS NULL
+		ORDER BY d.created_at, d.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents for export: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []ExportedDocument{}
+	for rows.Next() {
+		var doc ExportedDocument
+		var ownerID, shareToken sql.NullString
+		var expiresAt, stateUpdatedAt sql.NullTime
+		var tokenIndex, wpm, chunkSize sql.NullInt64
+		err := rows.Scan(
+			&doc.ID, &ownerID, &doc.Title, &doc.Status, &doc.TokenCount, &doc.ChunkCount, &doc.Visibility, &shareToken, &expiresAt, &doc.CreatedAt, &doc.HasContent,
+			&doc.SourceType, &doc.Author, &doc.SourceURL, &doc.Language,
+			&tokenIndex, &wpm, &chunkSize, &stateUpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+
+		if ownerID.Valid {
+			uid, _ := uuid.Parse(ownerID.String)
+			doc.UserID = &uid
+		}
+		if shareToken.Valid {
+			st, _ := uuid.Parse(shareToken.String)
+			doc.ShareToken = &st
+		}
+		if expiresAt.Valid {
+			doc.ExpiresAt = &expiresAt.Time
+		}
+		if tokenIndex.Valid {
+			doc.ReadingState = &ReadingState{
+				UserID:     userID,
+				DocID:      doc.ID,
+				TokenIndex: int(tokenIndex.Int64),
+				WPM:        int(wpm.Int64),
+				ChunkSize:  int(chunkSize.Int64),
+				UpdatedAt:  stateUpdatedAt.Time,
+			}
+		}
+
+		docs = append(docs, doc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating documents: %w", err)
+	}
+
+	return docs, nil
+}
+
+// ExportDocuments returns every document the current user owns, for
+// building an account export
+func (s *Service) ExportDocuments(ctx context.Context) ([]ExportedDocument, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("user not found in context")
+	}
+
+	return s.repo.ListForExport(ctx, user.ID)
+}
+
+// DocumentText returns the text of a document: its original content, or for
+// documents created before content was stored, the text rebuilt from its tokens
+func (s *Service) DocumentText(ctx context.Context, doc *Document) (string, error) {
+	content, hasContent, err := s.repo.GetContent(ctx, doc.ID)
+	if err != nil {
+		return "", err
+	}
+	if hasContent {
+		return content, nil
+	}
+
+	var tokens []storage.Token
+	for i := 0; i < doc.ChunkCount; i++ {
+		chunk, err := s.chunkStore.ReadChunk(doc.ID, i)
+		if err != nil {
+			return "", fmt.Errorf("failed to read chunk %d: %w", i, err)
+		}
+		tokens = append(tokens, chunk.Tokens...)
+	}
+
+	return textFromTokens(tokens), nil
+}
+
+// RestoreReadingState sets the current user's position in a document they
+// own without recording it as reading, as when importing a library
+func (s *Service) RestoreReadingState(ctx context.Context, state *ReadingState) error {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("user not found in context")
+	}
+
+	owner, err := s.repo.IsOwner(ctx, state.DocID, user.ID)
+	if err != nil {
+		return err
+	}
+	if !owner {
+		return fmt.Errorf("document not found or not owned by user")
+	}
+
+	state.UserID = user.ID
+	return s.repo.UpsertReadingState(ctx, state, WriteCondition{})
+}
+
+// textFromTokens joins tokens back into text, words separated by spaces and
+// paragraphs by blank lines. Original whitespace and line breaks within a
+// paragraph are not recoverable.
+func textFromTokens(tokens []storage.Token) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		b.WriteString(t.Text)
+		if i == len(tokens)-1 {
+			break
+		}
+		if t.IsParagraphEnd {
+			b.WriteString("\n\n")
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
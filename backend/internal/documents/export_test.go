@@ -0,0 +1,27 @@
+package documents
+
+import (
+	"testing"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/storage"
+)
+
+func TestTextFromTokens(t *testing.T) {
+	tokens := []storage.Token{
+		{Text: "First"},
+		{Text: "paragraph.", IsSentenceEnd: true, IsParagraphEnd: true},
+		{Text: "Second"},
+		{Text: "one.", IsSentenceEnd: true, IsParagraphEnd: true},
+	}
+
+	want := "First paragraph.\n\nSecond one."
+	if got := textFromTokens(tokens); got != want {
+		t.Errorf("textFromTokens() = %q, want %q", got, want)
+	}
+}
+
+func TestTextFromTokens_Empty(t *testing.T) {
+	if got := textFromTokens(nil); got != "" {
+		t.Errorf("textFromTokens(nil) = %q, want empty", got)
+	}
+}
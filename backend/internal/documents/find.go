@@ -0,0 +1,179 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/storage"
+)
+
+const (
+	// DefaultFindLimit is how many matches are returned when no limit is given
+	DefaultFindLimit = 100
+
+	// MaxFindLimit caps the matches returned by a single find
+	MaxFindLimit = 500
+
+	// maxFindWords bounds the length of a phrase query
+	maxFindWords = 32
+
+	// findContextTokens is how many tokens of context surround each match
+	findContextTokens = 8
+)
+
+// ErrEmptyFindQuery indicates a find query with no searchable words
+var ErrEmptyFindQuery = errors.New("find query has no words")
+
+// FindMatch is one occurrence of the query in a document's tokens
+type FindMatch struct {
+	TokenIndex int    `json:"tokenIndex"`
+	TokenCount int    `json:"tokenCount"` // tokens spanned by the match
+	Before     string `json:"before"`
+	Match      string `json:"match"`
+	After      string `json:"after"`
+}
+
+// FindResult lists matches in token order. Total counts every match even
+// when only the first Limit are returned.
+type FindResult struct {
+	Matches   []FindMatch `json:"matches"`
+	Total     int         `json:"total"`
+	Truncated bool        `json:"truncated"`
+}
+
+// FindInDocument searches a document's tokens for a word or phrase, ignoring
+// case, diacritics and punctuation. Access follows GetTokens.
+func (s *Service) FindInDocument(ctx context.Context, docID uuid.UUID, query string, limit int) (*FindResult, error) {
+	doc, err := s.GetDocument(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	return s.FindInChunks(doc.ID, doc.ChunkCount, query, limit)
+}
+
+// FindInChunks searches a document's stored chunks without checking access.
+// It is for callers that have already authorized the read, such as share links.
+func (s *Service) FindInChunks(docID uuid.UUID, chunkCount int, query string, limit int) (*FindResult, error) {
+	phrase := parseFindQuery(query)
+	if len(phrase) == 0 {
+		return nil, ErrEmptyFindQuery
+	}
+
+	f := newTokenFinder(phrase, limit)
+	index := 0
+	for i := 0; i < chunkCount; i++ {
+		chunk, err := s.chunkStore.ReadChunk(docID, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chunk %d: %w", i, err)
+		}
+		for _, token := range chunk.Tokens {
+			f.feed(index, token)
+			index++
+		}
+	}
+
+	return f.result(), nil
+}
+
+// parseFindQuery folds a query into the words of the phrase to match.
+// Surrounding quotes are accepted but not required: several words are always
+// matched as a phrase.
+func parseFindQuery(query string) []string {
+	var phrase []string
+	for _, word := range strings.Fields(query) {
+		if folded := foldWord(word); folded != "" {
+			phrase = append(phrase, folded)
+		}
+		if len(phrase) == maxFindWords {
+			break
+		}
+	}
+	return phrase
+}
+
+// foundToken is a token seen by the finder with its comparison key
+type foundToken struct {
+	text   string
+	folded string
+}
+
+// tokenFinder matches a phrase against a token stream fed one token at a
+// time, so documents never need to be held in memory whole
+type tokenFinder struct {
+	phrase  []string
+	limit   int
+	recent  []foundToken // the last len(phrase)+findContextTokens tokens
+	pending []int        // matches still collecting trailing context
+	res     FindResult
+}
+
+func newTokenFinder(phrase []string, limit int) *tokenFinder {
+	return &tokenFinder{
+		phrase: phrase,
+		limit:  limit,
+		recent: make([]foundToken, 0, len(phrase)+findContextTokens),
+		res:    FindResult{Matches: []FindMatch{}},
+	}
+}
+
+func (f *tokenFinder) feed(index int, token storage.Token) {
+	t := foundToken{text: token.Text, folded: foldWord(token.Text)}
+
+	// Extend the trailing context of earlier matches
+	open := f.pending[:0]
+	for _, m := range f.pending {
+		match := &f.res.Matches[m]
+		if index-(match.TokenIndex+match.TokenCount) < findContextTokens {
+			match.After = joinTokenText(match.After, t.text)
+			open = append(open, m)
+		}
+	}
+	f.pending = open
+
+	if len(f.recent) == cap(f.recent) {
+		copy(f.recent, f.recent[1:])
+		f.recent = f.recent[:len(f.recent)-1]
+	}
+	f.recent = append(f.recent, t)
+
+	n := len(f.phrase)
+	if len(f.recent) < n {
+		return
+	}
+	window := f.recent[len(f.recent)-n:]
+	for i, word := range f.phrase {
+		if window[i].folded != word {
+			return
+		}
+	}
+
+	f.res.Total++
+	if len(f.res.Matches) == f.limit {
+		f.res.Truncated = true
+		return
+	}
+
+	match := FindMatch{TokenIndex: index - n + 1, TokenCount: n}
+	for _, w := range f.recent[:len(f.recent)-n] {
+		match.Before = joinTokenText(match.Before, w.text)
+	}
+	for _, w := range window {
+		match.Match = joinTokenText(match.Match, w.text)
+	}
+	f.res.Matches = append(f.res.Matches, match)
+	f.pending = append(f.pending, len(f.res.Matches)-1)
+}
+
+func (f *tokenFinder) result() *FindResult {
+	return &f.res
+}
+
+func joinTokenText(text, token string) string {
+	if text == "" {
+		return token
+	}
+	return text + " " + token
+}
@@ -0,0 +1,81 @@
+package documents
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/storage"
+)
+
+// findIn runs the finder over whitespace-separated tokens
+func findIn(text, query string, limit int) *FindResult {
+	f := newTokenFinder(parseFindQuery(query), limit)
+	for i, word := range strings.Fields(text) {
+		f.feed(i, storage.Token{Text: word})
+	}
+	return f.result()
+}
+
+func TestParseFindQuery(t *testing.T) {
+	got := parseFindQuery(`  "Café Society," — `)
+	if !reflect.DeepEqual(got, []string{"cafe", "society"}) {
+		t.Errorf("parseFindQuery = %v", got)
+	}
+	if got := parseFindQuery(" ... "); len(got) != 0 {
+		t.Errorf("expected no words, got %v", got)
+	}
+}
+
+func TestTokenFinder_Word(t *testing.T) {
+	res := findIn("The café opened. Later, the CAFE closed; cafés elsewhere stayed open.", "cafe", 10)
+
+	if res.Total != 2 || len(res.Matches) != 2 {
+		t.Fatalf("expected 2 matches, got %+v", res)
+	}
+	if res.Matches[0].TokenIndex != 1 || res.Matches[1].TokenIndex != 5 {
+		t.Errorf("unexpected indices: %+v", res.Matches)
+	}
+	if res.Matches[1].Before != "The café opened. Later, the" || res.Matches[1].Match != "CAFE" {
+		t.Errorf("unexpected context: %+v", res.Matches[1])
+	}
+	if res.Matches[1].After != "closed; cafés elsewhere stayed open." {
+		t.Errorf("unexpected trailing context: %q", res.Matches[1].After)
+	}
+}
+
+func TestTokenFinder_Phrase(t *testing.T) {
+	res := findIn("to be or not to be, that is the question", `"To be"`, 10)
+
+	if res.Total != 2 {
+		t.Fatalf("expected 2 matches, got %+v", res)
+	}
+	m := res.Matches[1]
+	if m.TokenIndex != 4 || m.TokenCount != 2 || m.Match != "to be," {
+		t.Errorf("unexpected phrase match: %+v", m)
+	}
+}
+
+func TestTokenFinder_ContextIsBounded(t *testing.T) {
+	words := make([]string, 40)
+	for i := range words {
+		words[i] = "w"
+	}
+	words[20] = "needle"
+
+	res := findIn(strings.Join(words, " "), "needle", 10)
+	m := res.Matches[0]
+	if got := len(strings.Fields(m.Before)); got != findContextTokens {
+		t.Errorf("expected %d tokens before, got %d", findContextTokens, got)
+	}
+	if got := len(strings.Fields(m.After)); got != findContextTokens {
+		t.Errorf("expected %d tokens after, got %d", findContextTokens, got)
+	}
+}
+
+func TestTokenFinder_Limit(t *testing.T) {
+	res := findIn("a b a b a b a", "a", 2)
+	if res.Total != 4 || len(res.Matches) != 2 || !res.Truncated {
+		t.Errorf("expected 2 of 4 matches and truncation, got %+v", res)
+	}
+}
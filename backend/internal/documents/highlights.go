@@ -0,0 +1,367 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+)
+
+const (
+	// MaxHighlightNoteLength bounds the note attached to a highlight, in characters
+	MaxHighlightNoteLength = 2000
+
+	// MaxHighlightsPerDocument caps how many highlights one reader keeps in a document
+	MaxHighlightsPerDocument = 1000
+)
+
+var (
+	// ErrHighlightNotFound indicates the highlight doesn't exist or belongs to
+	// another reader or document
+	ErrHighlightNotFound = errors.New("highlight not found")
+
+	// ErrHighlightLimit indicates the reader already has MaxHighlightsPerDocument
+	// highlights in the document
+	ErrHighlightLimit = errors.New("too many highlights in document")
+
+	// ErrInvalidHighlight wraps the reason a highlight request was rejected
+	ErrInvalidHighlight = errors.New("invalid highlight")
+)
+
+// highlightColorPattern accepts hex colors like #fde047
+var highlightColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// HighlightKind distinguishes saved positions from marked passages
+type HighlightKind string
+
+const (
+	KindBookmark  HighlightKind = "bookmark"
+	KindHighlight HighlightKind = "highlight"
+)
+
+// Highlight is a passage a reader marked, from StartIndex to EndIndex
+// inclusive. Bookmarks always cover a single token.
+type Highlight struct {
+	ID         uuid.UUID     `json:"id"`
+	DocID      uuid.UUID     `json:"docId"`
+	Kind       HighlightKind `json:"kind"`
+	StartIndex int           `json:"startIndex"`
+	EndIndex   int           `json:"endIndex"`
+	Color      string        `json:"color,omitempty"`
+	Note       string        `json:"note,omitempty"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+}
+
+// HighlightRequest creates or updates a highlight. On create, Kind defaults to
+// highlight and EndIndex to StartIndex. On update, omitted fields are left
+// unchanged and an empty color or note clears it.
+type HighlightRequest struct {
+	Kind       *HighlightKind `json:"kind,omitempty"`
+	StartIndex *int           `json:"startIndex,omitempty"`
+	EndIndex   *int           `json:"endIndex,omitempty"`
+	Color      *string        `json:"color,omitempty"`
+	Note       *string        `json:"note,omitempty"`
+}
+
+// ListHighlights returns a reader's highlights in a document in reading order
+func (r *Repository) ListHighlights(ctx context.Context, userID, docID uuid.UUID) ([]Highlight, error) {
+	query := `
+		SELECT id, doc_id, kind, start_index, end_index, COALESCE(color, ''), COALESCE(note, ''), created_at, updated_at
+		FROM highlights
+		WHERE user_id = $1 AND doc_id = $2
+		ORDER BY start_index, end_index, created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list highlights: %w", err)
+	}
+	defer rows.Close()
+
+	highlights := []Highlight{}
+	for rows.Next() {
+		var h Highlight
+		if err := rows.Scan(&h.ID, &h.DocID, &h.Kind, &h.StartIndex, &h.EndIndex, &h.Color, &h.Note, &h.CreatedAt, &h.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan highlight: %w", err)
+		}
+		highlights = append(highlights, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating highlights: %w", err)
+	}
+
+	return highlights, nil
+}
+
+// GetHighlight returns one of a reader's highlights in a document
+func (r *Repository) GetHighlight(ctx context.Context, userID, docID, highlightID uuid.UUID) (*Highlight, error) {
+	query := `
+		SELECT id, doc_id, kind, start_index, end_index, COALESCE(color, ''), COALESCE(note, ''), created_at, updated_at
+		FROM highlights
+		WHERE id = $1 AND user_id = $2 AND doc_id = $3
+	`
+
+	h := &Highlight{}
+	err := r.db.QueryRowContext(ctx, query, highlightID, userID, docID).Scan(
+		&h.ID, &h.DocID, &h.Kind, &h.StartIndex, &h.EndIndex, &h.Color, &h.Note, &h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrHighlightNotFound
+		}
+		return nil, fmt.Errorf("failed to get highlight: %w", err)
+	}
+
+	return h, nil
+}
+
+// CreateHighlight inserts a highlight unless the reader already has limit
+// highlights in the document
+func (r *Repository) CreateHighlight(ctx context.Context, userID uuid.UUID, h *Highlight, limit int) error {
+	query := `
+		INSERT INTO highlights (user_id, doc_id, kind, start_index, end_index, color, note, created_at, updated_at)
+		SELECT $1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NOW(), NOW()
+		WHERE (SELECT COUNT(*) FROM highlights WHERE user_id = $1 AND doc_id = $2) < $8
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, userID, h.DocID, h.Kind, h.StartIndex, h.EndIndex, h.Color, h.Note, limit).
+		Scan(&h.ID, &h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrHighlightLimit
+		}
+		return fmt.Errorf("failed to create highlight: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateHighlight saves a highlight's range, color and note
+func (r *Repository) UpdateHighlight(ctx context.Context, userID uuid.UUID, h *Highlight) error {
+	query := `
+		UPDATE highlights
+		SET kind = $4, start_index = $5, end_index = $6, color = NULLIF($7, ''), note = NULLIF($8, ''), updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND doc_id = $3
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, h.ID, userID, h.DocID, h.Kind, h.StartIndex, h.EndIndex, h.Color, h.Note).Scan(&h.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrHighlightNotFound
+		}
+		return fmt.Errorf("failed to update highlight: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteHighlight removes one of a reader's highlights
+func (r *Repository) DeleteHighlight(ctx context.Context, userID, docID, highlightID uuid.UUID) error {
+	query := `DELETE FROM highlights WHERE id = $1 AND user_id = $2 AND doc_id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, highlightID, userID, docID)
+	if err != nil {
+		return fmt.Errorf("failed to delete highlight: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrHighlightNotFound
+	}
+
+	return nil
+}
+
+// RemapHighlights moves every reader's highlights in a document through
+// remap, leaving updated_at alone since nobody edited them
+func (r *Repository) RemapHighlights(ctx context.Context, docID uuid.UUID, remap func(start, end int) (int, int)) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, start_index, end_index FROM highlights WHERE doc_id = $1 FOR UPDATE`, docID)
+	if err != nil {
+		return fmt.Errorf("failed to list highlights: %w", err)
+	}
+
+	type span struct {
+		id         uuid.UUID
+		start, end int
+	}
+	var spans []span
+	for rows.Next() {
+		var s span
+		if err := rows.Scan(&s.id, &s.start, &s.end); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan highlight: %w", err)
+		}
+		spans = append(spans, s)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("error iterating highlights: %w", err)
+	}
+	rows.Close()
+
+	update := `UPDATE highlights SET start_index = $2, end_index = $3 WHERE id = $1`
+	for _, s := range spans {
+		start, end := remap(s.start, s.end)
+		if start == s.start && end == s.end {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, update, s.id, start, end); err != nil {
+			return fmt.Errorf("failed to remap highlight: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit highlights: %w", err)
+	}
+	return nil
+}
+
+// ListHighlights returns the current user's highlights in a document they can read
+func (s *Service) ListHighlights(ctx context.Context, docID uuid.UUID) ([]Highlight, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("user not found in context")
+	}
+
+	if _, err := s.GetDocument(ctx, docID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListHighlights(ctx, user.ID, docID)
+}
+
+// CreateHighlight saves a bookmark or highlight in a document the user can read
+func (s *Service) CreateHighlight(ctx context.Context, docID uuid.UUID, req *HighlightRequest) (*Highlight, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("user not found in context")
+	}
+
+	doc, err := s.GetDocument(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.StartIndex == nil {
+		return nil, fmt.Errorf("%w: startIndex is required", ErrInvalidHighlight)
+	}
+	h := &Highlight{DocID: docID, Kind: KindHighlight}
+	if req.EndIndex == nil {
+		h.EndIndex = *req.StartIndex
+	}
+	if err := applyHighlightRequest(h, req, doc.TokenCount); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateHighlight(ctx, user.ID, h, MaxHighlightsPerDocument); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// UpdateHighlight changes the range, color or note of one of the user's highlights
+func (s *Service) UpdateHighlight(ctx context.Context, docID, highlightID uuid.UUID, req *HighlightRequest) (*Highlight, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("user not found in context")
+	}
+
+	doc, err := s.GetDocument(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := s.repo.GetHighlight(ctx, user.ID, docID, highlightID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyHighlightRequest(h, req, doc.TokenCount); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateHighlight(ctx, user.ID, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// DeleteHighlight removes one of the user's highlights
+func (s *Service) DeleteHighlight(ctx context.Context, docID, highlightID uuid.UUID) error {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("user not found in context")
+	}
+
+	if _, err := s.GetDocument(ctx, docID); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteHighlight(ctx, user.ID, docID, highlightID)
+}
+
+// applyHighlightRequest validates a request and copies the provided fields
+// onto h. Ranges are checked against the document's token count once it has
+// been processed.
+func applyHighlightRequest(h *Highlight, req *HighlightRequest, tokenCount int) error {
+	if req.Kind != nil {
+		if *req.Kind != KindBookmark && *req.Kind != KindHighlight {
+			return fmt.Errorf("%w: kind must be bookmark or highlight", ErrInvalidHighlight)
+		}
+		h.Kind = *req.Kind
+	}
+	if req.StartIndex != nil {
+		h.StartIndex = *req.StartIndex
+	}
+	if req.EndIndex != nil {
+		h.EndIndex = *req.EndIndex
+	}
+	if h.Kind == KindBookmark {
+		h.EndIndex = h.StartIndex
+	}
+
+	if h.StartIndex < 0 {
+		return fmt.Errorf("%w: startIndex must not be negative", ErrInvalidHighlight)
+	}
+	if h.EndIndex < h.StartIndex {
+		return fmt.Errorf("%w: endIndex must not precede startIndex", ErrInvalidHighlight)
+	}
+	if tokenCount > 0 && h.EndIndex >= tokenCount {
+		return fmt.Errorf("%w: range is past the end of the document", ErrInvalidHighlight)
+	}
+
+	if req.Color != nil {
+		color := strings.TrimSpace(*req.Color)
+		if color != "" && !highlightColorPattern.MatchString(color) {
+			return fmt.Errorf("%w: color must be a hex color like #fde047", ErrInvalidHighlight)
+		}
+		h.Color = strings.ToLower(color)
+	}
+	if req.Note != nil {
+		note := strings.TrimSpace(*req.Note)
+		if utf8.RuneCountInString(note) > MaxHighlightNoteLength {
+			return fmt.Errorf("%w: note is too long", ErrInvalidHighlight)
+		}
+		h.Note = note
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package documents
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func intPtr(n int) *int { return &n }
+
+func stringPtr(s string) *string { return &s }
+
+func TestApplyHighlightRequest(t *testing.T) {
+	bookmark := KindBookmark
+	h := &Highlight{Kind: KindHighlight}
+	req := &HighlightRequest{
+		StartIndex: intPtr(4),
+		EndIndex:   intPtr(9),
+		Color:      stringPtr(" #FDE047 "),
+		Note:       stringPtr("  worth rereading "),
+	}
+
+	if err := applyHighlightRequest(h, req, 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.StartIndex != 4 || h.EndIndex != 9 || h.Color != "#fde047" || h.Note != "worth rereading" {
+		t.Errorf("unexpected highlight: %+v", h)
+	}
+
+	// Bookmarks collapse to their start token
+	if err := applyHighlightRequest(h, &HighlightRequest{Kind: &bookmark}, 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.Kind != KindBookmark || h.EndIndex != 4 {
+		t.Errorf("expected a bookmark at 4, got %+v", h)
+	}
+
+	// Empty strings clear the color and note
+	if err := applyHighlightRequest(h, &HighlightRequest{Color: stringPtr(""), Note: stringPtr("")}, 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.Color != "" || h.Note != "" {
+		t.Errorf("expected color and note cleared, got %+v", h)
+	}
+}
+
+func TestApplyHighlightRequest_Invalid(t *testing.T) {
+	kind := HighlightKind("underline")
+	tests := []struct {
+		name string
+		req  HighlightRequest
+	}{
+		{"unknown kind", HighlightRequest{Kind: &kind}},
+		{"negative start", HighlightRequest{StartIndex: intPtr(-1)}},
+		{"end before start", HighlightRequest{StartIndex: intPtr(5), EndIndex: intPtr(4)}},
+		{"past end of document", HighlightRequest{StartIndex: intPtr(5), EndIndex: intPtr(10)}},
+		{"bad color", HighlightRequest{Color: stringPtr("yellow")}},
+		{"long note", HighlightRequest{Note: stringPtr(strings.Repeat("a", MaxHighlightNoteLength+1))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Highlight{Kind: KindHighlight}
+			err := applyHighlightRequest(h, &tt.req, 10)
+			if !errors.Is(err, ErrInvalidHighlight) {
+				t.Errorf("expected ErrInvalidHighlight, got %v", err)
+			}
+		})
+	}
+}
+
+func TestApplyHighlightRequest_UnprocessedDocument(t *testing.T) {
+	// Until the document is tokenized its length is unknown, so any range is accepted
+	h := &Highlight{Kind: KindHighlight}
+	if err := applyHighlightRequest(h, &HighlightRequest{StartIndex: intPtr(50), EndIndex: intPtr(60)}, 0); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobStatus represents the state of a background processing job
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending"
+	JobRunning JobStatus = "running"
+	JobFailed  JobStatus = "failed"
+)
+
+const (
+	// jobClaimLockKey serializes job claims across API instances so the
+	// per-user concurrency cap can't be exceeded by concurrent claimers
+	jobClaimLockKey = 7215398401
+
+	// retryBaseDelay is the delay before the first retry of a failed job
+	retryBaseDelay = 5 * time.Second
+
+	// retryMaxDelay caps the exponential backoff between retries
+	retryMaxDelay = 5 * time.Minute
+)
+
+// Job represents a queued document processing job
+type Job struct {
+	DocID       uuid.UUID `json:"docId"`
+	UserID      uuid.UUID `json:"userId"`
+	Status      JobStatus `json:"status"`
+	Attempts    int       `json:"attempts"`
+	MaxAttempts int       `json:"maxAttempts"`
+	LastError   string    `json:"lastError,omitempty"`
+	RunAfter    time.Time `json:"runAfter"`
+}
+
+// retryDelay returns the exponential backoff delay after the given attempt
+func retryDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := retryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+
+	return delay
+}
+
+// enqueueJobQuery schedules a document for processing, resetting any previous job
+const enqueueJobQuery = `
+	INSERT INTO document_jobs (doc_id, user_id, status, attempts, max_attempts, run_after, created_at, updated_at)
+	VALUES ($1, $2, 'pending', 0, $3, NOW(), NOW(), NOW())
+	ON CONFLICT (doc_id) DO UPDATE SET
+		status = 'pending',
+		attempts = 0,
+		max_attempts = EXCLUDED.max_attempts,
+		last_error = NULL,
+		run_after = NOW(),
+		locked_at = NULL,
+		updated_at = NOW()
+`
+
+// EnqueueJob schedules a document for background processing, resetting any previous job
+func (r *Repository) EnqueueJob(ctx context.Context, docID, userID uuid.UUID, maxAttempts int) error {
+	if _, err := r.db.ExecContext(ctx, enqueueJobQuery, docID, userID, maxAttempts); err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimJob marks the next runnable job as running and returns it.
+// Jobs are skipped while their user already has perUserLimit jobs running.
+// Running jobs whose lease has expired (e.g. after a crash) are reclaimed.
+// Returns nil when no job is runnable.
+func (r *Repository) ClaimJob(ctx context.Context, perUserLimit int, lease time.Duration) (*Job, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, jobClaimLockKey); err != nil {
+		return nil, fmt.Errorf("failed to acquire claim lock: %w", err)
+	}
+
+	query := `
+		UPDATE document_jobs j
+		SET status = 'running', attempts = j.attempts + 1, locked_at = NOW(), updated_at = NOW()
+		WHERE j.doc_id = (
+			SELECT c.doc_id FROM document_jobs c
+			WHERE ((c.status = 'pending' AND c.run_after <= NOW())
+				OR (c.status = 'running' AND c.locked_at < NOW() - make_interval(secs => $2)))
+			  AND (
+				SELECT COUNT(*) FROM document_jobs rj
+				WHERE rj.user_id = c.user_id AND rj.status = 'running'
+				  AND rj.locked_at >= NOW() - make_interval(secs => $2)
+			  ) < $1
+			ORDER BY c.run_after
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING j.doc_id, j.user_id, j.status, j.attempts, j.max_attempts, COALESCE(j.last_error, ''), j.run_after
+	`
+
+	job := &Job{}
+	err = tx.QueryRowContext(ctx, query, perUserLimit, lease.Seconds()).Scan(
+		&job.DocID, &job.UserID, &job.Status, &job.Attempts, &job.MaxAttempts, &job.LastError, &job.RunAfter)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit job claim: %w", err)
+	}
+
+	return job, nil
+}
+
+// CompleteJob removes a processed job. A job enqueued again while it ran is
+// pending rather than running, and is kept so it runs again.
+func (r *Repository) CompleteJob(ctx context.Context, docID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM document_jobs WHERE doc_id = $1 AND status = 'running'`, docID); err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+	return nil
+}
+
+// RetryJob returns a job to the queue to run again after the given delay
+func (r *Repository) RetryJob(ctx context.Context, docID uuid.UUID, delay time.Duration, lastError string) error {
+	query := `
+		UPDATE document_jobs
+		SET status = 'pending', last_error = $2, run_after = NOW() + make_interval(secs => $3), locked_at = NULL, updated_at = NOW()
+		WHERE doc_id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, docID, lastError, delay.Seconds()); err != nil {
+		return fmt.Errorf("failed to reschedule job: %w", err)
+	}
+	return nil
+}
+
+// FailJob marks a job as permanently failed
+func (r *Repository) FailJob(ctx context.Context, docID uuid.UUID, lastError string) error {
+	query := `
+		UPDATE document_jobs
+		SET status = 'failed', last_error = $2, locked_at = NULL, updated_at = NOW()
+		WHERE doc_id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, docID, lastError); err != nil {
+		return fmt.Errorf("failed to mark job failed: %w", err)
+	}
+	return nil
+}
+
+// GetJob retrieves the processing job for a document, returns nil if none exists
+func (r *Repository) GetJob(ctx context.Context, docID uuid.UUID) (*Job, error) {
+	query := `
+		SELECT doc_id, user_id, status, attempts, max_attempts, COALESCE(last_error, ''), run_after
+		FROM document_jobs
+		WHERE doc_id = $1
+	`
+
+	job := &Job{}
+	err := r.db.QueryRowContext(ctx, query, docID).Scan(
+		&job.DocID, &job.UserID, &job.Status, &job.Attempts, &job.MaxAttempts, &job.LastError, &job.RunAfter)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	return job, nil
+}
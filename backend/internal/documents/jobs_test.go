@@ -0,0 +1,187 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+	"github.com/mikepersonal/speed-reader/backend/internal/storage"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: 5 * time.Second},
+		{attempt: 1, want: 5 * time.Second},
+		{attempt: 2, want: 10 * time.Second},
+		{attempt: 3, want: 20 * time.Second},
+		{attempt: 6, want: 160 * time.Second},
+		{attempt: 7, want: 5 * time.Minute},
+		{attempt: 50, want: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempt); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateDocumentContent_QueuesProcessing(t *testing.T) {
+	svc, db := testService(t)
+	ctx := testUser(t, db)
+	id := testDocument(t, svc, ctx, "one two three")
+
+	doc, err := svc.UpdateDocumentContent(ctx, id, "", "one two three four five", nil)
+	if err != nil {
+		t.Fatalf("failed to edit: %v", err)
+	}
+	if doc.Status != StatusPending {
+		t.Errorf("expected the edited document to be pending, got %s", doc.Status)
+	}
+	job, err := svc.repo.GetJob(ctx, id)
+	if err != nil || job == nil || job.Status != JobPending {
+		t.Fatalf("expected a pending job for the edit, got %+v (%v)", job, err)
+	}
+
+	// Further edits wait until the first is processed
+	if _, err := svc.UpdateDocumentContent(ctx, id, "", "six seven", nil); !errors.Is(err, ErrDocumentProcessing) {
+		t.Errorf("expected ErrDocumentProcessing for a content edit, got %v", err)
+	}
+	if err := svc.UpdateDocumentTitle(ctx, id, "Renamed", nil); !errors.Is(err, ErrDocumentProcessing) {
+		t.Errorf("expected ErrDocumentProcessing for a rename, got %v", err)
+	}
+
+	testProcess(t, svc, id)
+
+	doc, err = svc.GetDocument(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get document: %v", err)
+	}
+	if doc.Status != StatusReady || doc.TokenCount != 5 {
+		t.Errorf("expected a ready document with 5 tokens, got %s with %d", doc.Status, doc.TokenCount)
+	}
+}
+
+func TestProcessDocument_DiscardsStaleWork(t *testing.T) {
+	svc, db := testService(t)
+	ctx := testUser(t, db)
+	id := testDocument(t, svc, ctx, "one two three")
+
+	if _, err := svc.UpdateDocumentContent(ctx, id, "", "one two three four", nil); err != nil {
+		t.Fatalf("failed to edit: %v", err)
+	}
+
+	// Work done for the version before the edit must not mark it ready
+	doc, err := svc.repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get document: %v", err)
+	}
+	if err := svc.repo.FinishProcessing(ctx, id, doc.Version-1, 4, 1, nil); !errors.Is(err, ErrContentChanged) {
+		t.Errorf("expected ErrContentChanged for a stale version, got %v", err)
+	}
+
+	doc, err = svc.repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get document: %v", err)
+	}
+	if doc.Status != StatusPending {
+		t.Errorf("expected stale work to leave the document pending, got %s", doc.Status)
+	}
+}
+
+func TestProcessDocument_RetriedEditRemapsPositions(t *testing.T) {
+	svc, db := testService(t)
+	ctx := testUser(t, db)
+	id := testDocument(t, svc, ctx, "one two three")
+
+	state := &ReadingState{DocID: id, TokenIndex: 2, WPM: 300, ChunkSize: 1}
+	if err := svc.UpdateReadingState(ctx, state, ClientWeb, WriteCondition{}); err != nil {
+		t.Fatalf("failed to save position: %v", err)
+	}
+	if _, err := svc.UpdateDocumentContent(ctx, id, "", "zero one two three", nil); err != nil {
+		t.Fatalf("failed to edit: %v", err)
+	}
+
+	// The first attempt fails partway, as when chunks can't be written
+	store := svc.chunkStore
+	blocked := filepath.Join(t.TempDir(), "blocked")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	svc.chunkStore = storage.NewChunkStore(blocked)
+	if _, err := svc.ProcessDocument(context.Background(), id); err == nil {
+		t.Fatal("expected processing to fail")
+	}
+	svc.chunkStore = store
+
+	// Readers are still served the text from before the edit
+	doc, err := svc.GetDocument(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get document: %v", err)
+	}
+	if doc.TokenCount != 3 {
+		t.Errorf("expected the failed attempt to keep 3 tokens, got %d", doc.TokenCount)
+	}
+	chunk, err := svc.GetTokens(ctx, id, 0)
+	if err != nil || len(chunk.Tokens) != 3 {
+		t.Fatalf("expected the previous chunk to stay readable, got %v", err)
+	}
+
+	// The retry still knows it is an edit and carries the position across
+	if err := svc.repo.SetStatus(ctx, id, StatusPending); err != nil {
+		t.Fatalf("failed to requeue: %v", err)
+	}
+	testProcess(t, svc, id)
+
+	got, err := svc.GetReadingState(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get position: %v", err)
+	}
+	if got.TokenIndex != 3 {
+		t.Errorf("expected the position to follow %q to token 3, got %d", "three", got.TokenIndex)
+	}
+}
+
+func TestFailProcessing_KeepsEditedDocument(t *testing.T) {
+	svc, db := testService(t)
+	ctx := testUser(t, db)
+	id := testDocument(t, svc, ctx, "one two three")
+
+	if _, err := svc.UpdateDocumentContent(ctx, id, "", "one two three four", nil); err != nil {
+		t.Fatalf("failed to edit: %v", err)
+	}
+	if err := svc.repo.FailProcessing(ctx, id); err != nil {
+		t.Fatalf("failed to fail processing: %v", err)
+	}
+
+	doc, err := svc.GetDocument(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get document: %v", err)
+	}
+	if doc.Status != StatusReady || doc.TokenCount != 3 {
+		t.Errorf("expected a failed edit to leave the ready document with 3 tokens, got %s with %d", doc.Status, doc.TokenCount)
+	}
+	if _, err := svc.GetTokens(ctx, id, 0); err != nil {
+		t.Errorf("expected the previous chunk to stay readable, got %v", err)
+	}
+
+	// A document that never processed has nothing to go back to
+	user, _ := auth.UserFromContext(ctx)
+	fresh, err := svc.repo.Create(ctx, &CreateParams{Title: "Notes", Content: "text", UserID: user.ID})
+	if err != nil {
+		t.Fatalf("failed to create document: %v", err)
+	}
+	if err := svc.repo.FailProcessing(ctx, fresh.ID); err != nil {
+		t.Fatalf("failed to fail processing: %v", err)
+	}
+	if doc, err := svc.repo.GetByID(ctx, fresh.ID); err != nil || doc.Status != StatusError {
+		t.Errorf("expected a document never processed to be marked as an error, got %+v (%v)", doc, err)
+	}
+}
@@ -0,0 +1,277 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// MaxListLimit caps the page size of a document list
+const MaxListLimit = 200
+
+// FinishedThreshold is the share of a document that must be read for it to
+// count as finished. It matches the client's "Finished" badge, which allows
+// for the last few words never being shown on their own.
+const FinishedThreshold = 0.99
+
+// ErrInvalidCursor indicates a list cursor that is malformed or was issued
+// for a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListSort selects the order of a document list
+type ListSort string
+
+const (
+	SortCreated  ListSort = "created"
+	SortTitle    ListSort = "title"
+	SortLastRead ListSort = "last_read"
+	SortProgress ListSort = "progress"
+	SortLength   ListSort = "length"
+)
+
+// ReadingFilter selects documents by how far they've been read
+type ReadingFilter string
+
+const (
+	ReadingUnread     ReadingFilter = "unread"
+	ReadingInProgress ReadingFilter = "in_progress"
+	ReadingFinished   ReadingFilter = "finished"
+)
+
+// progressExpr is the fraction of a document the user has read
+const progressExpr = `CASE WHEN d.token_count > 0 THEN COALESCE(rs.token_index, 0)::float8 / d.token_count ELSE 0 END`
+
+// listSort describes how to order and page by one sort key
+type listSort struct {
+	expr      string // SQL expression ordered on
+	cast      string // type the cursor's key is cast back to
+	ascending bool   // default direction
+}
+
+var listSorts = map[ListSort]listSort{
+	SortCreated:  {expr: "d.created_at", cast: "timestamptz"},
+	SortTitle:    {expr: "LOWER(d.title)", cast: "text", ascending: true},
+	SortLastRead: {expr: "COALESCE(rs.updated_at, d.created_at)", cast: "timestamptz"},
+	SortProgress: {expr: progressExpr, cast: "float8"},
+	SortLength:   {expr: "d.token_count", cast: "int"},
+}
+
+var readingFilters = map[ReadingFilter]string{
+	ReadingUnread:     "COALESCE(rs.token_index, 0) = 0",
+	ReadingInProgress: fmt.Sprintf("COALESCE(rs.token_index, 0) > 0 AND %s < %v", progressExpr, FinishedThreshold),
+	ReadingFinished:   fmt.Sprintf("d.token_count > 0 AND %s >= %v", progressExpr, FinishedThreshold),
+}
+
+// ValidSort reports whether s is a supported sort key
+func ValidSort(s ListSort) bool {
+	_, ok := listSorts[s]
+	return ok
+}
+
+// ValidReadingFilter reports whether f is a supported reading filter
+func ValidReadingFilter(f ReadingFilter) bool {
+	_, ok := readingFilters[f]
+	return ok
+}
+
+// ListOptions narrows, orders and pages the documents returned by List.
+// Zero values mean no filter; the default order is most recently read first.
+type ListOptions struct {
+	TagIDs        []uuid.UUID // only documents carrying every one of these tags
+	CollectionID  *uuid.UUID  // only documents in this collection
+	Status        DocumentStatus
+	Reading       ReadingFilter
+	Visibility    Visibility
+	HasContent    *bool
+	ModifiedSince *time.Time // only documents changed after this time
+
+	Sort      ListSort
+	Ascending *bool  // defaults to the natural direction of Sort
+	Limit     int    // 0 returns every matching document
+	Cursor    string // NextCursor from the previous page
+}
+
+// listCursor is the position after the last document of a page. Key is the
+// sort expression rendered as text by Postgres, so it casts back exactly.
+type listCursor struct {
+	Sort      ListSort  `json:"s"`
+	Ascending bool      `json:"a"`
+	Key       string    `json:"k"`
+	ID        uuid.UUID `json:"i"`
+}
+
+func encodeCursor(c listCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (listCursor, error) {
+	var c listCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// List retrieves a user's documents with their reading progress and tags. When
+// a limit is set and more documents follow, the returned cursor fetches them.
+func (r *Repository) List(ctx context.Context, userID uuid.UUID, opts ListOptions) ([]DocumentWithProgress, string, error) {
+	sortKey := opts.Sort
+	if sortKey == "" {
+		sortKey = SortLastRead
+	}
+	sort, ok := listSorts[sortKey]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown sort %q", sortKey)
+	}
+	ascending := sort.ascending
+	if opts.Ascending != nil {
+		ascending = *opts.Ascending
+	}
+
+	args := []interface{}{userID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where := []string{"d.user_id = $1", "d.deleted_at IS NULL"}
+	for _, tagID := range opts.TagIDs {
+		where = append(where, "EXISTS (SELECT 1 FROM document_tags dt WHERE dt.doc_id = d.id AND dt.tag_id = "+arg(tagID)+")")
+	}
+	if opts.CollectionID != nil {
+		where = append(where, "EXISTS (SELECT 1 FROM collection_documents cd WHERE cd.doc_id = d.id AND cd.collection_id = "+arg(*opts.CollectionID)+")")
+	}
+	if opts.Status != "" {
+		where = append(where, "d.status = "+arg(opts.Status))
+	}
+	if opts.Reading != "" {
+		filter, ok := readingFilters[opts.Reading]
+		if !ok {
+			return nil, "", fmt.Errorf("unknown reading filter %q", opts.Reading)
+		}
+		where = append(where, filter)
+	}
+	if opts.Visibility != "" {
+		where = append(where, "d.visibility = "+arg(opts.Visibility))
+	}
+	if opts.ModifiedSince != nil {
+		where = append(where, "d.updated_at > "+arg(*opts.ModifiedSince))
+	}
+	if opts.HasContent != nil {
+		if *opts.HasContent {
+			where = append(where, "d.content IS NOT NULL")
+		} else {
+			where = append(where, "d.content IS NULL")
+		}
+	}
+
+	direction, comparison := "DESC", "<"
+	if ascending {
+		direction, comparison = "ASC", ">"
+	}
+	if opts.Cursor != "" {
+		cursor, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if cursor.Sort != sortKey || cursor.Ascending != ascending {
+			return nil, "", ErrInvalidCursor
+		}
+		where = append(where, fmt.Sprintf("(%s, d.id) %s (%s::%s, %s)",
+			sort.expr, comparison, arg(cursor.Key), sort.cast, arg(cursor.ID)))
+	}
+
+	limit := ""
+	if opts.Limit > 0 {
+		// Fetch one extra row to learn whether another page follows
+		limit = "LIMIT " + arg(opts.Limit+1)
+	}
+
+	query := `
+		SELECT d.id, d.user_id, d.title, d.status, d.token_count, d.chunk_count, d.visibility, d.share_token, d.expires_at, d.created_at,
+			   d.content IS NOT NULL, d.source_type, COALESCE(d.author, ''), COALESCE(d.source_url, ''), COALESCE(d.language, ''), d.analytics, d.updated_at, d.version,
+			   COALESCE(rs.token_index, 0), COALESCE(rs.wpm, (SELECT (u.settings->>'defaultWpm')::int FROM users u WHERE u.id = $1), 300),
+			   COALESCE(rs.updated_at, d.created_at),
+			   ARRAY(SELECT dt.tag_id::text FROM document_tags dt WHERE dt.doc_id = d.id ORDER BY dt.created_at),
+			   (` + sort.expr + `)::text
+		FROM documents d
+		LEFT JOIN reading_state rs ON d.id = rs.doc_id AND rs.user_id = $1
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY ` + sort.expr + ` ` + direction + `, d.id ` + direction + `
+		` + limit
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []DocumentWithProgress
+	var lastKey string
+	for rows.Next() {
+		var doc DocumentWithProgress
+		var docUserID, shareToken sql.NullString
+		var expiresAt sql.NullTime
+		var tagIDs []string
+		var sortValue string
+		var analytics []byte
+		var modifiedAt time.Time
+		err := rows.Scan(
+			&doc.ID, &docUserID, &doc.Title, &doc.Status, &doc.TokenCount, &doc.ChunkCount, &doc.Visibility, &shareToken, &expiresAt, &doc.CreatedAt,
+			&doc.HasContent, &doc.SourceType, &doc.Author, &doc.SourceURL, &doc.Language, &analytics, &modifiedAt, &doc.Version,
+			&doc.TokenIndex, &doc.WPM, &doc.UpdatedAt, pq.Array(&tagIDs), &sortValue,
+		)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to scan document: %w", err)
+		}
+		doc.TagIDs = make([]uuid.UUID, 0, len(tagIDs))
+		for _, id := range tagIDs {
+			if tagID, err := uuid.Parse(id); err == nil {
+				doc.TagIDs = append(doc.TagIDs, tagID)
+			}
+		}
+		if docUserID.Valid {
+			uid, _ := uuid.Parse(docUserID.String)
+			doc.UserID = &uid
+		}
+		if shareToken.Valid {
+			st, _ := uuid.Parse(shareToken.String)
+			doc.ShareToken = &st
+		}
+		if expiresAt.Valid {
+			doc.ExpiresAt = &expiresAt.Time
+		}
+		doc.Analytics = scanAnalytics(analytics)
+		doc.ModifiedAt = &modifiedAt
+		remaining := RemainingSeconds(doc.Analytics, doc.TokenIndex, doc.TokenCount, doc.WPM)
+		doc.RemainingSeconds = &remaining
+
+		if opts.Limit > 0 && len(docs) == opts.Limit {
+			// The extra row: another page follows this one
+			last := docs[len(docs)-1]
+			next := encodeCursor(listCursor{Sort: sortKey, Ascending: ascending, Key: lastKey, ID: last.ID})
+			return docs, next, rows.Err()
+		}
+		docs = append(docs, doc)
+		lastKey = sortValue
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("error iterating documents: %w", err)
+	}
+
+	return docs, "", nil
+}
@@ -0,0 +1,44 @@
+package documents
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestListCursorRoundTrip(t *testing.T) {
+	want := listCursor{Sort: SortTitle, Ascending: true, Key: "moby dick", ID: uuid.New()}
+
+	got, err := decodeCursor(encodeCursor(want))
+	if err != nil {
+		t.Fatalf("decodeCursor failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("cursor = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, s := range []string{"not base64!", "bm90IGpzb24"} {
+		if _, err := decodeCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeCursor(%q) = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
+
+func TestListSortsAndFilters(t *testing.T) {
+	for _, s := range []ListSort{SortCreated, SortTitle, SortLastRead, SortProgress, SortLength} {
+		if !ValidSort(s) {
+			t.Errorf("expected %q to be a valid sort", s)
+		}
+	}
+	if ValidSort("random") {
+		t.Error("expected unknown sort to be invalid")
+	}
+	for _, f := range []ReadingFilter{ReadingUnread, ReadingInProgress, ReadingFinished} {
+		if !ValidReadingFilter(f) {
+			t.Errorf("expected %q to be a valid reading filter", f)
+		}
+	}
+}
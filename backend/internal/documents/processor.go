@@ -0,0 +1,195 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// jobLease is how long a claimed job may run before another worker may reclaim it
+	jobLease = 2 * time.Minute
+
+	// jobTimeout bounds the time spent processing a single job
+	jobTimeout = 90 * time.Second
+
+	// defaultPollInterval is how often idle workers check for runnable jobs
+	defaultPollInterval = 2 * time.Second
+)
+
+// ProcessorConfig configures the background document processor
+type ProcessorConfig struct {
+	Workers      int           // number of worker goroutines
+	PerUserLimit int           // max jobs running concurrently for one user
+	PollInterval time.Duration // idle polling interval
+}
+
+// Processor runs queued document processing jobs on a pool of worker goroutines
+type Processor struct {
+	service *Service
+	config  ProcessorConfig
+	logger  *slog.Logger
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewProcessor creates a new background processor for the given service
+func NewProcessor(service *Service, config ProcessorConfig, logger *slog.Logger) *Processor {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.PerUserLimit <= 0 {
+		config.PerUserLimit = 1
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultPollInterval
+	}
+
+	return &Processor{
+		service: service,
+		config:  config,
+		logger:  logger,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start launches the worker goroutines
+func (p *Processor) Start() {
+	for i := 0; i < p.config.Workers; i++ {
+		p.wg.Add(1)
+		go p.work(i)
+	}
+}
+
+// Shutdown stops claiming new jobs and waits for in-flight jobs to finish.
+// If ctx expires first, unfinished jobs are left to be reclaimed after their lease.
+func (p *Processor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("processor shutdown: %w", ctx.Err())
+	}
+}
+
+// work is the main loop of a single worker goroutine
+func (p *Processor) work(id int) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain all runnable jobs before going idle
+		for p.runNext(id) {
+			select {
+			case <-p.stop:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case <-p.service.jobWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims and runs one job, reporting whether a job was found
+func (p *Processor) runNext(worker int) bool {
+	// Jobs run on their own context so shutdown drains rather than aborts them
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	job, err := p.service.repo.ClaimJob(ctx, p.config.PerUserLimit, jobLease)
+	if err != nil {
+		p.logger.Error("failed to claim document job",
+			slog.Int("worker", worker),
+			slog.String("error", err.Error()),
+		)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	start := time.Now()
+	logger := p.logger.With(
+		slog.Int("worker", worker),
+		slog.String("doc.id", job.DocID.String()),
+		slog.Int("job.attempt", job.Attempts),
+	)
+
+	// A reclaimed job may already have used up its attempts
+	if job.Attempts > job.MaxAttempts {
+		p.fail(ctx, logger, job, fmt.Errorf("exceeded %d attempts", job.MaxAttempts))
+		return true
+	}
+
+	doc, err := p.service.ProcessDocument(ctx, job.DocID)
+	if errors.Is(err, ErrContentChanged) {
+		// The edit queued the document again; leave its status to that job
+		if err := p.service.repo.CompleteJob(ctx, job.DocID); err != nil {
+			logger.Error("failed to complete document job", slog.String("error", err.Error()))
+		}
+		logger.Info("document edited during processing, discarded", slog.Duration("duration", time.Since(start)))
+		return true
+	}
+	if err != nil {
+		if job.Attempts >= job.MaxAttempts {
+			p.fail(ctx, logger, job, err)
+			return true
+		}
+
+		delay := retryDelay(job.Attempts)
+		if rerr := p.service.repo.RetryJob(ctx, job.DocID, delay, err.Error()); rerr != nil {
+			logger.Error("failed to reschedule document job", slog.String("error", rerr.Error()))
+		}
+		_ = p.service.repo.SetStatus(ctx, job.DocID, StatusPending)
+		logger.Warn("document job failed, retrying",
+			slog.String("error", err.Error()),
+			slog.Duration("retry_in", delay),
+		)
+		return true
+	}
+
+	if err := p.service.repo.CompleteJob(ctx, job.DocID); err != nil {
+		logger.Error("failed to complete document job", slog.String("error", err.Error()))
+	}
+	logger.Info("document processed",
+		slog.Int("doc.token_count", doc.TokenCount),
+		slog.Int("doc.chunk_count", doc.ChunkCount),
+		slog.Duration("duration", time.Since(start)),
+	)
+
+	return true
+}
+
+// fail marks a job as permanently failed, keeping the cause on the job. An
+// edited document keeps the tokens it had before the edit; one never
+// processed is marked as an error.
+func (p *Processor) fail(ctx context.Context, logger *slog.Logger, job *Job, cause error) {
+	if err := p.service.repo.FailJob(ctx, job.DocID, cause.Error()); err != nil {
+		logger.Error("failed to mark document job failed", slog.String("error", err.Error()))
+	}
+	_ = p.service.repo.FailProcessing(ctx, job.DocID)
+	_ = p.service.chunkStore.DiscardStaged(job.DocID)
+	logger.Error("document job failed permanently", slog.String("error", cause.Error()))
+}
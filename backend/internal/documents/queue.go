@@ -0,0 +1,330 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+)
+
+// MaxQueueLength caps how many documents a user's reading queue holds
+const MaxQueueLength = 500
+
+var (
+	// ErrNotQueued indicates a document that isn't in the user's reading queue
+	ErrNotQueued = errors.New("document not in queue")
+	// ErrQueueEmpty indicates a reading queue with nothing left to read
+	ErrQueueEmpty = errors.New("queue is empty")
+	// ErrQueueFull indicates a reading queue already at MaxQueueLength
+	ErrQueueFull = errors.New("queue is full")
+	// ErrQueueOrder indicates a reorder that doesn't list every queued
+	// document exactly once
+	ErrQueueOrder = errors.New("order must list every queued document once")
+)
+
+// unfinishedFilter matches documents the user hasn't finished reading
+var unfinishedFilter = "NOT (" + readingFilters[ReadingFinished] + ")"
+
+// QueueItem is a document in a user's reading queue with how far they've read
+type QueueItem struct {
+	Document
+	TokenIndex int       `json:"tokenIndex"`
+	QueuedAt   time.Time `json:"queuedAt"`
+}
+
+// QueueNext is the next document in the queue and where to resume reading it
+type QueueNext struct {
+	Document     *Document     `json:"document"`
+	ReadingState *ReadingState `json:"readingState"`
+}
+
+// isFinished reports whether a position counts as having read a document
+func isFinished(tokenIndex, tokenCount int) bool {
+	return tokenCount > 0 && float64(tokenIndex)/float64(tokenCount) >= FinishedThreshold
+}
+
+// sameDocuments reports whether order lists exactly the queued documents,
+// each once, in any order
+func sameDocuments(queued, order []uuid.UUID) bool {
+	if len(queued) != len(order) {
+		return false
+	}
+	remaining := make(map[uuid.UUID]bool, len(queued))
+	for _, id := range queued {
+		remaining[id] = true
+	}
+	for _, id := range order {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+	return true
+}
+
+// ListQueue retrieves the documents in a user's reading queue, in order.
+// Documents in the trash keep their place but aren't listed.
+func (r *Repository) ListQueue(ctx context.Context, userID uuid.UUID) ([]QueueItem, error) {
+	query := `
+		SELECT d.id, d.user_id, d.title, d.status, d.token_count, d.chunk_count, d.visibility, d.created_at, d.content IS NOT NULL,
+			   d.source_type, COALESCE(d.author, ''), COALESCE(d.source_url, ''), COALESCE(d.language, ''), d.version,
+			   COALESCE(rs.token_index, 0), q.queued_at
+		FROM reading_queue q
+		JOIN documents d ON d.id = q.doc_id AND d.deleted_at IS NULL
+		LEFT JOIN reading_state rs ON rs.doc_id = q.doc_id AND rs.user_id = q.user_id
+		WHERE q.user_id = $1
+		ORDER BY q.position, q.queued_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queue: %w", err)
+	}
+	defer rows.Close()
+
+	items := []QueueItem{}
+	for rows.Next() {
+		var item QueueItem
+		var docUserID sql.NullString
+		err := rows.Scan(
+			&item.ID, &docUserID, &item.Title, &item.Status, &item.TokenCount, &item.ChunkCount, &item.Visibility, &item.CreatedAt, &item.HasContent,
+			&item.SourceType, &item.Author, &item.SourceURL, &item.Language, &item.Version,
+			&item.TokenIndex, &item.QueuedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan queue item: %w", err)
+		}
+		if docUserID.Valid {
+			uid, _ := uuid.Parse(docUserID.String)
+			item.UserID = &uid
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating queue: %w", err)
+	}
+
+	return items, nil
+}
+
+// AddToQueue puts a document at the end of a user's queue, or at the front
+// when first is set. A document already queued stays where it is unless
+// first moves it to the front.
+func (r *Repository) AddToQueue(ctx context.Context, userID, docID uuid.UUID, first bool) error {
+	query := `
+		INSERT INTO reading_queue (user_id, doc_id, position)
+		SELECT $1, $2, CASE WHEN $3 THEN COALESCE(MIN(position), 0) - 1 ELSE COALESCE(MAX(position), -1) + 1 END
+		FROM reading_queue
+		WHERE user_id = $1
+		ON CONFLICT (user_id, doc_id) DO UPDATE SET position = EXCLUDED.position
+		WHERE $3
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, docID, first); err != nil {
+		return fmt.Errorf("failed to add to queue: %w", err)
+	}
+	return nil
+}
+
+// QueueLength counts the documents in a user's queue, including any in the
+// trash, and reports whether docID is one of them
+func (r *Repository) QueueLength(ctx context.Context, userID, docID uuid.UUID) (int, bool, error) {
+	query := `SELECT COUNT(*), COALESCE(BOOL_OR(doc_id = $2), false) FROM reading_queue WHERE user_id = $1`
+
+	var count int
+	var queued bool
+	if err := r.db.QueryRowContext(ctx, query, userID, docID).Scan(&count, &queued); err != nil {
+		return 0, false, fmt.Errorf("failed to count queue: %w", err)
+	}
+	return count, queued, nil
+}
+
+// RemoveFromQueue takes a document out of a user's queue
+func (r *Repository) RemoveFromQueue(ctx context.Context, userID, docID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM reading_queue WHERE user_id = $1 AND doc_id = $2`, userID, docID)
+	if err != nil {
+		return fmt.Errorf("failed to remove from queue: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotQueued
+	}
+
+	return nil
+}
+
+// ReorderQueue puts a user's queue in the given order, which must list every
+// document in it outside the trash exactly once
+func (r *Repository) ReorderQueue(ctx context.Context, userID uuid.UUID, order []uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT q.doc_id
+		FROM reading_queue q
+		JOIN documents d ON d.id = q.doc_id AND d.deleted_at IS NULL
+		WHERE q.user_id = $1
+		FOR UPDATE OF q
+	`
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list queue: %w", err)
+	}
+	var queued []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan queue item: %w", err)
+		}
+		queued = append(queued, id)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("error iterating queue: %w", err)
+	}
+	rows.Close()
+
+	if !sameDocuments(queued, order) {
+		return ErrQueueOrder
+	}
+
+	update := `UPDATE reading_queue SET position = $3 WHERE user_id = $1 AND doc_id = $2`
+	for i, id := range order {
+		if _, err := tx.ExecContext(ctx, update, userID, id, i); err != nil {
+			return fmt.Errorf("failed to reorder queue: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit queue order: %w", err)
+	}
+
+	return nil
+}
+
+// NextInQueue returns the first document in a user's queue they haven't
+// finished, skipping any in the trash. remove takes it out of the queue.
+func (r *Repository) NextInQueue(ctx context.Context, userID uuid.UUID, remove bool) (uuid.UUID, error) {
+	next := `
+		SELECT q.doc_id
+		FROM reading_queue q
+		JOIN documents d ON d.id = q.doc_id AND d.deleted_at IS NULL
+		LEFT JOIN reading_state rs ON rs.doc_id = q.doc_id AND rs.user_id = q.user_id
+		WHERE q.user_id = $1 AND ` + unfinishedFilter + `
+		ORDER BY q.position, q.queued_at
+		LIMIT 1
+	`
+	query := next
+	if remove {
+		query = `DELETE FROM reading_queue WHERE user_id = $1 AND doc_id = (` + next + `) RETURNING doc_id`
+	}
+
+	var docID uuid.UUID
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&docID)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, ErrQueueEmpty
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get next in queue: %w", err)
+	}
+
+	return docID, nil
+}
+
+// ListQueue retrieves the current user's reading queue
+func (s *Service) ListQueue(ctx context.Context) ([]QueueItem, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("user not found in context")
+	}
+
+	return s.repo.ListQueue(ctx, user.ID)
+}
+
+// AddToQueue queues one of the current user's documents, at the front when
+// first is set
+func (s *Service) AddToQueue(ctx context.Context, docID uuid.UUID, first bool) error {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("user not found in context")
+	}
+
+	owner, err := s.repo.IsOwner(ctx, docID, user.ID)
+	if err != nil {
+		return err
+	}
+	if !owner {
+		return fmt.Errorf("document not found or not owned by user")
+	}
+
+	count, queued, err := s.repo.QueueLength(ctx, user.ID, docID)
+	if err != nil {
+		return err
+	}
+	if !queued && count >= MaxQueueLength {
+		return ErrQueueFull
+	}
+
+	return s.repo.AddToQueue(ctx, user.ID, docID, first)
+}
+
+// RemoveFromQueue takes a document out of the current user's queue
+func (s *Service) RemoveFromQueue(ctx context.Context, docID uuid.UUID) error {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("user not found in context")
+	}
+
+	return s.repo.RemoveFromQueue(ctx, user.ID, docID)
+}
+
+// ReorderQueue puts the current user's queue in the given order
+func (s *Service) ReorderQueue(ctx context.Context, order []uuid.UUID) error {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("user not found in context")
+	}
+
+	return s.repo.ReorderQueue(ctx, user.ID, order)
+}
+
+// NextInQueue returns the next document in the current user's queue they
+// haven't finished and where to resume it. pop also takes it out of the
+// queue, for a client that's starting on it.
+func (s *Service) NextInQueue(ctx context.Context, pop bool) (*QueueNext, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("user not found in context")
+	}
+
+	docID, err := s.repo.NextInQueue(ctx, user.ID, pop)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.repo.GetByID(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.repo.GetReadingState(ctx, user.ID, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Non-fatal error: the estimate is a convenience
+	_ = s.FillRemainingTime(ctx, doc)
+
+	return &QueueNext{Document: doc, ReadingState: state}, nil
+}
@@ -0,0 +1,50 @@
+package documents
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestIsFinished(t *testing.T) {
+	tests := []struct {
+		tokenIndex, tokenCount int
+		want                   bool
+	}{
+		{0, 0, false},
+		{0, 100, false},
+		{98, 100, false},
+		{99, 100, true},
+		{100, 100, true},
+		{990, 1000, true},
+	}
+
+	for _, tt := range tests {
+		if got := isFinished(tt.tokenIndex, tt.tokenCount); got != tt.want {
+			t.Errorf("isFinished(%d, %d) = %v, want %v", tt.tokenIndex, tt.tokenCount, got, tt.want)
+		}
+	}
+}
+
+func TestSameDocuments(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	queued := []uuid.UUID{a, b, c}
+
+	if !sameDocuments(queued, []uuid.UUID{c, a, b}) {
+		t.Error("expected a reordering to match")
+	}
+	if !sameDocuments(nil, []uuid.UUID{}) {
+		t.Error("expected an empty order to match an empty queue")
+	}
+
+	for name, order := range map[string][]uuid.UUID{
+		"missing":  {a, b},
+		"repeated": {a, b, b},
+		"unknown":  {a, b, uuid.New()},
+		"extra":    {a, b, c, uuid.New()},
+	} {
+		if sameDocuments(queued, order) {
+			t.Errorf("expected %s document to be rejected", name)
+		}
+	}
+}
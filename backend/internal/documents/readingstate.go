@@ -0,0 +1,57 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrStaleReadingState indicates a reading state write that would overwrite a
+// newer position saved from elsewhere
+var ErrStaleReadingState = errors.New("stale reading state")
+
+// StaleReadingStateError reports the saved state a stale write lost to
+type StaleReadingStateError struct {
+	Current *ReadingState
+}
+
+func (e *StaleReadingStateError) Error() string {
+	return fmt.Sprintf("stale reading state, current version is %d", e.Current.Version)
+}
+
+// Is makes a StaleReadingStateError match ErrStaleReadingState
+func (e *StaleReadingStateError) Is(target error) bool {
+	return target == ErrStaleReadingState
+}
+
+// WriteCondition guards a reading state write against rewinding a position
+// saved from another tab or device. The write applies if it names the saved
+// version, i.e. the writer saw the latest position, or if the writer reached
+// its position after the saved one was reached, as when a device that was
+// offline catches up. With neither set the write always applies.
+type WriteCondition struct {
+	Version    *int64     // the version the writer last saw
+	ClientTime *time.Time // when the writer reached the position
+}
+
+// clampClientTime keeps a client's timestamp from running ahead of the
+// server, so a device with a fast clock can't lock out every other write
+func clampClientTime(t *time.Time, now time.Time) *time.Time {
+	if t == nil || !t.After(now) {
+		return t
+	}
+	return &now
+}
+
+// staleReadingState returns the error for a stale write to a user's position
+// in a document, carrying the position that was kept
+func (s *Service) staleReadingState(ctx context.Context, userID, docID uuid.UUID) error {
+	current, err := s.repo.GetReadingState(ctx, userID, docID)
+	if err != nil {
+		return err
+	}
+	return &StaleReadingStateError{Current: current}
+}
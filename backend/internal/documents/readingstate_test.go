@@ -0,0 +1,39 @@
+package documents
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClampClientTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if got := clampClientTime(nil, now); got != nil {
+		t.Errorf("expected nil to stay nil, got %v", got)
+	}
+
+	past := now.Add(-time.Hour)
+	if got := clampClientTime(&past, now); !got.Equal(past) {
+		t.Errorf("expected past time to be kept, got %v", got)
+	}
+
+	future := now.Add(time.Hour)
+	if got := clampClientTime(&future, now); !got.Equal(now) {
+		t.Errorf("expected future time to be clamped to %v, got %v", now, got)
+	}
+}
+
+func TestStaleReadingStateError(t *testing.T) {
+	current := &ReadingState{TokenIndex: 420, Version: 7}
+	err := fmt.Errorf("update failed: %w", &StaleReadingStateError{Current: current})
+
+	if !errors.Is(err, ErrStaleReadingState) {
+		t.Error("expected StaleReadingStateError to match ErrStaleReadingState")
+	}
+	var staleErr *StaleReadingStateError
+	if !errors.As(err, &staleErr) || staleErr.Current != current {
+		t.Error("expected the current state to be recoverable from the error")
+	}
+}
@@ -0,0 +1,359 @@
+package documents
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/storage"
+)
+
+// maxLCSCells bounds the dynamic-programming table used to align regions of
+// the token streams that have no unique words to anchor on. Larger regions
+// are treated as wholly replaced.
+const maxLCSCells = 1 << 22
+
+// tokenRemap translates token indices in a document's previous token stream to
+// the equivalent positions after a content edit
+type tokenRemap struct {
+	mapped []int // new index for each old token, -1 if it was deleted
+	newLen int
+}
+
+// newTokenRemap aligns the old and new token texts with a word-level diff.
+// Unchanged words map to their new position; words inside a replaced span map
+// to the corresponding offset in the replacement; words whose span was deleted
+// outright have no position. A nil old stream means the previous tokens are
+// unknown, so every index falls back to the start.
+func newTokenRemap(oldWords, newWords []string) *tokenRemap {
+	m := &tokenRemap{newLen: len(newWords)}
+	if oldWords == nil {
+		return m
+	}
+
+	matches := matchTokens(oldWords, newWords)
+	m.mapped = make([]int, len(oldWords))
+
+	prevNew := -1
+	for i := 0; i < len(oldWords); {
+		if matches[i] >= 0 {
+			m.mapped[i] = matches[i]
+			prevNew = matches[i]
+			i++
+			continue
+		}
+
+		// Hunk of old tokens [i, j) replaced by new tokens (prevNew, nextNew)
+		j := i
+		for j < len(oldWords) && matches[j] < 0 {
+			j++
+		}
+		nextNew := len(newWords)
+		if j < len(oldWords) {
+			nextNew = matches[j]
+		}
+		for k := i; k < j; k++ {
+			if prevNew+1 < nextNew {
+				m.mapped[k] = min(prevNew+1+(k-i), nextNew-1)
+			} else {
+				m.mapped[k] = -1
+			}
+		}
+		i = j
+	}
+
+	return m
+}
+
+// Map returns the new index for an old token index, falling back to 0 when the
+// token at that position was deleted
+func (m *tokenRemap) Map(index int) int {
+	switch {
+	case m.mapped == nil || index < 0:
+		return 0
+	case index >= len(m.mapped):
+		return m.newLen
+	case m.mapped[index] < 0:
+		return 0
+	default:
+		return m.mapped[index]
+	}
+}
+
+// MapRange returns the new inclusive span covering whatever survives of the
+// old span [start, end]. If every token in it was deleted, the span collapses
+// onto the nearest surviving token after it, or failing that before it, so
+// whatever is attached to the span isn't lost.
+func (m *tokenRemap) MapRange(start, end int) (int, int) {
+	if m.newLen == 0 {
+		return 0, 0
+	}
+	last := m.newLen - 1
+	if m.mapped == nil {
+		// Without the old tokens, keep the numeric offsets where they still fit
+		return min(max(start, 0), last), min(max(end, 0), last)
+	}
+	if start >= len(m.mapped) {
+		return last, last
+	}
+	start = max(start, 0)
+	end = min(max(end, start), len(m.mapped)-1)
+
+	first, final := -1, -1
+	for i := start; i <= end; i++ {
+		if m.mapped[i] >= 0 {
+			first = m.mapped[i]
+			break
+		}
+	}
+	for i := end; i >= start && first >= 0; i-- {
+		if m.mapped[i] >= 0 {
+			final = m.mapped[i]
+			break
+		}
+	}
+	if first >= 0 {
+		return first, max(first, final)
+	}
+
+	for i := end + 1; i < len(m.mapped); i++ {
+		if m.mapped[i] >= 0 {
+			return m.mapped[i], m.mapped[i]
+		}
+	}
+	for i := start - 1; i >= 0; i-- {
+		if m.mapped[i] >= 0 {
+			return m.mapped[i], m.mapped[i]
+		}
+	}
+	return 0, 0
+}
+
+// tokenDiff aligns two token streams, recording matches from old to new
+type tokenDiff struct {
+	old, new []string
+	matches  []int
+}
+
+// matchTokens returns, for each old token, the index of the identical token it
+// corresponds to in the new stream, or -1 when it has no counterpart. It is a
+// patience diff: words occurring exactly once on both sides anchor the
+// alignment, and the gaps between anchors are aligned recursively.
+func matchTokens(old, new []string) []int {
+	d := &tokenDiff{old: old, new: new, matches: make([]int, len(old))}
+	for i := range d.matches {
+		d.matches[i] = -1
+	}
+	d.match(0, len(old), 0, len(new))
+	return d.matches
+}
+
+func (d *tokenDiff) match(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.old[aLo] == d.new[bLo] {
+		d.matches[aLo] = bLo
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && d.old[aHi-1] == d.new[bHi-1] {
+		aHi--
+		bHi--
+		d.matches[aHi] = bHi
+	}
+	if aLo == aHi || bLo == bHi {
+		return
+	}
+
+	anchors := d.uniqueAnchors(aLo, aHi, bLo, bHi)
+	if len(anchors) == 0 {
+		d.lcs(aLo, aHi, bLo, bHi)
+		return
+	}
+
+	for _, anchor := range anchors {
+		d.match(aLo, anchor[0], bLo, anchor[1])
+		d.matches[anchor[0]] = anchor[1]
+		aLo, bLo = anchor[0]+1, anchor[1]+1
+	}
+	d.match(aLo, aHi, bLo, bHi)
+}
+
+// uniqueAnchors finds words occurring exactly once in both ranges and returns
+// the longest run of them that appears in the same order on both sides
+func (d *tokenDiff) uniqueAnchors(aLo, aHi, bLo, bHi int) [][2]int {
+	type occurrence struct {
+		countA, countB int
+		posA, posB     int
+	}
+
+	seen := make(map[string]*occurrence)
+	for i := aLo; i < aHi; i++ {
+		o := seen[d.old[i]]
+		if o == nil {
+			o = &occurrence{}
+			seen[d.old[i]] = o
+		}
+		o.countA++
+		o.posA = i
+	}
+	for j := bLo; j < bHi; j++ {
+		if o := seen[d.new[j]]; o != nil {
+			o.countB++
+			o.posB = j
+		}
+	}
+
+	var candidates [][2]int
+	for i := aLo; i < aHi; i++ {
+		if o := seen[d.old[i]]; o.countA == 1 && o.countB == 1 {
+			candidates = append(candidates, [2]int{i, o.posB})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Longest increasing subsequence of new positions, by patience sorting
+	var (
+		tails = []int{}                      // candidate index ending each pile
+		prev  = make([]int, len(candidates)) // back-pointers
+	)
+	for i, c := range candidates {
+		pile := sort.Search(len(tails), func(k int) bool { return candidates[tails[k]][1] >= c[1] })
+		if pile > 0 {
+			prev[i] = tails[pile-1]
+		} else {
+			prev[i] = -1
+		}
+		if pile == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[pile] = i
+		}
+	}
+
+	anchors := make([][2]int, len(tails))
+	for i, k := len(tails)-1, tails[len(tails)-1]; i >= 0; i, k = i-1, prev[k] {
+		anchors[i] = candidates[k]
+	}
+	return anchors
+}
+
+// lcs aligns a small region exactly with a longest-common-subsequence table
+func (d *tokenDiff) lcs(aLo, aHi, bLo, bHi int) {
+	n, m := aHi-aLo, bHi-bLo
+	if n*m > maxLCSCells {
+		return
+	}
+
+	// table[i][j] is the LCS length of old[aLo+i:aHi] and new[bLo+j:bHi]
+	width := m + 1
+	table := make([]int32, (n+1)*width)
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if d.old[aLo+i] == d.new[bLo+j] {
+				table[i*width+j] = table[(i+1)*width+j+1] + 1
+			} else {
+				table[i*width+j] = max(table[(i+1)*width+j], table[i*width+j+1])
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case d.old[aLo+i] == d.new[bLo+j]:
+			d.matches[aLo+i] = bLo + j
+			i++
+			j++
+		case table[(i+1)*width+j] >= table[i*width+j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+}
+
+// RemapReadingStates moves every user's saved position in a document through
+// remap, leaving updated_at alone since nobody actually read anything. The
+// version is bumped so positions saved against the old text are stale, and
+// changed_at so sync sends the moved positions.
+func (r *Repository) RemapReadingStates(ctx context.Context, docID uuid.UUID, remap func(int) int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT user_id, token_index FROM reading_state WHERE doc_id = $1 FOR UPDATE`, docID)
+	if err != nil {
+		return fmt.Errorf("failed to list reading states: %w", err)
+	}
+
+	type position struct {
+		userID     uuid.UUID
+		tokenIndex int
+	}
+	var positions []position
+	for rows.Next() {
+		var p position
+		if err := rows.Scan(&p.userID, &p.tokenIndex); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan reading state: %w", err)
+		}
+		positions = append(positions, p)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("error iterating reading states: %w", err)
+	}
+	rows.Close()
+
+	update := `UPDATE reading_state SET token_index = $3, version = version + 1, changed_at = NOW() WHERE user_id = $1 AND doc_id = $2`
+	for _, p := range positions {
+		mapped := remap(p.tokenIndex)
+		if mapped == p.tokenIndex {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, update, p.userID, docID, mapped); err != nil {
+			return fmt.Errorf("failed to remap reading state: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reading states: %w", err)
+	}
+	return nil
+}
+
+// tokenWords returns the text of each token in a stream
+func tokenWords(tokens []storage.Token) []string {
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.Text
+	}
+	return words
+}
+
+// processedWords returns the token texts of a document's current chunks,
+// which readers' positions point into. Nil means they can't be read and the
+// old stream is unknown.
+func (s *Service) processedWords(doc *Document) []string {
+	words := make([]string, 0, doc.TokenCount)
+	for i := 0; i < doc.ChunkCount; i++ {
+		chunk, err := s.chunkStore.ReadChunk(doc.ID, i)
+		if err != nil {
+			return nil
+		}
+		words = append(words, tokenWords(chunk.Tokens)...)
+	}
+	return words
+}
+
+// remapAnchors carries token-anchored data across a content edit so readers
+// keep their place in the text rather than their numeric offset
+func (s *Service) remapAnchors(ctx context.Context, docID uuid.UUID, remap *tokenRemap) error {
+	if err := s.repo.RemapReadingStates(ctx, docID, remap.Map); err != nil {
+		return err
+	}
+	return s.repo.RemapHighlights(ctx, docID, remap.MapRange)
+}
@@ -0,0 +1,108 @@
+package documents
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenRemap(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		index    int
+		want     int
+	}{
+		{"unchanged", "the quick brown fox", "the quick brown fox", 2, 2},
+		{"insert before", "the quick brown fox", "well then the quick brown fox", 2, 4},
+		{"insert after", "the quick brown fox", "the quick brown fox jumps", 3, 3},
+		{"delete before", "one two three four five", "four five", 3, 0},
+		{"delete before keeps later words", "one two three four five", "four five", 4, 1},
+		{"anchor deleted", "one two three four five", "one two four five", 2, 0},
+		{"replaced word", "one two three four five", "one two THREE four five", 2, 2},
+		{"replaced span", "a b c d e f", "a x y z f", 3, 3},
+		{"replacement shorter", "a b c d e f", "a x f", 4, 1},
+		{"end of document", "a b c", "z a b c", 3, 4},
+		{"negative index", "a b c", "a b c", -1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remap := newTokenRemap(strings.Fields(tt.old), strings.Fields(tt.new))
+			if got := remap.Map(tt.index); got != tt.want {
+				t.Errorf("Map(%d) = %d, want %d", tt.index, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenRemap_UnknownOldTokens(t *testing.T) {
+	remap := newTokenRemap(nil, strings.Fields("a b c"))
+	if got := remap.Map(2); got != 0 {
+		t.Errorf("expected fallback to 0 without old tokens, got %d", got)
+	}
+}
+
+func TestTokenRemap_MapRange(t *testing.T) {
+	tests := []struct {
+		name               string
+		old, new           string
+		start, end         int
+		wantStart, wantEnd int
+	}{
+		{"unchanged", "a b c d e", "a b c d e", 1, 3, 1, 3},
+		{"shifted", "a b c d e", "x y a b c d e", 1, 3, 3, 5},
+		{"grows with insertion", "a b c d e", "a b new words c d e", 1, 3, 1, 5},
+		{"edges trimmed", "a b c d e", "a c e", 1, 3, 1, 1},
+		{"deleted collapses forward", "a b c d e", "a e", 1, 3, 1, 1},
+		{"deleted at end collapses back", "a b c d e", "a b", 3, 4, 1, 1},
+		{"past end", "a b c", "a b c d", 5, 6, 3, 3},
+		{"everything deleted", "a b c", "x", 0, 2, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remap := newTokenRemap(strings.Fields(tt.old), strings.Fields(tt.new))
+			start, end := remap.MapRange(tt.start, tt.end)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("MapRange(%d, %d) = (%d, %d), want (%d, %d)", tt.start, tt.end, start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestTokenRemap_MapRangeUnknownOldTokens(t *testing.T) {
+	remap := newTokenRemap(nil, strings.Fields("a b c"))
+	if start, end := remap.MapRange(1, 5); start != 1 || end != 2 {
+		t.Errorf("expected offsets clamped to (1, 2), got (%d, %d)", start, end)
+	}
+}
+
+func TestMatchTokens_RepeatedWords(t *testing.T) {
+	// Common words repeat, so the alignment has to come from the rare ones
+	old := strings.Fields("the cat sat on the mat and the dog sat on the rug")
+	new := strings.Fields("the cat sat on the mat and then the dog sat on the rug")
+
+	matches := matchTokens(old, new)
+	for i := range old {
+		want := i
+		if i >= 7 {
+			want = i + 1
+		}
+		if matches[i] != want {
+			t.Errorf("token %d (%q) matched %d, want %d", i, old[i], matches[i], want)
+		}
+	}
+}
+
+func TestMatchTokens_MovedParagraph(t *testing.T) {
+	old := strings.Fields("alpha beta gamma delta epsilon")
+	new := strings.Fields("delta epsilon alpha beta gamma")
+
+	matches := matchTokens(old, new)
+	// The longer run stays matched; the moved words become a replacement
+	for i, want := range []int{2, 3, 4, -1, -1} {
+		if matches[i] != want {
+			t.Errorf("token %d (%q) matched %d, want %d", i, old[i], matches[i], want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package documents
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -27,6 +28,18 @@ const (
 	VisibilityPublic  Visibility = "public"
 )
 
+// SourceType records how a document's text was provided
+type SourceType string
+
+const (
+	SourcePaste SourceType = "paste"
+	SourceEPUB  SourceType = "epub"
+	SourceHTML  SourceType = "html"
+	SourceDOCX  SourceType = "docx"
+	SourceODT   SourceType = "odt"
+	SourcePDF   SourceType = "pdf"
+)
+
 // Document represents a stored document
 type Document struct {
 	ID         uuid.UUID      `json:"id"`
@@ -40,6 +53,18 @@ type Document struct {
 	ExpiresAt  *time.Time     `json:"expiresAt,omitempty"`
 	CreatedAt  time.Time      `json:"createdAt"`
 	HasContent bool           `json:"hasContent"` // True if original content is stored (for editing)
+	SourceType SourceType     `json:"sourceType"`
+	Author     string         `json:"author,omitempty"`
+	SourceURL  string         `json:"sourceUrl,omitempty"`
+	Language   string         `json:"language,omitempty"` // detected ISO 639-1 code
+	Analytics  *Analytics     `json:"analytics,omitempty"`
+	Chapters   []Chapter      `json:"chapters,omitempty"`   // sections of imported documents, once processed
+	ModifiedAt *time.Time     `json:"modifiedAt,omitempty"` // last change to the document itself
+	Version    int64          `json:"version"`              // counts title and content edits; the ETag
+
+	// RemainingSeconds is the time left to read at the user's speed, set
+	// where a response reports it
+	RemainingSeconds *int `json:"remainingSeconds,omitempty"`
 }
 
 // ReadingState represents the user's reading progress
@@ -50,6 +75,11 @@ type ReadingState struct {
 	WPM        int       `json:"wpm"`
 	ChunkSize  int       `json:"chunkSize"`
 	UpdatedAt  time.Time `json:"updatedAt"`
+
+	// Version counts writes to the position, 0 before the first.
+	// ClientUpdatedAt is when the writing device reached the position.
+	Version         int64     `json:"version"`
+	ClientUpdatedAt time.Time `json:"clientUpdatedAt"`
 }
 
 // Repository handles database operations for documents
@@ -64,10 +94,15 @@ func NewRepository(db *sql.DB) *Repository {
 
 // CreateParams contains parameters for creating a document
 type CreateParams struct {
-	Title     string
-	Content   string // Original text content for editing
-	UserID    uuid.UUID
-	ExpiresAt *time.Time
+	Title      string
+	Content    string // Original text content for editing
+	UserID     uuid.UUID
+	ExpiresAt  *time.Time
+	SourceType SourceType
+	Author     string
+	SourceURL  string    // page the document was imported from, if any
+	Language   string    // detected ISO 639-1 code, empty if unknown
+	Headings   []Heading // section titles of an imported document, if any
 }
 
 // Create inserts a new document
@@ -81,11 +116,19 @@ func (r *Repository) Create(ctx context.Context, params *CreateParams) (*Documen
 		ExpiresAt:  params.ExpiresAt,
 		CreatedAt:  time.Now(),
 		HasContent: params.Content != "",
+		SourceType: params.SourceType,
+		Author:     params.Author,
+		SourceURL:  params.SourceURL,
+		Language:   params.Language,
+	}
+	if doc.SourceType == "" {
+		doc.SourceType = SourcePaste
 	}
 
 	query := `
-		INSERT INTO documents (id, user_id, title, status, token_count, chunk_count, visibility, expires_at, created_at, content)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO documents (id, user_id, title, status, token_count, chunk_count, visibility, expires_at, created_at, content, source_type, author, source_url,
+			language, search_config, content_hash, headings)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15::regconfig, NULLIF($16, ''), $17)
 	`
 
 	// Store content as NULL if empty (for backward compatibility)
@@ -94,8 +137,33 @@ func (r *Repository) Create(ctx context.Context, params *CreateParams) (*Documen
 		content = &params.Content
 	}
 
+	var author *string
+	if params.Author != "" {
+		author = &params.Author
+	}
+
+	var sourceURL *string
+	if params.SourceURL != "" {
+		sourceURL = &params.SourceURL
+	}
+
+	var language *string
+	if params.Language != "" {
+		language = &params.Language
+	}
+
+	var headings []byte
+	if len(params.Headings) > 0 {
+		data, err := json.Marshal(params.Headings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode headings: %w", err)
+		}
+		headings = data
+	}
+
 	_, err := r.db.ExecContext(ctx, query,
-		doc.ID, doc.UserID, doc.Title, doc.Status, doc.TokenCount, doc.ChunkCount, doc.Visibility, doc.ExpiresAt, doc.CreatedAt, content)
+		doc.ID, doc.UserID, doc.Title, doc.Status, doc.TokenCount, doc.ChunkCount, doc.Visibility, doc.ExpiresAt, doc.CreatedAt, content, doc.SourceType, author, sourceURL,
+		language, searchConfig(params.Language), ContentHash(params.Content), headings)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert document: %w", err)
 	}
@@ -106,16 +174,20 @@ func (r *Repository) Create(ctx context.Context, params *CreateParams) (*Documen
 // GetByID retrieves a document by ID
 func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*Document, error) {
 	query := `
-		SELECT id, user_id, title, status, token_count, chunk_count, visibility, share_token, expires_at, created_at, content IS NOT NULL
+		SELECT id, user_id, title, status, token_count, chunk_count, visibility, share_token, expires_at, created_at, content IS NOT NULL,
+			   source_type, COALESCE(author, ''), COALESCE(source_url, ''), COALESCE(language, ''), analytics, updated_at, version, chapters
 		FROM documents
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	doc := &Document{}
 	var userID, shareToken sql.NullString
 	var expiresAt sql.NullTime
+	var analytics, chapters []byte
+	var modifiedAt time.Time
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&doc.ID, &userID, &doc.Title, &doc.Status, &doc.TokenCount, &doc.ChunkCount, &doc.Visibility, &shareToken, &expiresAt, &doc.CreatedAt, &doc.HasContent)
+		&doc.ID, &userID, &doc.Title, &doc.Status, &doc.TokenCount, &doc.ChunkCount, &doc.Visibility, &shareToken, &expiresAt, &doc.CreatedAt, &doc.HasContent,
+		&doc.SourceType, &doc.Author, &doc.SourceURL, &doc.Language, &analytics, &modifiedAt, &doc.Version, &chapters)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("document not found")
@@ -134,15 +206,53 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*Document, erro
 	if expiresAt.Valid {
 		doc.ExpiresAt = &expiresAt.Time
 	}
+	doc.Analytics = scanAnalytics(analytics)
+	doc.Chapters = scanChapters(chapters)
+	doc.ModifiedAt = &modifiedAt
 
 	return doc, nil
 }
 
+// FinishProcessing marks a document ready with its token and chunk counts
+// and chapters, provided it is still at the version that was processed.
+// Otherwise it was edited meanwhile and ErrContentChanged is returned.
+func (r *Repository) FinishProcessing(ctx context.Context, id uuid.UUID, version int64, tokenCount, chunkCount int, chapters []Chapter) error {
+	var data []byte
+	if len(chapters) > 0 {
+		encoded, err := json.Marshal(chapters)
+		if err != nil {
+			return fmt.Errorf("failed to encode chapters: %w", err)
+		}
+		data = encoded
+	}
+
+	query := `
+		UPDATE documents
+		SET status = 'ready', token_count = $3, chunk_count = $4, chapters = $5, updated_at = NOW()
+		WHERE id = $1 AND version = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, version, tokenCount, chunkCount, data)
+	if err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrContentChanged
+	}
+
+	return nil
+}
+
 // UpdateStatus updates the document status and counts
 func (r *Repository) UpdateStatus(ctx context.Context, id uuid.UUID, status DocumentStatus, tokenCount, chunkCount int) error {
 	query := `
 		UPDATE documents
-		SET status = $2, token_count = $3, chunk_count = $4
+		SET status = $2, token_count = $3, chunk_count = $4, updated_at = NOW()
 		WHERE id = $1
 	`
 
@@ -162,27 +272,65 @@ func (r *Repository) UpdateStatus(ctx context.Context, id uuid.UUID, status Docu
 	return nil
 }
 
+// SetStatus updates the document status, keeping the counts of the chunks
+// readers are served until processing replaces them
+func (r *Repository) SetStatus(ctx context.Context, id uuid.UUID, status DocumentStatus) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE documents SET status = $2, updated_at = NOW() WHERE id = $1`, id, status)
+	if err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("document not found")
+	}
+
+	return nil
+}
+
+// FailProcessing ends processing that can't succeed. A document processed
+// before, whose edit failed, goes back to ready with the tokens it had; one
+// that never was is marked as an error.
+func (r *Repository) FailProcessing(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE documents
+		SET status = CASE WHEN token_count > 0 THEN 'ready' ELSE 'error' END, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+	return nil
+}
+
 // GetReadingState retrieves the reading state for a document and user
 func (r *Repository) GetReadingState(ctx context.Context, userID, docID uuid.UUID) (*ReadingState, error) {
 	query := `
-		SELECT user_id, doc_id, token_index, wpm, chunk_size, updated_at
+		SELECT user_id, doc_id, token_index, wpm, chunk_size, updated_at, version, client_updated_at
 		FROM reading_state
 		WHERE user_id = $1 AND doc_id = $2
 	`
 
 	state := &ReadingState{}
 	err := r.db.QueryRowContext(ctx, query, userID, docID).Scan(
-		&state.UserID, &state.DocID, &state.TokenIndex, &state.WPM, &state.ChunkSize, &state.UpdatedAt)
+		&state.UserID, &state.DocID, &state.TokenIndex, &state.WPM, &state.ChunkSize, &state.UpdatedAt,
+		&state.Version, &state.ClientUpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// Return default state if not found
+			now := time.Now()
 			return &ReadingState{
-				UserID:     userID,
-				DocID:      docID,
-				TokenIndex: 0,
-				WPM:        300,
-				ChunkSize:  1,
-				UpdatedAt:  time.Now(),
+				UserID:          userID,
+				DocID:           docID,
+				TokenIndex:      0,
+				WPM:             300,
+				ChunkSize:       1,
+				UpdatedAt:       now,
+				ClientUpdatedAt: now,
 			}, nil
 		}
 		return nil, fmt.Errorf("failed to get reading state: %w", err)
@@ -191,43 +339,41 @@ func (r *Repository) GetReadingState(ctx context.Context, userID, docID uuid.UUI
 	return state, nil
 }
 
-// UpsertReadingState creates or updates reading state
-func (r *Repository) UpsertReadingState(ctx context.Context, state *ReadingState) error {
+// UpsertReadingState creates or updates reading state if cond allows it,
+// setting the state's version and times to those saved. If cond rejects the
+// write, nothing is saved and it returns ErrStaleReadingState.
+func (r *Repository) UpsertReadingState(ctx context.Context, state *ReadingState, cond WriteCondition) error {
 	query := `
-		INSERT INTO reading_state (user_id, doc_id, token_index, wpm, chunk_size, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO reading_state (user_id, doc_id, token_index, wpm, chunk_size, updated_at, client_updated_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 1)
 		ON CONFLICT (user_id, doc_id) DO UPDATE SET
 			token_index = EXCLUDED.token_index,
 			wpm = EXCLUDED.wpm,
 			chunk_size = EXCLUDED.chunk_size,
-			updated_at = EXCLUDED.updated_at
+			updated_at = EXCLUDED.updated_at,
+			client_updated_at = EXCLUDED.client_updated_at,
+			version = reading_state.version + 1,
+			changed_at = NOW()
+		WHERE ($8::bigint IS NULL AND $9::timestamptz IS NULL)
+			OR reading_state.version = $8
+			OR reading_state.client_updated_at < $9
+		RETURNING version
 	`
 
 	state.UpdatedAt = time.Now()
-	_, err := r.db.ExecContext(ctx, query,
-		state.UserID, state.DocID, state.TokenIndex, state.WPM, state.ChunkSize, state.UpdatedAt)
-	if err != nil {
-		return fmt.Errorf("failed to upsert reading state: %w", err)
+	state.ClientUpdatedAt = state.UpdatedAt
+	if cond.ClientTime != nil {
+		state.ClientUpdatedAt = *cond.ClientTime
 	}
 
-	return nil
-}
-
-// Delete removes a document owned by a user
-func (r *Repository) Delete(ctx context.Context, id, userID uuid.UUID) error {
-	query := `DELETE FROM documents WHERE id = $1 AND user_id = $2`
-
-	result, err := r.db.ExecContext(ctx, query, id, userID)
-	if err != nil {
-		return fmt.Errorf("failed to delete document: %w", err)
+	err := r.db.QueryRowContext(ctx, query,
+		state.UserID, state.DocID, state.TokenIndex, state.WPM, state.ChunkSize, state.UpdatedAt, state.ClientUpdatedAt,
+		cond.Version, cond.ClientTime).Scan(&state.Version)
+	if err == sql.ErrNoRows {
+		return ErrStaleReadingState
 	}
-
-	rows, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-	if rows == 0 {
-		return fmt.Errorf("document not found or not owned by user")
+		return fmt.Errorf("failed to upsert reading state: %w", err)
 	}
 
 	return nil
@@ -236,68 +382,22 @@ func (r *Repository) Delete(ctx context.Context, id, userID uuid.UUID) error {
 // DocumentWithProgress combines document metadata with reading progress
 type DocumentWithProgress struct {
 	Document
-	TokenIndex int       `json:"tokenIndex"`
-	WPM        int       `json:"wpm"`
-	UpdatedAt  time.Time `json:"updatedAt"`
+	TokenIndex int         `json:"tokenIndex"`
+	WPM        int         `json:"wpm"`
+	UpdatedAt  time.Time   `json:"updatedAt"`
+	TagIDs     []uuid.UUID `json:"tagIds"`
 }
 
-// List retrieves all documents for a user with their reading progress
-func (r *Repository) List(ctx context.Context, userID uuid.UUID) ([]DocumentWithProgress, error) {
+// UpdateTitle updates only the title of a document owned by a user and bumps
+// its version. With expected set, the title is only updated at that version.
+func (r *Repository) UpdateTitle(ctx context.Context, id, userID uuid.UUID, title string, expected *int64) error {
 	query := `
-		SELECT d.id, d.user_id, d.title, d.status, d.token_count, d.chunk_count, d.visibility, d.share_token, d.expires_at, d.created_at,
-			   d.content IS NOT NULL,
-			   COALESCE(rs.token_index, 0), COALESCE(rs.wpm, 300), COALESCE(rs.updated_at, d.created_at)
-		FROM documents d
-		LEFT JOIN reading_state rs ON d.id = rs.doc_id AND rs.user_id = $1
-		WHERE d.user_id = $1
-		ORDER BY COALESCE(rs.updated_at, d.created_at) DESC
+		UPDATE documents SET title = $2, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND user_id = $3 AND deleted_at IS NULL AND ($4::bigint IS NULL OR version = $4)
+		  AND status NOT IN ('pending', 'processing')
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list documents: %w", err)
-	}
-	defer rows.Close()
-
-	var docs []DocumentWithProgress
-	for rows.Next() {
-		var doc DocumentWithProgress
-		var docUserID, shareToken sql.NullString
-		var expiresAt sql.NullTime
-		err := rows.Scan(
-			&doc.ID, &docUserID, &doc.Title, &doc.Status, &doc.TokenCount, &doc.ChunkCount, &doc.Visibility, &shareToken, &expiresAt, &doc.CreatedAt,
-			&doc.HasContent,
-			&doc.TokenIndex, &doc.WPM, &doc.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan document: %w", err)
-		}
-		if docUserID.Valid {
-			uid, _ := uuid.Parse(docUserID.String)
-			doc.UserID = &uid
-		}
-		if shareToken.Valid {
-			st, _ := uuid.Parse(shareToken.String)
-			doc.ShareToken = &st
-		}
-		if expiresAt.Valid {
-			doc.ExpiresAt = &expiresAt.Time
-		}
-		docs = append(docs, doc)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating documents: %w", err)
-	}
-
-	return docs, nil
-}
-
-// UpdateTitle updates only the title of a document owned by a user
-func (r *Repository) UpdateTitle(ctx context.Context, id, userID uuid.UUID, title string) error {
-	query := `UPDATE documents SET title = $2 WHERE id = $1 AND user_id = $3`
-
-	result, err := r.db.ExecContext(ctx, query, id, title, userID)
+	result, err := r.db.ExecContext(ctx, query, id, title, userID, expected)
 	if err != nil {
 		return fmt.Errorf("failed to update title: %w", err)
 	}
@@ -307,7 +407,7 @@ func (r *Repository) UpdateTitle(ctx context.Context, id, userID uuid.UUID, titl
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 	if rows == 0 {
-		return fmt.Errorf("document not found or not owned by user")
+		return r.versionConflict(ctx, id, userID)
 	}
 
 	return nil
@@ -333,21 +433,66 @@ func (r *Repository) GetContent(ctx context.Context, id uuid.UUID) (string, bool
 	return content.String, true, nil
 }
 
-// UpdateContent updates the content of a document owned by a user
-func (r *Repository) UpdateContent(ctx context.Context, id, userID uuid.UUID, content string) error {
-	query := `UPDATE documents SET content = $2 WHERE id = $1 AND user_id = $3`
-
-	result, err := r.db.ExecContext(ctx, query, id, content, userID)
+// UpdateContent updates the content of a document owned by a user, along with
+// the language it's indexed for search in and its content hash, and its title
+// unless that's empty, bumps its version and queues it for processing. The
+// text being replaced is kept as a revision in the same transaction, so an
+// edit that fails leaves no revision behind. Documents still being processed
+// return ErrDocumentProcessing. With expected set, it returns
+// ErrVersionMismatch unless the document is at that version.
+func (r *Repository) UpdateContent(ctx context.Context, id, userID uuid.UUID, title, content, language string, expected *int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the document so concurrent edits can't both go ahead from the same version
+	var currentTitle string
+	var currentContent sql.NullString
+	var status DocumentStatus
+	var tokenCount int
+	var version int64
+	lock := `
+		SELECT title, content, status, token_count, version FROM documents
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	err = tx.QueryRowContext(ctx, lock, id, userID).Scan(&currentTitle, &currentContent, &status, &tokenCount, &version)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("document not found or not owned by user")
+		}
+		return fmt.Errorf("failed to lock document: %w", err)
+	}
+	if isProcessing(status) {
+		return ErrDocumentProcessing
+	}
+	if expected != nil && *expected != version {
+		return ErrVersionMismatch
+	}
+
+	if needsRevision(currentContent.Valid, currentTitle, currentContent.String, title, content) {
+		if _, err := insertRevision(ctx, tx, id, currentTitle, currentContent.String, tokenCount, maxRevisionsPerDocument); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE documents SET content = $2, language = NULLIF($3, ''), search_config = $4::regconfig, content_hash = NULLIF($5, ''),
+			title = COALESCE(NULLIF($6, ''), title), headings = NULL, status = 'pending', version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND version = $7
+	`
+	if _, err := tx.ExecContext(ctx, query, id, content, language, searchConfig(language), ContentHash(content), title, version); err != nil {
 		return fmt.Errorf("failed to update content: %w", err)
 	}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+	if _, err := tx.ExecContext(ctx, enqueueJobQuery, id, userID, maxJobAttempts); err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
 	}
-	if rows == 0 {
-		return fmt.Errorf("document not found or not owned by user")
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit content update: %w", err)
 	}
 
 	return nil
@@ -356,7 +501,7 @@ func (r *Repository) UpdateContent(ctx context.Context, id, userID uuid.UUID, co
 // TransferOwnership transfers all documents from one user to another (for guest merge)
 func (r *Repository) TransferOwnership(ctx context.Context, fromUserID, toUserID uuid.UUID) error {
 	// Transfer documents
-	docQuery := `UPDATE documents SET user_id = $2, expires_at = NULL WHERE user_id = $1`
+	docQuery := `UPDATE documents SET user_id = $2, expires_at = NULL, updated_at = NOW() WHERE user_id = $1`
 	_, err := r.db.ExecContext(ctx, docQuery, fromUserID, toUserID)
 	if err != nil {
 		return fmt.Errorf("failed to transfer documents: %w", err)
@@ -369,15 +514,39 @@ func (r *Repository) TransferOwnership(ctx context.Context, fromUserID, toUserID
 		return fmt.Errorf("failed to transfer reading states: %w", err)
 	}
 
+	// Transfer highlights
+	hlQuery := `UPDATE highlights SET user_id = $2 WHERE user_id = $1`
+	_, err = r.db.ExecContext(ctx, hlQuery, fromUserID, toUserID)
+	if err != nil {
+		return fmt.Errorf("failed to transfer highlights: %w", err)
+	}
+
+	// Transfer the reading queue
+	queueQuery := `UPDATE reading_queue SET user_id = $2 WHERE user_id = $1`
+	_, err = r.db.ExecContext(ctx, queueQuery, fromUserID, toUserID)
+	if err != nil {
+		return fmt.Errorf("failed to transfer reading queue: %w", err)
+	}
+
+	// Transfer reading history
+	sessionQuery := `UPDATE reading_sessions SET user_id = $2 WHERE user_id = $1`
+	_, err = r.db.ExecContext(ctx, sessionQuery, fromUserID, toUserID)
+	if err != nil {
+		return fmt.Errorf("failed to transfer reading sessions: %w", err)
+	}
+
 	return nil
 }
 
 // DeleteExpiredGuestDocuments deletes documents that have expired
 func (r *Repository) DeleteExpiredGuestDocuments(ctx context.Context) ([]uuid.UUID, error) {
 	query := `
-		DELETE FROM documents
-		WHERE expires_at < NOW()
-		RETURNING id
+		WITH deleted AS (
+			DELETE FROM documents
+			WHERE expires_at < NOW()
+			RETURNING id, user_id
+		), ` + tombstoneDeleted + `
+		SELECT id FROM deleted
 	`
 
 	rows, err := r.db.QueryContext(ctx, query)
@@ -404,7 +573,7 @@ func (r *Repository) DeleteExpiredGuestDocuments(ctx context.Context) ([]uuid.UU
 
 // IsOwner checks if a user owns a document
 func (r *Repository) IsOwner(ctx context.Context, docID, userID uuid.UUID) (bool, error) {
-	query := `SELECT 1 FROM documents WHERE id = $1 AND user_id = $2`
+	query := `SELECT 1 FROM documents WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
 	var exists int
 	err := r.db.QueryRowContext(ctx, query, docID, userID).Scan(&exists)
 	if err == sql.ErrNoRows {
@@ -0,0 +1,166 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+)
+
+// maxRevisionsPerDocument caps how many past versions are retained per document;
+// the oldest are pruned as new ones are recorded
+const maxRevisionsPerDocument = 25
+
+// ErrRevisionNotFound indicates the revision doesn't exist or belongs to a
+// document the user doesn't own
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Revision is an immutable snapshot of a document's content before an edit.
+// Content is only populated when a single revision is fetched.
+type Revision struct {
+	ID         uuid.UUID `json:"id"`
+	DocID      uuid.UUID `json:"docId"`
+	Revision   int       `json:"revision"`
+	Title      string    `json:"title"`
+	TokenCount int       `json:"tokenCount"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Content    string    `json:"content,omitempty"`
+}
+
+// needsRevision reports whether an edit replaces anything worth keeping: a
+// document's stored content, when the edit changes it or sets a new title.
+// Documents created before content was stored have nothing to keep.
+func needsRevision(hasContent bool, currentTitle, currentContent, title, content string) bool {
+	return hasContent && (content != currentContent || (title != "" && title != currentTitle))
+}
+
+// pruneCutoff returns the highest revision number to delete once revision
+// latest has been recorded, so that keep revisions remain
+func pruneCutoff(latest, keep int) int {
+	return latest - keep
+}
+
+// insertRevision records a snapshot and prunes revisions beyond keep. The
+// document row must already be locked by tx, so concurrent edits can't pick
+// the same revision number.
+func insertRevision(ctx context.Context, tx *sql.Tx, docID uuid.UUID, title, content string, tokenCount, keep int) (*Revision, error) {
+	rev := &Revision{DocID: docID, Title: title, TokenCount: tokenCount}
+	insert := `
+		INSERT INTO document_revisions (doc_id, revision, title, content, token_count, created_at)
+		SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, NOW()
+		FROM document_revisions
+		WHERE doc_id = $1
+		RETURNING id, revision, created_at
+	`
+	if err := tx.QueryRowContext(ctx, insert, docID, title, content, tokenCount).Scan(&rev.ID, &rev.Revision, &rev.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to insert revision: %w", err)
+	}
+
+	prune := `DELETE FROM document_revisions WHERE doc_id = $1 AND revision <= $2`
+	if _, err := tx.ExecContext(ctx, prune, docID, pruneCutoff(rev.Revision, keep)); err != nil {
+		return nil, fmt.Errorf("failed to prune revisions: %w", err)
+	}
+
+	return rev, nil
+}
+
+// ListRevisions returns a document's revisions, newest first, without content
+func (r *Repository) ListRevisions(ctx context.Context, docID uuid.UUID) ([]Revision, error) {
+	query := `
+		SELECT id, doc_id, revision, title, token_count, created_at
+		FROM document_revisions
+		WHERE doc_id = $1
+		ORDER BY revision DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []Revision{}
+	for rows.Next() {
+		var rev Revision
+		if err := rows.Scan(&rev.ID, &rev.DocID, &rev.Revision, &rev.Title, &rev.TokenCount, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetRevision returns a single revision of a document, including its content
+func (r *Repository) GetRevision(ctx context.Context, docID, revisionID uuid.UUID) (*Revision, error) {
+	query := `
+		SELECT id, doc_id, revision, title, content, token_count, created_at
+		FROM document_revisions
+		WHERE id = $1 AND doc_id = $2
+	`
+
+	rev := &Revision{}
+	err := r.db.QueryRowContext(ctx, query, revisionID, docID).Scan(
+		&rev.ID, &rev.DocID, &rev.Revision, &rev.Title, &rev.Content, &rev.TokenCount, &rev.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	return rev, nil
+}
+
+// ListRevisions returns the revision history of a document the user owns
+func (s *Service) ListRevisions(ctx context.Context, docID uuid.UUID) ([]Revision, error) {
+	if err := s.checkRevisionAccess(ctx, docID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRevisions(ctx, docID)
+}
+
+// GetRevision returns a past version of a document the user owns
+func (s *Service) GetRevision(ctx context.Context, docID, revisionID uuid.UUID) (*Revision, error) {
+	if err := s.checkRevisionAccess(ctx, docID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetRevision(ctx, docID, revisionID)
+}
+
+// RestoreRevision makes a past version current again. The restore goes
+// through UpdateDocumentContent, so the version it replaces is itself kept
+// as a revision and the restore can be undone.
+func (s *Service) RestoreRevision(ctx context.Context, docID, revisionID uuid.UUID) (*Document, error) {
+	rev, err := s.GetRevision(ctx, docID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.UpdateDocumentContent(ctx, docID, rev.Title, rev.Content, nil)
+}
+
+// checkRevisionAccess limits history to the document owner; public readers
+// can see the current text but not past edits
+func (s *Service) checkRevisionAccess(ctx context.Context, docID uuid.UUID) error {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("user not found in context")
+	}
+
+	owner, err := s.repo.IsOwner(ctx, docID, user.ID)
+	if err != nil {
+		return err
+	}
+	if !owner {
+		return ErrRevisionNotFound
+	}
+	return nil
+}
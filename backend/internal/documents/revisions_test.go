@@ -0,0 +1,177 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNeedsRevision(t *testing.T) {
+	tests := []struct {
+		name                         string
+		hasContent                   bool
+		currentTitle, currentContent string
+		title, content               string
+		want                         bool
+	}{
+		{"content changed", true, "Notes", "old text", "", "new text", true},
+		{"title changed", true, "Notes", "same text", "Renamed", "same text", true},
+		{"title kept when empty", true, "Notes", "same text", "", "same text", false},
+		{"nothing changed", true, "Notes", "same text", "Notes", "same text", false},
+		{"restore of an older version", true, "Notes v2", "second draft", "Notes v1", "first draft", true},
+		{"no stored content", false, "Notes", "", "", "new text", false},
+	}
+
+	for _, tt := range tests {
+		got := needsRevision(tt.hasContent, tt.currentTitle, tt.currentContent, tt.title, tt.content)
+		if got != tt.want {
+			t.Errorf("%s: needsRevision = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPruneCutoff(t *testing.T) {
+	tests := []struct {
+		latest, keep, want int
+	}{
+		{1, maxRevisionsPerDocument, 1 - maxRevisionsPerDocument}, // nothing to prune yet
+		{maxRevisionsPerDocument, maxRevisionsPerDocument, 0},
+		{maxRevisionsPerDocument + 1, maxRevisionsPerDocument, 1},
+		{30, 25, 5},
+	}
+
+	for _, tt := range tests {
+		if got := pruneCutoff(tt.latest, tt.keep); got != tt.want {
+			t.Errorf("pruneCutoff(%d, %d) = %d, want %d", tt.latest, tt.keep, got, tt.want)
+		}
+		// Revisions after the cutoff up to latest are the ones kept
+		if kept := tt.latest - max(pruneCutoff(tt.latest, tt.keep), 0); kept > tt.keep {
+			t.Errorf("pruneCutoff(%d, %d) keeps %d revisions, want at most %d", tt.latest, tt.keep, kept, tt.keep)
+		}
+	}
+}
+
+func TestRevisions_CapDropsOldest(t *testing.T) {
+	svc, db := testService(t)
+	ctx := testUser(t, db)
+	id := testDocument(t, svc, ctx, "edit 0")
+
+	edits := maxRevisionsPerDocument + 5
+	for i := 1; i <= edits; i++ {
+		if _, err := svc.UpdateDocumentContent(ctx, id, "", fmt.Sprintf("edit %d", i), nil); err != nil {
+			t.Fatalf("edit %d: %v", i, err)
+		}
+		testProcess(t, svc, id)
+	}
+
+	revisions, err := svc.ListRevisions(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to list revisions: %v", err)
+	}
+	if len(revisions) != maxRevisionsPerDocument {
+		t.Fatalf("expected %d revisions kept, got %d", maxRevisionsPerDocument, len(revisions))
+	}
+	if newest := revisions[0].Revision; newest != edits {
+		t.Errorf("expected newest revision %d, got %d", edits, newest)
+	}
+	if oldest := revisions[len(revisions)-1].Revision; oldest != edits-maxRevisionsPerDocument+1 {
+		t.Errorf("expected oldest revision %d, got %d", edits-maxRevisionsPerDocument+1, oldest)
+	}
+}
+
+func TestRevisions_FailedEditRecordsNothing(t *testing.T) {
+	svc, db := testService(t)
+	ctx := testUser(t, db)
+	id := testDocument(t, svc, ctx, "first draft")
+
+	stale := int64(-1)
+	if _, err := svc.UpdateDocumentContent(ctx, id, "", "second draft", &stale); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("expected ErrVersionMismatch, got %v", err)
+	}
+
+	revisions, err := svc.ListRevisions(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to list revisions: %v", err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("expected a rejected edit to record no revision, got %d", len(revisions))
+	}
+}
+
+func TestRestoreRevision_KeepsReplacedVersion(t *testing.T) {
+	svc, db := testService(t)
+	ctx := testUser(t, db)
+	id := testDocument(t, svc, ctx, "first draft")
+
+	if _, err := svc.UpdateDocumentContent(ctx, id, "", "second draft", nil); err != nil {
+		t.Fatalf("failed to edit: %v", err)
+	}
+	testProcess(t, svc, id)
+	revisions, err := svc.ListRevisions(ctx, id)
+	if err != nil || len(revisions) != 1 {
+		t.Fatalf("expected one revision after an edit, got %d (%v)", len(revisions), err)
+	}
+
+	if _, err := svc.RestoreRevision(ctx, id, revisions[0].ID); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+
+	content, err := svc.GetDocumentContent(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get content: %v", err)
+	}
+	if content.Content != "first draft" {
+		t.Errorf("expected restored content %q, got %q", "first draft", content.Content)
+	}
+
+	revisions, err = svc.ListRevisions(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to list revisions: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("expected the restore to record a revision, got %d revisions", len(revisions))
+	}
+	replaced, err := svc.GetRevision(ctx, id, revisions[0].ID)
+	if err != nil {
+		t.Fatalf("failed to get revision: %v", err)
+	}
+	if replaced.Content != "second draft" {
+		t.Errorf("expected the restore to keep %q, got %q", "second draft", replaced.Content)
+	}
+}
+
+func TestCheckRevisionAccess_OwnerOnly(t *testing.T) {
+	svc, db := testService(t)
+	owner := testUser(t, db)
+	other := testUser(t, db)
+	id := testDocument(t, svc, owner, "first draft")
+
+	if _, err := svc.UpdateDocumentContent(owner, id, "", "second draft", nil); err != nil {
+		t.Fatalf("failed to edit: %v", err)
+	}
+	testProcess(t, svc, id)
+	revisions, err := svc.ListRevisions(owner, id)
+	if err != nil || len(revisions) != 1 {
+		t.Fatalf("expected the owner to see one revision, got %d (%v)", len(revisions), err)
+	}
+
+	if err := svc.checkRevisionAccess(owner, id); err != nil {
+		t.Errorf("expected the owner to have access, got %v", err)
+	}
+	if err := svc.checkRevisionAccess(other, id); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("expected ErrRevisionNotFound for another user, got %v", err)
+	}
+	if _, err := svc.ListRevisions(other, id); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("expected another user's list to fail with ErrRevisionNotFound, got %v", err)
+	}
+	if _, err := svc.GetRevision(other, id, revisions[0].ID); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("expected another user's get to fail with ErrRevisionNotFound, got %v", err)
+	}
+	if _, err := svc.RestoreRevision(other, id, revisions[0].ID); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("expected another user's restore to fail with ErrRevisionNotFound, got %v", err)
+	}
+	if err := svc.checkRevisionAccess(context.Background(), id); err == nil {
+		t.Error("expected an error without a user in context")
+	}
+}
@@ -0,0 +1,253 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// DefaultSearchLimit is how many results are returned when no limit is given
+	DefaultSearchLimit = 20
+
+	// MaxSearchLimit caps the results of a single search
+	MaxSearchLimit = 50
+
+	// MaxSearchQueryLength bounds the query text accepted from clients
+	MaxSearchQueryLength = 200
+)
+
+// Snippet highlight markers. ts_headline wraps matches in these control
+// characters so the surrounding text can be HTML-escaped before the markers
+// are swapped for <mark> tags.
+const (
+	snippetStart = "\x02"
+	snippetStop  = "\x03"
+)
+
+// headlineOptions configures ts_headline to return up to two short fragments
+var headlineOptions = "StartSel=" + snippetStart + ", StopSel=" + snippetStop +
+	`, MaxWords=24, MinWords=12, MaxFragments=2, FragmentDelimiter=" … "`
+
+// searchConfigs maps detected languages to Postgres text search configurations.
+// Documents in any other language are indexed with the "simple" configuration,
+// which lowercases words without stemming them.
+var searchConfigs = map[string]string{
+	"en": "english",
+	"fr": "french",
+	"de": "german",
+	"es": "spanish",
+	"it": "italian",
+	"pt": "portuguese",
+	"nl": "dutch",
+	"sv": "swedish",
+	"ru": "russian",
+}
+
+// searchConfig returns the text search configuration for a language code
+func searchConfig(language string) string {
+	if config, ok := searchConfigs[language]; ok {
+		return config
+	}
+	return "simple"
+}
+
+// searchMatchClause matches a document against the query parsed with its own
+// configuration. It is spelled out per configuration, rather than passing
+// d.search_config to websearch_to_tsquery, so every branch compares the
+// search vector with a constant query and can use the GIN index.
+var searchMatchClause = func() string {
+	configs := []string{"simple"}
+	for _, config := range searchConfigs {
+		configs = append(configs, config)
+	}
+	sort.Strings(configs)
+
+	clauses := make([]string, len(configs))
+	for i, config := range configs {
+		clauses[i] = fmt.Sprintf("(d.search_config = '%[1]s'::regconfig AND d.search_vector @@ websearch_to_tsquery('%[1]s', $2))", config)
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")"
+}()
+
+// SearchResult is a document matching a library search. Snippet is
+// HTML-escaped text with matches wrapped in <mark> tags. FirstHitIndex is the
+// token index of the first match in the body, absent when only the title matched.
+type SearchResult struct {
+	Document
+	Rank          float64 `json:"rank"`
+	Snippet       string  `json:"snippet"`
+	FirstHitIndex *int    `json:"firstHitIndex,omitempty"`
+
+	matchedTerms []string // surface forms of the words highlighted in the snippet
+}
+
+// Search runs a full-text query over a user's documents, best matches first.
+// The query accepts web search syntax: quoted phrases, OR, and -exclusions.
+func (r *Repository) Search(ctx context.Context, userID uuid.UUID, query string, limit int) ([]SearchResult, error) {
+	sqlQuery := `
+		SELECT id, user_id, title, status, token_count, chunk_count, visibility, share_token, expires_at, created_at, has_content,
+			   source_type, author, source_url, language, rank,
+			   ts_headline(search_config, LEFT(COALESCE(content, ''), 262144), websearch_to_tsquery(search_config, $2), $4)
+		FROM (
+			SELECT d.id, d.user_id, d.title, d.status, d.token_count, d.chunk_count, d.visibility, d.share_token, d.expires_at, d.created_at,
+				   d.content IS NOT NULL AS has_content, d.source_type, COALESCE(d.author, '') AS author,
+				   COALESCE(d.source_url, '') AS source_url, COALESCE(d.language, '') AS language,
+				   d.search_config, d.content,
+				   ts_rank_cd(d.search_vector, websearch_to_tsquery(d.search_config, $2)) AS rank
+			FROM documents d
+			WHERE d.user_id = $1 AND d.deleted_at IS NULL AND ` + searchMatchClause + `
+			ORDER BY rank DESC, d.created_at DESC
+			LIMIT $3
+		) d
+		ORDER BY rank DESC, created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, userID, query, limit, headlineOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var result SearchResult
+		var docUserID, shareToken sql.NullString
+		var expiresAt sql.NullTime
+		var headline string
+		err := rows.Scan(
+			&result.ID, &docUserID, &result.Title, &result.Status, &result.TokenCount, &result.ChunkCount, &result.Visibility, &shareToken, &expiresAt, &result.CreatedAt, &result.HasContent,
+			&result.SourceType, &result.Author, &result.SourceURL, &result.Language, &result.Rank, &headline,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		if docUserID.Valid {
+			uid, _ := uuid.Parse(docUserID.String)
+			result.UserID = &uid
+		}
+		if shareToken.Valid {
+			st, _ := uuid.Parse(shareToken.String)
+			result.ShareToken = &st
+		}
+		if expiresAt.Valid {
+			result.ExpiresAt = &expiresAt.Time
+		}
+		result.Snippet, result.matchedTerms = renderSnippet(headline)
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	return results, nil
+}
+
+// SearchDocuments searches the current user's library and locates the first
+// hit in each matching document so the reader can open it there
+func (s *Service) SearchDocuments(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("user not found in context")
+	}
+
+	results, err := s.repo.Search(ctx, user.ID, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		if results[i].Status != StatusReady || len(results[i].matchedTerms) == 0 {
+			continue
+		}
+		if index, ok := s.firstOccurrence(&results[i].Document, results[i].matchedTerms); ok {
+			results[i].FirstHitIndex = &index
+		}
+	}
+
+	return results, nil
+}
+
+// firstOccurrence scans a document's chunks for the first token equal to any
+// of terms, ignoring case, diacritics and surrounding punctuation
+func (s *Service) firstOccurrence(doc *Document, terms []string) (int, bool) {
+	wanted := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		if folded := foldWord(term); folded != "" {
+			wanted[folded] = true
+		}
+	}
+	if len(wanted) == 0 {
+		return 0, false
+	}
+
+	offset := 0
+	for i := 0; i < doc.ChunkCount; i++ {
+		chunk, err := s.chunkStore.ReadChunk(doc.ID, i)
+		if err != nil {
+			return 0, false
+		}
+		for j, token := range chunk.Tokens {
+			if wanted[foldWord(token.Text)] {
+				return offset + j, true
+			}
+		}
+		offset += len(chunk.Tokens)
+	}
+	return 0, false
+}
+
+// renderSnippet converts a ts_headline result into escaped HTML with <mark>
+// tags, returning the highlighted words as well
+func renderSnippet(headline string) (string, []string) {
+	var b strings.Builder
+	var terms []string
+
+	for {
+		start := strings.Index(headline, snippetStart)
+		if start < 0 {
+			break
+		}
+		stop := strings.Index(headline[start:], snippetStop)
+		if stop < 0 {
+			break
+		}
+		stop += start
+
+		term := headline[start+len(snippetStart) : stop]
+		b.WriteString(html.EscapeString(headline[:start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(term))
+		b.WriteString("</mark>")
+		terms = append(terms, term)
+
+		headline = headline[stop+len(snippetStop):]
+	}
+	b.WriteString(html.EscapeString(headline))
+
+	return b.String(), terms
+}
+
+// foldWord reduces a word to a comparison key: lowercase, without diacritics
+// and without leading or trailing punctuation
+func foldWord(word string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(word) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return strings.TrimFunc(b.String(), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,57 @@
+package documents
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderSnippet(t *testing.T) {
+	headline := "the " + snippetStart + "quick" + snippetStop + " <b>brown</b> " + snippetStart + "fox" + snippetStop + " & co"
+
+	snippet, terms := renderSnippet(headline)
+
+	want := "the <mark>quick</mark> &lt;b&gt;brown&lt;/b&gt; <mark>fox</mark> &amp; co"
+	if snippet != want {
+		t.Errorf("snippet = %q, want %q", snippet, want)
+	}
+	if !reflect.DeepEqual(terms, []string{"quick", "fox"}) {
+		t.Errorf("terms = %v", terms)
+	}
+}
+
+func TestRenderSnippet_UnterminatedMarker(t *testing.T) {
+	snippet, terms := renderSnippet("a " + snippetStart + "b")
+	if strings.Contains(snippet, "<mark>") || len(terms) != 0 {
+		t.Errorf("expected no highlight for unterminated marker, got %q %v", snippet, terms)
+	}
+}
+
+func TestFoldWord(t *testing.T) {
+	tests := map[string]string{
+		"Café":     "cafe",
+		"\"Hello,": "hello",
+		"naïve.":   "naive",
+		"ÉCOLE":    "ecole",
+		"don't":    "don't",
+		"—":        "",
+		"2024!":    "2024",
+	}
+	for in, want := range tests {
+		if got := foldWord(in); got != want {
+			t.Errorf("foldWord(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSearchConfig(t *testing.T) {
+	if got := searchConfig("fr"); got != "french" {
+		t.Errorf("searchConfig(fr) = %q", got)
+	}
+	if got := searchConfig(""); got != "simple" {
+		t.Errorf("searchConfig(\"\") = %q", got)
+	}
+	if !strings.Contains(searchMatchClause, "'simple'::regconfig") || !strings.Contains(searchMatchClause, "'russian'::regconfig") {
+		t.Errorf("match clause missing configurations: %s", searchMatchClause)
+	}
+}
@@ -2,6 +2,7 @@ package documents
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -9,15 +10,20 @@ import (
 	"github.com/google/uuid"
 	"github.com/mikepersonal/speed-reader/backend/internal/auth"
 	"github.com/mikepersonal/speed-reader/backend/internal/config"
+	"github.com/mikepersonal/speed-reader/backend/internal/language"
 	"github.com/mikepersonal/speed-reader/backend/internal/storage"
 	"github.com/mikepersonal/speed-reader/backend/internal/tokenizer"
 )
 
+// maxJobAttempts is how many times a document is processed before giving up
+const maxJobAttempts = 5
+
 // Service orchestrates document operations
 type Service struct {
 	repo            *Repository
 	chunkStore      *storage.ChunkStore
 	guestDocTTLDays int
+	jobWake         chan struct{} // signals workers that a job was enqueued
 }
 
 // NewService creates a new document service
@@ -26,19 +32,70 @@ func NewService(repo *Repository, chunkStore *storage.ChunkStore, guestDocTTLDay
 		repo:            repo,
 		chunkStore:      chunkStore,
 		guestDocTTLDays: guestDocTTLDays,
+		jobWake:         make(chan struct{}, 1),
 	}
 }
 
-// CreateDocument creates a new document and processes its content
-func (s *Service) CreateDocument(ctx context.Context, title, content string) (*Document, error) {
+// ProcessingStatus describes where a document is in the processing pipeline
+type ProcessingStatus struct {
+	DocID         uuid.UUID      `json:"docId"`
+	Status        DocumentStatus `json:"status"`
+	TokenCount    int            `json:"tokenCount"`
+	ChunkCount    int            `json:"chunkCount"`
+	Attempts      int            `json:"attempts"`
+	Error         string         `json:"error,omitempty"`
+	NextAttemptAt *time.Time     `json:"nextAttemptAt,omitempty"`
+}
+
+// CreateDocumentInput describes the text and provenance of a new document
+type CreateDocumentInput struct {
+	Title      string
+	Content    string
+	SourceType SourceType // defaults to SourcePaste
+	Author     string
+	SourceURL  string
+	Headings   []Heading // section titles, for documents extracted from files or pages
+
+	// AllowDuplicate creates the document even if the user already has one
+	// with the same content
+	AllowDuplicate bool
+}
+
+// CreateDocument stores a new document and queues it for background processing.
+// The returned document is still pending; poll GetProcessingStatus until it is ready.
+// Unless the input allows duplicates, content matching one of the user's
+// documents returns a *DuplicateError naming it instead.
+func (s *Service) CreateDocument(ctx context.Context, input *CreateDocumentInput) (*Document, error) {
 	// Get user from context
 	user, ok := auth.UserFromContext(ctx)
 	if !ok {
 		return nil, fmt.Errorf("user not found in context")
 	}
 
+	if !input.AllowDuplicate {
+		existing, err := s.findDuplicate(ctx, user.ID, input.Content)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, &DuplicateError{Existing: existing}
+		}
+	}
+
+	doc, err := s.createDocument(ctx, user, input)
+	if err != nil {
+		return nil, err
+	}
+	s.wakeWorkers()
+
+	return doc, nil
+}
+
+// createDocument inserts a document for the user and queues its processing
+// job. Callers check for duplicates first and wake the workers after.
+func (s *Service) createDocument(ctx context.Context, user *auth.User, input *CreateDocumentInput) (*Document, error) {
 	// Generate random title if not provided
-	title = strings.TrimSpace(title)
+	title := strings.TrimSpace(input.Title)
 	if title == "" {
 		title = GenerateRandomTitle()
 	}
@@ -50,54 +107,192 @@ func (s *Service) CreateDocument(ctx context.Context, title, content string) (*D
 		expiresAt = &t
 	}
 
-	// Create document record (store content for future editing)
+	// Create document record (content is tokenized later by a worker)
 	doc, err := s.repo.Create(ctx, &CreateParams{
-		Title:     title,
-		Content:   content,
-		UserID:    user.ID,
-		ExpiresAt: expiresAt,
+		Title:      title,
+		Content:    input.Content,
+		UserID:     user.ID,
+		ExpiresAt:  expiresAt,
+		SourceType: input.SourceType,
+		Author:     strings.TrimSpace(input.Author),
+		SourceURL:  input.SourceURL,
+		Language:   language.Detect(input.Content),
+		Headings:   input.Headings,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create document: %w", err)
 	}
 
-	// Update status to processing
-	if err := s.repo.UpdateStatus(ctx, doc.ID, StatusProcessing, 0, 0); err != nil {
+	if err := s.repo.EnqueueJob(ctx, doc.ID, user.ID, maxJobAttempts); err != nil {
+		_ = s.repo.UpdateStatus(ctx, doc.ID, StatusError, 0, 0)
+		return nil, fmt.Errorf("failed to queue document: %w", err)
+	}
+
+	return doc, nil
+}
+
+// ProcessDocument tokenizes a document's stored content and writes its chunks.
+// It is run by background workers and is safe to retry. Until it finishes,
+// readers are served the chunks and counts from before an edit. If the
+// document is edited while it runs, its work is discarded with
+// ErrContentChanged and the job queued by the edit processes the new content.
+func (s *Service) ProcessDocument(ctx context.Context, id uuid.UUID) (*Document, error) {
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// A ready document was marked so by an attempt that stopped before its
+	// job completed; all that may be left is swapping its chunks in
+	if doc.Status == StatusReady {
+		if err := s.chunkStore.PromoteStaged(id); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	}
+	// The version is read before the content, so content edited in between
+	// is newer than the version and fails the check when the document is
+	// marked ready
+	version := doc.Version
+
+	content, hasContent, err := s.repo.GetContent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !hasContent {
+		return nil, fmt.Errorf("document has no content to process")
+	}
+
+	// A document with tokens was processed before, and this is an edit.
+	// Capture the words its chunks hold so readers keep their place. The
+	// counts and chunks stay until the new ones replace them, so a retried
+	// attempt still finds them.
+	edited := doc.TokenCount > 0
+	var oldWords []string
+	if edited {
+		oldWords = s.processedWords(doc)
+	}
+
+	if err := s.repo.SetStatus(ctx, id, StatusProcessing); err != nil {
 		return nil, fmt.Errorf("failed to update status: %w", err)
 	}
 
-	// Tokenize content
+	// Remove chunks staged by an earlier failed attempt
+	if err := s.chunkStore.DiscardStaged(id); err != nil {
+		return nil, err
+	}
+
 	tokens := tokenizer.Tokenize(content)
-	tokenCount := len(tokens)
+	chunkCount, err := s.writeChunks(id, tokens)
+	if err != nil {
+		return nil, err
+	}
 
-	// Write tokens to chunks
-	chunkCount := 0
-	for i := 0; i < tokenCount; i += config.ChunkSize {
-		end := i + config.ChunkSize
-		if end > tokenCount {
-			end = tokenCount
-		}
+	analytics := Analyze(tokens, doc.Language)
+	if err := s.repo.SetAnalytics(ctx, id, analytics); err != nil {
+		return nil, err
+	}
+
+	// Imported documents locate their chapters from the headings recorded at
+	// import; an edit clears those, and the chapters follow the edit instead
+	headings, err := s.repo.GetHeadings(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var remap *tokenRemap
+	if edited {
+		remap = newTokenRemap(oldWords, tokenWords(tokens))
+	}
+	var chapters []Chapter
+	switch {
+	case len(headings) > 0:
+		chapters = locateChapters(content, headings)
+	case edited:
+		chapters = remapChapters(doc.Chapters, remap)
+	}
 
-		if err := s.chunkStore.WriteChunk(doc.ID, chunkCount, tokens[i:end]); err != nil {
-			// Update status to error
-			_ = s.repo.UpdateStatus(ctx, doc.ID, StatusError, 0, 0)
-			return nil, fmt.Errorf("failed to write chunk %d: %w", chunkCount, err)
+	if err := s.repo.FinishProcessing(ctx, id, version, len(tokens), chunkCount, chapters); err != nil {
+		if errors.Is(err, ErrContentChanged) {
+			_ = s.chunkStore.DiscardStaged(id)
 		}
-		chunkCount++
+		return nil, err
 	}
 
-	// Update document with final status and counts
-	if err := s.repo.UpdateStatus(ctx, doc.ID, StatusReady, tokenCount, chunkCount); err != nil {
-		return nil, fmt.Errorf("failed to update document status: %w", err)
+	if edited {
+		// Keep every reader at the same place in the text. Positions only fall
+		// back to the start when the words they pointed at were deleted.
+		// Non-fatal error: a failed remap shouldn't fail processing
+		_ = s.remapAnchors(ctx, id, remap)
+	}
+
+	if err := s.chunkStore.PromoteStaged(id); err != nil {
+		return nil, err
 	}
 
 	doc.Status = StatusReady
-	doc.TokenCount = tokenCount
+	doc.TokenCount = len(tokens)
 	doc.ChunkCount = chunkCount
+	doc.Analytics = analytics
+	doc.Chapters = chapters
 
 	return doc, nil
 }
 
+// GetProcessingStatus reports the processing state of a document and its job
+func (s *Service) GetProcessingStatus(ctx context.Context, id uuid.UUID) (*ProcessingStatus, error) {
+	doc, err := s.GetDocument(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ProcessingStatus{
+		DocID:      doc.ID,
+		Status:     doc.Status,
+		TokenCount: doc.TokenCount,
+		ChunkCount: doc.ChunkCount,
+	}
+
+	job, err := s.repo.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job != nil {
+		status.Attempts = job.Attempts
+		status.Error = job.LastError
+		if job.Status == JobPending && job.Attempts > 0 {
+			status.NextAttemptAt = &job.RunAfter
+		}
+	}
+
+	return status, nil
+}
+
+// writeChunks splits tokens into staged chunk files and returns the number of
+// chunks written
+func (s *Service) writeChunks(id uuid.UUID, tokens []storage.Token) (int, error) {
+	chunkCount := 0
+	for i := 0; i < len(tokens); i += config.ChunkSize {
+		end := i + config.ChunkSize
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+
+		if err := s.chunkStore.WriteStagedChunk(id, chunkCount, tokens[i:end]); err != nil {
+			return chunkCount, fmt.Errorf("failed to write chunk %d: %w", chunkCount, err)
+		}
+		chunkCount++
+	}
+
+	return chunkCount, nil
+}
+
+// wakeWorkers nudges an idle worker without blocking if one is already signalled
+func (s *Service) wakeWorkers() {
+	select {
+	case s.jobWake <- struct{}{}:
+	default:
+	}
+}
+
 // GetDocument retrieves a document by ID with access control
 func (s *Service) GetDocument(ctx context.Context, id uuid.UUID) (*Document, error) {
 	doc, err := s.repo.GetByID(ctx, id)
@@ -143,147 +338,134 @@ func (s *Service) GetReadingState(ctx context.Context, docID uuid.UUID) (*Readin
 	return s.repo.GetReadingState(ctx, user.ID, docID)
 }
 
-// UpdateReadingState updates reading state for a document
-func (s *Service) UpdateReadingState(ctx context.Context, state *ReadingState) error {
+// UpdateReadingState updates reading state for a document and records the
+// progress in the reader's session history. If cond finds the write stale,
+// nothing is saved and it returns a *StaleReadingStateError carrying the
+// state already saved.
+func (s *Service) UpdateReadingState(ctx context.Context, state *ReadingState, client ClientType, cond WriteCondition) error {
 	user, ok := auth.UserFromContext(ctx)
 	if !ok {
 		return fmt.Errorf("user not found in context")
 	}
 
 	// Verify document exists and user has access
-	if _, err := s.GetDocument(ctx, state.DocID); err != nil {
+	doc, err := s.GetDocument(ctx, state.DocID)
+	if err != nil {
+		return err
+	}
+
+	previous, err := s.repo.GetReadingState(ctx, user.ID, state.DocID)
+	if err != nil {
 		return err
 	}
 
 	state.UserID = user.ID
-	return s.repo.UpsertReadingState(ctx, state)
+	cond.ClientTime = clampClientTime(cond.ClientTime, time.Now())
+	if err := s.repo.UpsertReadingState(ctx, state, cond); err != nil {
+		if errors.Is(err, ErrStaleReadingState) {
+			return s.staleReadingState(ctx, user.ID, state.DocID)
+		}
+		return err
+	}
+
+	// Non-fatal error: history is best effort and mustn't lose the position
+	_ = s.repo.RecordSessionProgress(ctx, user.ID, state.DocID, sessionProgress{
+		fromIndex: previous.TokenIndex,
+		toIndex:   state.TokenIndex,
+		wpm:       state.WPM,
+		client:    client,
+		at:        state.UpdatedAt,
+	})
+
+	// A finished document is done with; the queue moves on to the next.
+	// Non-fatal error: the position is saved either way
+	if isFinished(state.TokenIndex, doc.TokenCount) {
+		_ = s.repo.RemoveFromQueue(ctx, user.ID, state.DocID)
+	}
+	return nil
 }
 
-// DeleteDocument removes a document and its chunks
+// DeleteDocument moves a document to the trash. Its chunks are kept so it can
+// be restored; they're removed when the trash is emptied or purged.
 func (s *Service) DeleteDocument(ctx context.Context, id uuid.UUID) error {
 	user, ok := auth.UserFromContext(ctx)
 	if !ok {
 		return fmt.Errorf("user not found in context")
 	}
 
-	// Delete from database first (checks ownership)
-	if err := s.repo.Delete(ctx, id, user.ID); err != nil {
-		return err
-	}
-
-	// Delete chunk files
-	return s.chunkStore.DeleteDocument(id)
+	return s.repo.MoveToTrash(ctx, id, user.ID)
 }
 
-// ListDocuments retrieves all documents for the current user with their reading progress
-func (s *Service) ListDocuments(ctx context.Context) ([]DocumentWithProgress, error) {
+// ListDocuments retrieves a page of the current user's documents with their
+// reading progress, and the cursor for the next page if there is one
+func (s *Service) ListDocuments(ctx context.Context, opts ListOptions) ([]DocumentWithProgress, string, error) {
 	user, ok := auth.UserFromContext(ctx)
 	if !ok {
-		return nil, fmt.Errorf("user not found in context")
+		return nil, "", fmt.Errorf("user not found in context")
 	}
 
-	return s.repo.List(ctx, user.ID)
+	return s.repo.List(ctx, user.ID, opts)
 }
 
-// UpdateDocumentTitle updates the title of a document
-func (s *Service) UpdateDocumentTitle(ctx context.Context, id uuid.UUID, title string) error {
+// UpdateDocumentTitle updates the title of a document. Documents still being
+// processed return ErrDocumentProcessing. With expected set, it returns
+// ErrVersionMismatch unless the document is at that version.
+func (s *Service) UpdateDocumentTitle(ctx context.Context, id uuid.UUID, title string, expected *int64) error {
 	user, ok := auth.UserFromContext(ctx)
 	if !ok {
 		return fmt.Errorf("user not found in context")
 	}
 
-	return s.repo.UpdateTitle(ctx, id, user.ID, title)
+	return s.repo.UpdateTitle(ctx, id, user.ID, title, expected)
 }
 
 // GetDocumentContent retrieves the original content of a document for editing
-func (s *Service) GetDocumentContent(ctx context.Context, id uuid.UUID) (string, bool, error) {
-	// Verify document exists and user has access
-	if _, err := s.GetDocument(ctx, id); err != nil {
-		return "", false, err
+// and the version it belongs to
+func (s *Service) GetDocumentContent(ctx context.Context, id uuid.UUID) (*DocumentContent, error) {
+	// Verify document exists and user has access. The version is read first:
+	// if an edit lands in between, it's older than the content and a
+	// conditional edit fails rather than overwriting unseen changes.
+	doc, err := s.GetDocument(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.repo.GetContent(ctx, id)
+	content, hasContent, err := s.repo.GetContent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DocumentContent{Content: content, HasContent: hasContent, Version: doc.Version}, nil
 }
 
-// UpdateDocumentContent updates document content, re-tokenizes, and resets reading state
-func (s *Service) UpdateDocumentContent(ctx context.Context, id uuid.UUID, title, content string) (*Document, error) {
+// UpdateDocumentContent saves new content for a document and queues it to be
+// re-tokenized; readers' positions follow the edit once it's processed. The
+// returned document is pending; poll GetProcessingStatus until it is ready.
+// Documents still being processed return ErrDocumentProcessing. With expected
+// set, it returns ErrVersionMismatch unless the document is at that version.
+func (s *Service) UpdateDocumentContent(ctx context.Context, id uuid.UUID, title, content string, expected *int64) (*Document, error) {
 	user, ok := auth.UserFromContext(ctx)
 	if !ok {
 		return nil, fmt.Errorf("user not found in context")
 	}
 
-	// Verify document exists and user has access
+	// Verify document exists and user owns it
 	doc, err := s.GetDocument(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-
-	// Update title if provided
-	if title != "" && title != doc.Title {
-		if err := s.repo.UpdateTitle(ctx, id, user.ID, title); err != nil {
-			return nil, fmt.Errorf("failed to update title: %w", err)
-		}
-	}
-
-	// Update status to processing
-	if err := s.repo.UpdateStatus(ctx, id, StatusProcessing, doc.TokenCount, doc.ChunkCount); err != nil {
-		return nil, fmt.Errorf("failed to update status: %w", err)
-	}
-
-	// Delete old chunks
-	if err := s.chunkStore.DeleteDocument(id); err != nil {
-		_ = s.repo.UpdateStatus(ctx, id, StatusError, 0, 0)
-		return nil, fmt.Errorf("failed to delete old chunks: %w", err)
-	}
-
-	// Re-tokenize content
-	tokens := tokenizer.Tokenize(content)
-	tokenCount := len(tokens)
-
-	// Write new chunks
-	chunkCount := 0
-	for i := 0; i < tokenCount; i += config.ChunkSize {
-		end := i + config.ChunkSize
-		if end > tokenCount {
-			end = tokenCount
-		}
-
-		if err := s.chunkStore.WriteChunk(id, chunkCount, tokens[i:end]); err != nil {
-			_ = s.repo.UpdateStatus(ctx, id, StatusError, 0, 0)
-			return nil, fmt.Errorf("failed to write chunk %d: %w", chunkCount, err)
-		}
-		chunkCount++
+	if doc.UserID == nil || *doc.UserID != user.ID {
+		return nil, fmt.Errorf("document not found or not owned by user")
 	}
 
-	// Update content in database
-	if err := s.repo.UpdateContent(ctx, id, user.ID, content); err != nil {
-		return nil, fmt.Errorf("failed to update content: %w", err)
-	}
-
-	// Update document with final status and counts
-	if err := s.repo.UpdateStatus(ctx, id, StatusReady, tokenCount, chunkCount); err != nil {
-		return nil, fmt.Errorf("failed to update document status: %w", err)
-	}
-
-	// Reset reading state to beginning, preserving user's WPM preference
-	wpm := 300 // Default WPM
-	chunkSize := 1
-	if existingState, err := s.repo.GetReadingState(ctx, user.ID, id); err == nil && existingState != nil {
-		wpm = existingState.WPM
-		chunkSize = existingState.ChunkSize
-	}
-
-	state := &ReadingState{
-		UserID:     user.ID,
-		DocID:      id,
-		TokenIndex: 0,
-		WPM:        wpm,
-		ChunkSize:  chunkSize,
+	// Save the content, bump the version, keep the text being replaced as a
+	// revision and queue processing all at once, so of two edits made at the
+	// same version only one goes ahead, and a failed edit leaves nothing behind
+	if err := s.repo.UpdateContent(ctx, id, user.ID, title, content, language.Detect(content), expected); err != nil {
+		return nil, err
 	}
-	// Non-fatal error: reading state reset failure shouldn't fail the content update
-	_ = s.repo.UpsertReadingState(ctx, state)
+	s.wakeWorkers()
 
-	// Return updated document
 	return s.GetDocument(ctx, id)
 }
 
@@ -0,0 +1,272 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+)
+
+const (
+	// SessionIdleTimeout is the longest gap between progress saves that still
+	// counts as one sitting; a later save starts a new session
+	SessionIdleTimeout = 5 * time.Minute
+
+	// DefaultSessionLimit is how many sessions are listed when no limit is given
+	DefaultSessionLimit = 100
+
+	// MaxSessionLimit caps the sessions returned by one listing
+	MaxSessionLimit = 500
+
+	// seekSlack is how many tokens a save may advance beyond what the reader
+	// could plausibly have read before it's treated as a seek
+	seekSlack = 50
+)
+
+// ClientType identifies the kind of app a reader used
+type ClientType string
+
+const (
+	ClientWeb       ClientType = "web"
+	ClientMobile    ClientType = "mobile"
+	ClientDesktop   ClientType = "desktop"
+	ClientExtension ClientType = "extension"
+	ClientOther     ClientType = "other"
+)
+
+// ParseClientType normalizes a client name sent with reading progress.
+// Clients that don't say are the web app; unrecognized names are "other".
+func ParseClientType(s string) ClientType {
+	switch c := ClientType(strings.ToLower(strings.TrimSpace(s))); c {
+	case "":
+		return ClientWeb
+	case ClientWeb, ClientMobile, ClientDesktop, ClientExtension:
+		return c
+	default:
+		return ClientOther
+	}
+}
+
+// ReadingSession is one sitting spent reading a document. WordsRead counts
+// tokens advanced while reading, so seeks and rewinds don't inflate it.
+type ReadingSession struct {
+	ID              uuid.UUID  `json:"id"`
+	DocID           uuid.UUID  `json:"docId"`
+	DocTitle        string     `json:"docTitle"`
+	StartedAt       time.Time  `json:"startedAt"`
+	EndedAt         time.Time  `json:"endedAt"`
+	StartIndex      int        `json:"startIndex"`
+	EndIndex        int        `json:"endIndex"`
+	WordsRead       int        `json:"wordsRead"`
+	WPM             int        `json:"wpm"`
+	EffectiveWPM    int        `json:"effectiveWpm"`
+	DurationSeconds int        `json:"durationSeconds"`
+	Client          ClientType `json:"client"`
+}
+
+// fillDerived computes the duration and effective speed of a session
+func (s *ReadingSession) fillDerived() {
+	duration := s.EndedAt.Sub(s.StartedAt)
+	s.DurationSeconds = int(duration.Seconds())
+	s.EffectiveWPM = s.WPM
+	if duration >= time.Second {
+		s.EffectiveWPM = int(float64(s.WordsRead) / duration.Minutes())
+	}
+}
+
+// SessionListOptions filters a listing of reading sessions. From and To bound
+// the session start time, From inclusive and To exclusive.
+type SessionListOptions struct {
+	From  *time.Time
+	To    *time.Time
+	DocID *uuid.UUID
+	Limit int
+}
+
+// sessionProgress is a reading state save as seen by session tracking
+type sessionProgress struct {
+	fromIndex int // position before the save
+	toIndex   int // position saved
+	wpm       int
+	client    ClientType
+	at        time.Time
+}
+
+// wordsIn returns how many words are read at wpm in d
+func wordsIn(d time.Duration, wpm int) int {
+	return int(d.Minutes() * float64(wpm))
+}
+
+// advanceSession applies a progress save to the reader's latest session in a
+// document. It returns the session to store and whether it must be inserted,
+// or nil when the save neither continues a session nor amounts to reading.
+//
+// A save continues the open session when it comes from the same client within
+// SessionIdleTimeout of the last one. Forward progress counts as words read
+// unless it outruns the reader's speed, which means they skipped ahead. A new
+// session is only started by reading, and its start time is estimated from the
+// words read at the reader's speed since no earlier save marks it.
+func advanceSession(open *ReadingSession, p sessionProgress) (*ReadingSession, bool) {
+	wpm := p.wpm
+	if wpm <= 0 {
+		wpm = 300
+	}
+
+	continuing := open != nil && open.Client == p.client &&
+		!p.at.Before(open.EndedAt) && p.at.Sub(open.EndedAt) <= SessionIdleTimeout
+
+	elapsed := SessionIdleTimeout
+	if continuing {
+		elapsed = p.at.Sub(open.EndedAt)
+	}
+
+	words := 0
+	if advance := p.toIndex - p.fromIndex; advance > 0 && advance <= 2*wordsIn(elapsed, wpm)+seekSlack {
+		words = advance
+	}
+
+	if continuing {
+		s := *open
+		s.EndedAt = p.at
+		s.EndIndex = p.toIndex
+		s.WordsRead += words
+		s.WPM = wpm
+		return &s, false
+	}
+
+	if words == 0 {
+		return nil, false
+	}
+
+	return &ReadingSession{
+		StartedAt:  p.at.Add(-time.Duration(float64(words) / float64(wpm) * float64(time.Minute))),
+		EndedAt:    p.at,
+		StartIndex: p.fromIndex,
+		EndIndex:   p.toIndex,
+		WordsRead:  words,
+		WPM:        wpm,
+		Client:     p.client,
+	}, true
+}
+
+// RecordSessionProgress folds a progress save into the reader's session
+// history for a document, extending their latest session or starting one
+func (r *Repository) RecordSessionProgress(ctx context.Context, userID, docID uuid.UUID, p sessionProgress) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	latest := `
+		SELECT id, started_at, ended_at, start_index, end_index, words_read, wpm, client
+		FROM reading_sessions
+		WHERE user_id = $1 AND doc_id = $2
+		ORDER BY ended_at DESC
+		LIMIT 1
+		FOR UPDATE
+	`
+	var open *ReadingSession
+	s := &ReadingSession{DocID: docID}
+	err = tx.QueryRowContext(ctx, latest, userID, docID).Scan(
+		&s.ID, &s.StartedAt, &s.EndedAt, &s.StartIndex, &s.EndIndex, &s.WordsRead, &s.WPM, &s.Client)
+	switch {
+	case err == nil:
+		open = s
+	case err != sql.ErrNoRows:
+		return fmt.Errorf("failed to get latest session: %w", err)
+	}
+
+	next, isNew := advanceSession(open, p)
+	switch {
+	case next == nil:
+		return nil
+	case isNew:
+		insert := `
+			INSERT INTO reading_sessions (user_id, doc_id, started_at, ended_at, start_index, end_index, words_read, wpm, client)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`
+		_, err = tx.ExecContext(ctx, insert, userID, docID,
+			next.StartedAt, next.EndedAt, next.StartIndex, next.EndIndex, next.WordsRead, next.WPM, next.Client)
+	default:
+		update := `UPDATE reading_sessions SET ended_at = $2, end_index = $3, words_read = $4, wpm = $5 WHERE id = $1`
+		_, err = tx.ExecContext(ctx, update, next.ID, next.EndedAt, next.EndIndex, next.WordsRead, next.WPM)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save reading session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reading session: %w", err)
+	}
+	return nil
+}
+
+// ListSessions returns a user's reading sessions, most recent first
+func (r *Repository) ListSessions(ctx context.Context, userID uuid.UUID, opts SessionListOptions) ([]ReadingSession, error) {
+	args := []interface{}{userID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where := []string{"s.user_id = $1"}
+	if opts.From != nil {
+		where = append(where, "s.started_at >= "+arg(*opts.From))
+	}
+	if opts.To != nil {
+		where = append(where, "s.started_at < "+arg(*opts.To))
+	}
+	if opts.DocID != nil {
+		where = append(where, "s.doc_id = "+arg(*opts.DocID))
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultSessionLimit
+	}
+
+	query := `
+		SELECT s.id, s.doc_id, d.title, s.started_at, s.ended_at, s.start_index, s.end_index, s.words_read, s.wpm, s.client
+		FROM reading_sessions s
+		JOIN documents d ON d.id = s.doc_id
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY s.started_at DESC, s.id
+		LIMIT ` + arg(limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reading sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []ReadingSession{}
+	for rows.Next() {
+		var s ReadingSession
+		err := rows.Scan(&s.ID, &s.DocID, &s.DocTitle, &s.StartedAt, &s.EndedAt, &s.StartIndex, &s.EndIndex, &s.WordsRead, &s.WPM, &s.Client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reading session: %w", err)
+		}
+		s.fillDerived()
+		sessions = append(sessions, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reading sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// ListReadingSessions returns the current user's reading history
+func (s *Service) ListReadingSessions(ctx context.Context, opts SessionListOptions) ([]ReadingSession, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("user not found in context")
+	}
+
+	return s.repo.ListSessions(ctx, user.ID, opts)
+}
@@ -0,0 +1,108 @@
+package documents
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseClientType(t *testing.T) {
+	tests := map[string]ClientType{
+		"":           ClientWeb,
+		"web":        ClientWeb,
+		" Mobile ":   ClientMobile,
+		"extension":  ClientExtension,
+		"smartwatch": ClientOther,
+	}
+
+	for input, want := range tests {
+		if got := ParseClientType(input); got != want {
+			t.Errorf("ParseClientType(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestAdvanceSession_StartsOnReading(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	s, isNew := advanceSession(nil, sessionProgress{fromIndex: 100, toIndex: 400, wpm: 300, client: ClientWeb, at: now})
+	if s == nil || !isNew {
+		t.Fatalf("expected a new session, got %+v (new=%v)", s, isNew)
+	}
+	if s.WordsRead != 300 || s.StartIndex != 100 || s.EndIndex != 400 {
+		t.Errorf("unexpected session: %+v", s)
+	}
+	// 300 words at 300 wpm started a minute ago
+	if want := now.Add(-time.Minute); !s.StartedAt.Equal(want) {
+		t.Errorf("expected start %v, got %v", want, s.StartedAt)
+	}
+}
+
+func TestAdvanceSession_IgnoresNonReading(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		from, to int
+	}{
+		{"no movement", 100, 100},
+		{"rewind", 400, 100},
+		{"seek ahead", 0, 50000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := advanceSession(nil, sessionProgress{fromIndex: tt.from, toIndex: tt.to, wpm: 300, client: ClientWeb, at: now})
+			if s != nil {
+				t.Errorf("expected no session, got %+v", s)
+			}
+		})
+	}
+}
+
+func TestAdvanceSession_Continues(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	open := &ReadingSession{StartedAt: start, EndedAt: start.Add(time.Minute), StartIndex: 0, EndIndex: 300, WordsRead: 300, WPM: 300, Client: ClientWeb}
+
+	s, isNew := advanceSession(open, sessionProgress{fromIndex: 300, toIndex: 600, wpm: 350, client: ClientWeb, at: start.Add(2 * time.Minute)})
+	if s == nil || isNew {
+		t.Fatalf("expected the open session to continue, got %+v (new=%v)", s, isNew)
+	}
+	if s.WordsRead != 600 || s.EndIndex != 600 || s.WPM != 350 || !s.StartedAt.Equal(start) {
+		t.Errorf("unexpected session: %+v", s)
+	}
+	if open.WordsRead != 300 {
+		t.Error("expected the open session to be left untouched")
+	}
+
+	// A seek within the session moves its end without counting words
+	s, _ = advanceSession(open, sessionProgress{fromIndex: 300, toIndex: 9000, wpm: 300, client: ClientWeb, at: start.Add(90 * time.Second)})
+	if s.WordsRead != 300 || s.EndIndex != 9000 {
+		t.Errorf("expected the seek to be skipped, got %+v", s)
+	}
+}
+
+func TestAdvanceSession_SplitsOnInactivity(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	open := &ReadingSession{StartedAt: start, EndedAt: start.Add(time.Minute), EndIndex: 300, WordsRead: 300, WPM: 300, Client: ClientWeb}
+
+	s, isNew := advanceSession(open, sessionProgress{fromIndex: 300, toIndex: 450, wpm: 300, client: ClientWeb, at: start.Add(time.Hour)})
+	if s == nil || !isNew || s.WordsRead != 150 {
+		t.Errorf("expected a new session after the pause, got %+v (new=%v)", s, isNew)
+	}
+
+	// Switching devices starts a new session too
+	s, isNew = advanceSession(open, sessionProgress{fromIndex: 300, toIndex: 450, wpm: 300, client: ClientMobile, at: start.Add(2 * time.Minute)})
+	if s == nil || !isNew || s.Client != ClientMobile {
+		t.Errorf("expected a new mobile session, got %+v (new=%v)", s, isNew)
+	}
+}
+
+func TestReadingSession_FillDerived(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := &ReadingSession{StartedAt: start, EndedAt: start.Add(2 * time.Minute), WordsRead: 500, WPM: 300}
+	s.fillDerived()
+
+	if s.DurationSeconds != 120 || s.EffectiveWPM != 250 {
+		t.Errorf("expected 120s at 250 wpm, got %ds at %d wpm", s.DurationSeconds, s.EffectiveWPM)
+	}
+}
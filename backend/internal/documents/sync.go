@@ -0,0 +1,239 @@
+package documents
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+)
+
+// TombstoneRetention is how long deletions are kept for sync. A client whose
+// cursor is older must fetch its library again.
+const TombstoneRetention = 90 * 24 * time.Hour
+
+// syncOverlap is how far before the time of a sync its cursor points, so
+// writes still committing when the changes were read are picked up next time.
+// Changes in the overlap are sent twice, which clients apply idempotently.
+const syncOverlap = 5 * time.Second
+
+var (
+	// ErrInvalidSyncCursor indicates a sync cursor that is malformed
+	ErrInvalidSyncCursor = errors.New("invalid sync cursor")
+	// ErrSyncCursorExpired indicates a sync cursor older than the retained
+	// tombstones, so deletions since then may have been forgotten
+	ErrSyncCursorExpired = errors.New("sync cursor expired")
+)
+
+// tombstoneDeleted is a CTE, following one named deleted that returns the id
+// and user_id of deleted documents, recording them for sync
+const tombstoneDeleted = `tombstones AS (
+	INSERT INTO document_tombstones (doc_id, user_id)
+	SELECT id, user_id FROM deleted WHERE user_id IS NOT NULL
+	ON CONFLICT (doc_id) DO UPDATE SET deleted_at = NOW()
+)`
+
+// DeletedDocument is a document removed from the library, either moved to
+// the trash or deleted for good
+type DeletedDocument struct {
+	ID        uuid.UUID `json:"id"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// Changes are what changed in a user's library since a sync cursor. Created
+// and Updated hold documents added or changed since then, with the user's
+// progress. Progress holds reading positions that moved, which may be for
+// documents not otherwise changed. Cursor is passed to the next sync.
+type Changes struct {
+	Created  []DocumentWithProgress `json:"created"`
+	Updated  []DocumentWithProgress `json:"updated"`
+	Deleted  []DeletedDocument      `json:"deleted"`
+	Progress []ReadingState         `json:"progress"`
+	Cursor   string                 `json:"cursor"`
+}
+
+// syncCursor is the time changes were last synced up to
+type syncCursor struct {
+	Since time.Time `json:"t"`
+}
+
+func encodeSyncCursor(since time.Time) string {
+	data, _ := json.Marshal(syncCursor{Since: since.UTC()})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSyncCursor returns the time a cursor syncs from, rejecting cursors
+// from before now-TombstoneRetention
+func decodeSyncCursor(s string, now time.Time) (time.Time, error) {
+	var c syncCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return time.Time{}, ErrInvalidSyncCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.Since.IsZero() {
+		return time.Time{}, ErrInvalidSyncCursor
+	}
+	if c.Since.Before(now.Add(-TombstoneRetention)) {
+		return time.Time{}, ErrSyncCursorExpired
+	}
+	return c.Since, nil
+}
+
+// SyncTime returns the database's current time, which sync cursors are
+// compared against
+func (r *Repository) SyncTime(ctx context.Context) (time.Time, error) {
+	var now time.Time
+	if err := r.db.QueryRowContext(ctx, `SELECT NOW()`).Scan(&now); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get sync time: %w", err)
+	}
+	return now, nil
+}
+
+// ListDeletedSince retrieves the user's documents moved to the trash or
+// deleted for good after since
+func (r *Repository) ListDeletedSince(ctx context.Context, userID uuid.UUID, since time.Time) ([]DeletedDocument, error) {
+	query := `
+		SELECT id, deleted_at
+		FROM documents
+		WHERE user_id = $1 AND deleted_at > $2
+		UNION ALL
+		SELECT doc_id, deleted_at
+		FROM document_tombstones
+		WHERE user_id = $1 AND deleted_at > $2
+		ORDER BY deleted_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted documents: %w", err)
+	}
+	defer rows.Close()
+
+	deleted := []DeletedDocument{}
+	for rows.Next() {
+		var d DeletedDocument
+		if err := rows.Scan(&d.ID, &d.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan deleted document: %w", err)
+		}
+		deleted = append(deleted, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deleted documents: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// ListReadingStatesSince retrieves the user's reading positions that changed
+// after since, whether saved by a reader or moved by an edit, for documents
+// still in the library
+func (r *Repository) ListReadingStatesSince(ctx context.Context, userID uuid.UUID, since time.Time) ([]ReadingState, error) {
+	query := `
+		SELECT rs.user_id, rs.doc_id, rs.token_index, rs.wpm, rs.chunk_size, rs.updated_at, rs.version, rs.client_updated_at
+		FROM reading_state rs
+		JOIN documents d ON d.id = rs.doc_id AND d.deleted_at IS NULL
+		WHERE rs.user_id = $1 AND rs.changed_at > $2
+		ORDER BY rs.changed_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reading states: %w", err)
+	}
+	defer rows.Close()
+
+	states := []ReadingState{}
+	for rows.Next() {
+		var s ReadingState
+		if err := rows.Scan(&s.UserID, &s.DocID, &s.TokenIndex, &s.WPM, &s.ChunkSize, &s.UpdatedAt, &s.Version, &s.ClientUpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reading state: %w", err)
+		}
+		states = append(states, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reading states: %w", err)
+	}
+
+	return states, nil
+}
+
+// PurgeTombstones deletes tombstones recorded before the cutoff
+func (r *Repository) PurgeTombstones(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM document_tombstones WHERE deleted_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge tombstones: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
+
+// GetChanges returns what changed in the current user's library since the
+// given cursor. An empty cursor returns the whole library, to start syncing.
+func (s *Service) GetChanges(ctx context.Context, cursor string) (*Changes, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("user not found in context")
+	}
+
+	now, err := s.repo.SyncTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var since time.Time
+	if cursor != "" {
+		if since, err = decodeSyncCursor(cursor, now); err != nil {
+			return nil, err
+		}
+	}
+
+	ascending := true
+	opts := ListOptions{Sort: SortCreated, Ascending: &ascending}
+	if cursor != "" {
+		opts.ModifiedSince = &since
+	}
+	docs, _, err := s.repo.List(ctx, user.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &Changes{
+		Created:  []DocumentWithProgress{},
+		Updated:  []DocumentWithProgress{},
+		Deleted:  []DeletedDocument{},
+		Progress: []ReadingState{},
+		Cursor:   encodeSyncCursor(now.Add(-syncOverlap)),
+	}
+	for _, doc := range docs {
+		if doc.CreatedAt.After(since) {
+			changes.Created = append(changes.Created, doc)
+		} else {
+			changes.Updated = append(changes.Updated, doc)
+		}
+	}
+
+	// A full sync has nothing to delete, and its documents carry progress
+	if cursor == "" {
+		return changes, nil
+	}
+
+	if changes.Deleted, err = s.repo.ListDeletedSince(ctx, user.ID, since); err != nil {
+		return nil, err
+	}
+	if changes.Progress, err = s.repo.ListReadingStatesSince(ctx, user.ID, since); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,81 @@
+package documents
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSyncCursorRoundTrip(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-time.Hour).Add(123456 * time.Microsecond)
+
+	got, err := decodeSyncCursor(encodeSyncCursor(since), now)
+	if err != nil {
+		t.Fatalf("decodeSyncCursor failed: %v", err)
+	}
+	if !got.Equal(since) {
+		t.Errorf("since = %v, want %v", got, since)
+	}
+}
+
+func TestDecodeSyncCursor_Invalid(t *testing.T) {
+	now := time.Now()
+	for _, s := range []string{"not base64!", "bm90IGpzb24", "e30"} { // "e30" is {}
+		if _, err := decodeSyncCursor(s, now); !errors.Is(err, ErrInvalidSyncCursor) {
+			t.Errorf("decodeSyncCursor(%q) = %v, want ErrInvalidSyncCursor", s, err)
+		}
+	}
+}
+
+func TestDecodeSyncCursor_Expired(t *testing.T) {
+	now := time.Now()
+
+	old := encodeSyncCursor(now.Add(-TombstoneRetention - time.Minute))
+	if _, err := decodeSyncCursor(old, now); !errors.Is(err, ErrSyncCursorExpired) {
+		t.Errorf("expected ErrSyncCursorExpired, got %v", err)
+	}
+
+	recent := encodeSyncCursor(now.Add(-TombstoneRetention + time.Minute))
+	if _, err := decodeSyncCursor(recent, now); err != nil {
+		t.Errorf("expected cursor within retention to be accepted, got %v", err)
+	}
+}
+
+func TestGetChanges_SendsRemappedPositionsWithoutCountingAsReading(t *testing.T) {
+	svc, db := testService(t)
+	ctx := testUser(t, db)
+	id := testDocument(t, svc, ctx, "one two three")
+
+	state := &ReadingState{DocID: id, TokenIndex: 2, WPM: 300, ChunkSize: 1}
+	if err := svc.UpdateReadingState(ctx, state, ClientWeb, WriteCondition{}); err != nil {
+		t.Fatalf("failed to save position: %v", err)
+	}
+	read, err := svc.GetReadingState(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get position: %v", err)
+	}
+
+	changes, err := svc.GetChanges(ctx, "")
+	if err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	// Start after the overlap so the position saved above isn't sent again
+	time.Sleep(syncOverlap + 100*time.Millisecond)
+
+	if _, err := svc.UpdateDocumentContent(ctx, id, "", "zero one two three", nil); err != nil {
+		t.Fatalf("failed to edit: %v", err)
+	}
+	testProcess(t, svc, id)
+
+	changes, err = svc.GetChanges(ctx, changes.Cursor)
+	if err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if len(changes.Progress) != 1 || changes.Progress[0].TokenIndex != 3 {
+		t.Fatalf("expected the moved position at token 3 in the changes, got %+v", changes.Progress)
+	}
+	if !changes.Progress[0].UpdatedAt.Equal(read.UpdatedAt) {
+		t.Errorf("expected the edit to leave when the reader last read at %v, got %v", read.UpdatedAt, changes.Progress[0].UpdatedAt)
+	}
+}
@@ -0,0 +1,74 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+	"github.com/mikepersonal/speed-reader/backend/internal/database"
+	"github.com/mikepersonal/speed-reader/backend/internal/storage"
+)
+
+// testService returns a service backed by the database named by
+// TEST_DATABASE_URL, skipping the test when it isn't set
+func testService(t *testing.T) (*Service, *sql.DB) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	return NewService(NewRepository(db), storage.NewChunkStore(t.TempDir()), 7), db
+}
+
+// testUser creates a user, removed with their documents when the test ends,
+// and returns a context signed in as them
+func testUser(t *testing.T, db *sql.DB) context.Context {
+	t.Helper()
+
+	user := &auth.User{Name: "documents test"}
+	if err := db.QueryRow(`INSERT INTO users (name) VALUES ($1) RETURNING id`, user.Name).Scan(&user.ID); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE id = $1`, user.ID) })
+
+	return auth.ContextWithUser(context.Background(), user)
+}
+
+// testDocument creates a processed document owned by the context's user
+func testDocument(t *testing.T, svc *Service, ctx context.Context, content string) uuid.UUID {
+	t.Helper()
+
+	user, _ := auth.UserFromContext(ctx)
+	doc, err := svc.repo.Create(ctx, &CreateParams{Title: "Notes", Content: content, UserID: user.ID})
+	if err != nil {
+		t.Fatalf("failed to create document: %v", err)
+	}
+	testProcess(t, svc, doc.ID)
+
+	return doc.ID
+}
+
+// testProcess runs a document's queued processing, as a worker would
+func testProcess(t *testing.T, svc *Service, id uuid.UUID) {
+	t.Helper()
+
+	if _, err := svc.ProcessDocument(context.Background(), id); err != nil {
+		t.Fatalf("failed to process document: %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+)
+
+// ErrNotInTrash indicates a document that isn't in the user's trash
+var ErrNotInTrash = errors.New("document not in trash")
+
+// TrashedDocument is a deleted document awaiting restore or purge
+type TrashedDocument struct {
+	Document
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// MoveToTrash soft-deletes a document owned by a user. The row, its chunks and
+// reading state are kept until the trash is emptied or the cleanup job purges it.
+// Its processing job, if any, is dropped; Restore queues it again.
+func (r *Repository) MoveToTrash(ctx context.Context, id, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE documents SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	result, err := tx.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("document not found or not owned by user")
+	}
+
+	// Workers can't load a trashed document, so its job would only use up
+	// its attempts and mark the document failed
+	if _, err := tx.ExecContext(ctx, `DELETE FROM document_jobs WHERE doc_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit delete: %w", err)
+	}
+
+	return nil
+}
+
+// ListTrash retrieves a user's deleted documents, most recently deleted first
+func (r *Repository) ListTrash(ctx context.Context, userID uuid.UUID) ([]TrashedDocument, error) {
+	query := `
+		SELECT id, user_id, title, status, token_count, chunk_count, visibility, share_token, expires_at, created_at, content IS NOT NULL,
+			   source_type, COALESCE(author, ''), COALESCE(source_url, ''), COALESCE(language, ''), deleted_at
+		FROM documents
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []TrashedDocument{}
+	for rows.Next() {
+		var doc TrashedDocument
+		var docUserID, shareToken sql.NullString
+		var expiresAt sql.NullTime
+		err := rows.Scan(
+			&doc.ID, &docUserID, &doc.Title, &doc.Status, &doc.TokenCount, &doc.ChunkCount, &doc.Visibility, &shareToken, &expiresAt, &doc.CreatedAt, &doc.HasContent,
+			&doc.SourceType, &doc.Author, &doc.SourceURL, &doc.Language, &doc.DeletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		if docUserID.Valid {
+			uid, _ := uuid.Parse(docUserID.String)
+			doc.UserID = &uid
+		}
+		if shareToken.Valid {
+			st, _ := uuid.Parse(shareToken.String)
+			doc.ShareToken = &st
+		}
+		if expiresAt.Valid {
+			doc.ExpiresAt = &expiresAt.Time
+		}
+		docs = append(docs, doc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trash: %w", err)
+	}
+
+	return docs, nil
+}
+
+// Restore takes a document out of a user's trash. A document that wasn't
+// ready when it was trashed is queued for processing again, reporting
+// whether it was.
+func (r *Repository) Restore(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE documents SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL RETURNING status`
+
+	var status DocumentStatus
+	if err := tx.QueryRowContext(ctx, query, id, userID).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return false, ErrNotInTrash
+		}
+		return false, fmt.Errorf("failed to restore document: %w", err)
+	}
+
+	queued := status != StatusReady
+	if queued {
+		if _, err := tx.ExecContext(ctx, `UPDATE documents SET status = 'pending' WHERE id = $1`, id); err != nil {
+			return false, fmt.Errorf("failed to update document: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, enqueueJobQuery, id, userID, maxJobAttempts); err != nil {
+			return false, fmt.Errorf("failed to enqueue job: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit restore: %w", err)
+	}
+
+	return queued, nil
+}
+
+// DeleteFromTrash permanently deletes one document from a user's trash
+func (r *Repository) DeleteFromTrash(ctx context.Context, id, userID uuid.UUID) error {
+	query := `
+		WITH deleted AS (
+			DELETE FROM documents WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+			RETURNING id, user_id
+		), ` + tombstoneDeleted + `
+		SELECT COUNT(*) FROM deleted
+	`
+
+	var rows int
+	if err := r.db.QueryRowContext(ctx, query, id, userID).Scan(&rows); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotInTrash
+	}
+
+	return nil
+}
+
+// EmptyTrash permanently deletes every document in a user's trash
+func (r *Repository) EmptyTrash(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		WITH deleted AS (
+			DELETE FROM documents WHERE user_id = $1 AND deleted_at IS NOT NULL
+			RETURNING id, user_id
+		), ` + tombstoneDeleted + `
+		SELECT id FROM deleted
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to empty trash: %w", err)
+	}
+	return scanDeletedIDs(rows)
+}
+
+// PurgeTrash permanently deletes documents that have been in the trash since
+// before cutoff, across all users
+func (r *Repository) PurgeTrash(ctx context.Context, cutoff time.Time) ([]uuid.UUID, error) {
+	query := `
+		WITH deleted AS (
+			DELETE FROM documents WHERE deleted_at < $1
+			RETURNING id, user_id
+		), ` + tombstoneDeleted + `
+		SELECT id FROM deleted
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge trash: %w", err)
+	}
+	return scanDeletedIDs(rows)
+}
+
+// scanDeletedIDs collects the IDs returned by a DELETE ... RETURNING id
+func scanDeletedIDs(rows *sql.Rows) ([]uuid.UUID, error) {
+	defer rows.Close()
+
+	var deletedIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan deleted ID: %w", err)
+		}
+		deletedIDs = append(deletedIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deleted IDs: %w", err)
+	}
+
+	return deletedIDs, nil
+}
+
+// ListTrash retrieves the current user's deleted documents
+func (s *Service) ListTrash(ctx context.Context) ([]TrashedDocument, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("user not found in context")
+	}
+
+	return s.repo.ListTrash(ctx, user.ID)
+}
+
+// RestoreDocument moves a document out of the trash with its reading
+// position, tags and collections intact, resuming its processing if it
+// wasn't finished
+func (s *Service) RestoreDocument(ctx context.Context, id uuid.UUID) error {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("user not found in context")
+	}
+
+	queued, err := s.repo.Restore(ctx, id, user.ID)
+	if err != nil {
+		return err
+	}
+	if queued {
+		s.wakeWorkers()
+	}
+
+	return nil
+}
+
+// DeleteFromTrash permanently removes a trashed document and its chunks
+func (s *Service) DeleteFromTrash(ctx context.Context, id uuid.UUID) error {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("user not found in context")
+	}
+
+	if err := s.repo.DeleteFromTrash(ctx, id, user.ID); err != nil {
+		return err
+	}
+
+	return s.chunkStore.DeleteDocument(id)
+}
+
+// EmptyTrash permanently removes every trashed document of the current user,
+// returning how many were deleted
+func (s *Service) EmptyTrash(ctx context.Context) (int, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return 0, fmt.Errorf("user not found in context")
+	}
+
+	deletedIDs, err := s.repo.EmptyTrash(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	// The rows are gone, so a leftover chunk directory is only wasted space;
+	// keep going and report the first failure
+	var chunkErr error
+	for _, id := range deletedIDs {
+		if err := s.chunkStore.DeleteDocument(id); err != nil && chunkErr == nil {
+			chunkErr = err
+		}
+	}
+
+	return len(deletedIDs), chunkErr
+}
@@ -0,0 +1,60 @@
+package documents
+
+import (
+	"testing"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+)
+
+func TestRestoreDocument_ResumesProcessing(t *testing.T) {
+	svc, db := testService(t)
+	ctx := testUser(t, db)
+
+	user, _ := auth.UserFromContext(ctx)
+	doc, err := svc.createDocument(ctx, user, &CreateDocumentInput{Title: "Notes", Content: "one two three"})
+	if err != nil {
+		t.Fatalf("failed to create document: %v", err)
+	}
+
+	if err := svc.DeleteDocument(ctx, doc.ID); err != nil {
+		t.Fatalf("failed to trash document: %v", err)
+	}
+	if job, err := svc.repo.GetJob(ctx, doc.ID); err != nil || job != nil {
+		t.Fatalf("expected trashing to drop the pending job, got %+v (%v)", job, err)
+	}
+
+	if err := svc.RestoreDocument(ctx, doc.ID); err != nil {
+		t.Fatalf("failed to restore document: %v", err)
+	}
+	job, err := svc.repo.GetJob(ctx, doc.ID)
+	if err != nil || job == nil || job.Status != JobPending {
+		t.Fatalf("expected restoring to queue the document again, got %+v (%v)", job, err)
+	}
+
+	testProcess(t, svc, doc.ID)
+
+	restored, err := svc.GetDocument(ctx, doc.ID)
+	if err != nil {
+		t.Fatalf("failed to get document: %v", err)
+	}
+	if restored.Status != StatusReady || restored.TokenCount != 3 {
+		t.Errorf("expected a ready document with 3 tokens, got %s with %d", restored.Status, restored.TokenCount)
+	}
+}
+
+func TestRestoreDocument_LeavesReadyDocumentAlone(t *testing.T) {
+	svc, db := testService(t)
+	ctx := testUser(t, db)
+	id := testDocument(t, svc, ctx, "one two three")
+
+	if err := svc.DeleteDocument(ctx, id); err != nil {
+		t.Fatalf("failed to trash document: %v", err)
+	}
+	if err := svc.RestoreDocument(ctx, id); err != nil {
+		t.Fatalf("failed to restore document: %v", err)
+	}
+
+	if job, err := svc.repo.GetJob(ctx, id); err != nil || job != nil {
+		t.Errorf("expected no job for a ready document, got %+v (%v)", job, err)
+	}
+}
@@ -0,0 +1,54 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrVersionMismatch indicates a conditional edit to a document that was
+	// edited by someone else since the editor last read it
+	ErrVersionMismatch = errors.New("document was modified")
+	// ErrDocumentProcessing indicates an edit to a document that is still
+	// queued or being processed; it can be retried once the document is ready
+	ErrDocumentProcessing = errors.New("document is being processed")
+	// ErrContentChanged indicates processing whose document was edited while
+	// it ran, so its results were discarded
+	ErrContentChanged = errors.New("document changed during processing")
+)
+
+// isProcessing reports whether a document has processing queued or running
+func isProcessing(status DocumentStatus) bool {
+	return status == StatusPending || status == StatusProcessing
+}
+
+// DocumentContent is a document's original text and the version it's from
+type DocumentContent struct {
+	Content    string
+	HasContent bool
+	Version    int64
+}
+
+// versionConflict explains why an edit matched no document: it isn't the
+// user's to edit, it's still being processed, or it moved past the expected
+// version
+func (r *Repository) versionConflict(ctx context.Context, id, userID uuid.UUID) error {
+	query := `SELECT status FROM documents WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	var status DocumentStatus
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("document not found or not owned by user")
+		}
+		return fmt.Errorf("failed to check document: %w", err)
+	}
+	if isProcessing(status) {
+		return ErrDocumentProcessing
+	}
+	return ErrVersionMismatch
+}
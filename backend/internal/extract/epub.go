@@ -0,0 +1,213 @@
+package extract
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// epubContainer is META-INF/container.xml, which points at the OPF package file
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the subset of the OPF package document we need
+type epubPackage struct {
+	Titles   []string `xml:"metadata>title"`
+	Creators []string `xml:"metadata>creator"`
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef  string `xml:"idref,attr"`
+		Linear string `xml:"linear,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// EPUB extracts chapters and metadata from an EPUB archive. Each spine item
+// becomes a section, titled by its leading heading when it has one.
+func EPUB(r io.ReaderAt, size int64) (*Document, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid epub archive: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	budget := &archiveBudget{remaining: maxArchiveBytes}
+
+	var container epubContainer
+	if err := decodeZipXML(files, "META-INF/container.xml", budget, &container); err != nil {
+		return nil, fmt.Errorf("invalid epub container: %w", err)
+	}
+
+	opfPath := ""
+	for _, rf := range container.Rootfiles {
+		if rf.MediaType == "" || rf.MediaType == "application/oebps-package+xml" {
+			opfPath = rf.FullPath
+			break
+		}
+	}
+	if opfPath == "" {
+		return nil, fmt.Errorf("invalid epub: no package document")
+	}
+
+	var pkg epubPackage
+	if err := decodeZipXML(files, opfPath, budget, &pkg); err != nil {
+		return nil, fmt.Errorf("invalid epub package: %w", err)
+	}
+
+	doc := &Document{Format: "epub"}
+	if len(pkg.Titles) > 0 {
+		doc.Title = collapseSpace(pkg.Titles[0])
+	}
+	var authors []string
+	for _, creator := range pkg.Creators {
+		if c := collapseSpace(creator); c != "" {
+			authors = append(authors, c)
+		}
+	}
+	doc.Author = strings.Join(authors, ", ")
+
+	manifest := make(map[string]string, len(pkg.Manifest))
+	mediaTypes := make(map[string]string, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		manifest[item.ID] = item.Href
+		mediaTypes[item.ID] = item.MediaType
+	}
+
+	baseDir := path.Dir(opfPath)
+	for _, ref := range pkg.Spine {
+		if ref.Linear == "no" {
+			continue
+		}
+
+		href, ok := manifest[ref.IDRef]
+		if !ok {
+			continue
+		}
+		switch mediaTypes[ref.IDRef] {
+		case "application/xhtml+xml", "text/html", "":
+		default:
+			continue
+		}
+
+		name := resolveEPUBHref(baseDir, href)
+		f, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid epub: missing spine item %s", name)
+		}
+
+		rc, err := budget.open(f)
+		if err != nil {
+			return nil, err
+		}
+		blocks, err := parseXHTML(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		if section, ok := chapterFromBlocks(blocks); ok {
+			doc.Sections = append(doc.Sections, section)
+		}
+	}
+
+	return doc, nil
+}
+
+// chapterFromBlocks builds one section from a chapter's blocks, using a leading
+// heading as the chapter title. Returns false for chapters without text.
+func chapterFromBlocks(blocks []block) (Section, bool) {
+	var section Section
+	for i, b := range blocks {
+		if i == 0 && b.kind == blockHeading {
+			section.Title = b.text
+			continue
+		}
+		section.Paragraphs = append(section.Paragraphs, b.text)
+	}
+	return section, section.Title != "" || len(section.Paragraphs) > 0
+}
+
+// resolveEPUBHref resolves a manifest href relative to the package document
+func resolveEPUBHref(baseDir, href string) string {
+	if i := strings.IndexByte(href, '#'); i >= 0 {
+		href = href[:i]
+	}
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		href = unescaped
+	}
+	if baseDir == "." {
+		return path.Clean(href)
+	}
+	return path.Join(baseDir, href)
+}
+
+// decodeZipXML decodes an XML file from the archive into v
+func decodeZipXML(files map[string]*zip.File, name string, budget *archiveBudget, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("missing %s", name)
+	}
+
+	rc, err := budget.open(f)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	decoder := xml.NewDecoder(rc)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	return decoder.Decode(v)
+}
+
+// archiveBudget limits how many decompressed bytes may be read from an archive,
+// guarding against zip bombs
+type archiveBudget struct {
+	remaining int64
+}
+
+// open opens an archive entry whose reads count against the budget
+func (b *archiveBudget) open(f *zip.File) (io.ReadCloser, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	return &budgetReader{rc: rc, budget: b}, nil
+}
+
+// budgetReader is an archive entry reader that fails once the budget is spent
+type budgetReader struct {
+	rc     io.ReadCloser
+	budget *archiveBudget
+}
+
+func (r *budgetReader) Read(p []byte) (int, error) {
+	if r.budget.remaining <= 0 {
+		return 0, fmt.Errorf("archive exceeds %d bytes uncompressed", maxArchiveBytes)
+	}
+	if int64(len(p)) > r.budget.remaining {
+		p = p[:r.budget.remaining]
+	}
+	n, err := r.rc.Read(p)
+	r.budget.remaining -= int64(n)
+	return n, err
+}
+
+func (r *budgetReader) Close() error {
+	return r.rc.Close()
+}
@@ -0,0 +1,154 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// buildZip creates an in-memory zip archive from name/content pairs
+func buildZip(t *testing.T, files map[string]string) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close zip: %v", err)
+	}
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+const testContainer = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+const testOPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>The Test Book</dc:title>
+    <dc:creator>Ada Lovelace</dc:creator>
+    <dc:creator>Charles Babbage</dc:creator>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ch1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="text/chapter2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="css" href="style.css" media-type="text/css"/>
+  </manifest>
+  <spine>
+    <itemref idref="nav" linear="no"/>
+    <itemref idref="ch2"/>
+    <itemref idref="ch1"/>
+  </spine>
+</package>`
+
+func TestEPUB_ChaptersAndMetadata(t *testing.T) {
+	r := buildZip(t, map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": testContainer,
+		"OEBPS/content.opf":      testOPF,
+		"OEBPS/nav.xhtml":        `<html><body><nav><ol><li>Contents</li></ol></nav></body></html>`,
+		"OEBPS/text/chapter 1.xhtml": `<?xml version="1.0"?>
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>ignored</title><style>p{}</style></head>
+<body><h1>Chapter One</h1><p>It was a   dark
+night.</p><p>The end&nbsp;came.</p></body></html>`,
+		"OEBPS/text/chapter2.xhtml": `<html><body><h2>Prologue</h2><ul><li>First</li><li>Second</li></ul></body></html>`,
+	})
+
+	doc, err := File("book.EPUB", r, r.Size())
+	if err != nil {
+		t.Fatalf("File failed: %v", err)
+	}
+
+	if doc.Title != "The Test Book" {
+		t.Errorf("expected title 'The Test Book', got %q", doc.Title)
+	}
+	if doc.Author != "Ada Lovelace, Charles Babbage" {
+		t.Errorf("unexpected author %q", doc.Author)
+	}
+	if len(doc.Sections) != 2 {
+		t.Fatalf("expected 2 sections, got %d: %+v", len(doc.Sections), doc.Sections)
+	}
+
+	// Spine order wins over manifest order
+	if doc.Sections[0].Title != "Prologue" || doc.Sections[1].Title != "Chapter One" {
+		t.Errorf("unexpected section order: %q, %q", doc.Sections[0].Title, doc.Sections[1].Title)
+	}
+	if got := doc.Sections[1].Paragraphs; len(got) != 2 || got[0] != "It was a dark night." {
+		t.Errorf("unexpected chapter one paragraphs: %q", got)
+	}
+
+	want := "Prologue\n\nFirst\n\nSecond\n\nChapter One\n\nIt was a dark night.\n\nThe end came."
+	if got := doc.Text(); got != want {
+		t.Errorf("Text() = %q", got)
+	}
+}
+
+func TestEPUB_MissingContainer(t *testing.T) {
+	r := buildZip(t, map[string]string{"mimetype": "application/epub+zip"})
+
+	if _, err := EPUB(r, r.Size()); err == nil {
+		t.Error("expected error for epub without container.xml")
+	}
+}
+
+func TestEPUB_NoText(t *testing.T) {
+	r := buildZip(t, map[string]string{
+		"META-INF/container.xml": testContainer,
+		"OEBPS/content.opf": `<package><metadata/><manifest>
+			<item id="c" href="c.xhtml" media-type="application/xhtml+xml"/></manifest>
+			<spine><itemref idref="c"/></spine></package>`,
+		"OEBPS/c.xhtml": `<html><body><img src="cover.jpg"/></body></html>`,
+	})
+
+	if _, err := File("empty.epub", r, r.Size()); !errors.Is(err, ErrNoText) {
+		t.Errorf("expected ErrNoText, got %v", err)
+	}
+}
+
+func TestFile_UnsupportedFormat(t *testing.T) {
+	r := bytes.NewReader([]byte("hello"))
+
+	if _, err := File("notes.xyz", r, r.Size()); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestDocument_HeadingsPointIntoText(t *testing.T) {
+	doc := &Document{Sections: []Section{
+		{Paragraphs: []string{"Front matter."}},
+		{Title: "Chapter One", Paragraphs: []string{"It was a dark night."}},
+		{Title: "Chapter Two", Paragraphs: []string{"Morning came."}},
+	}}
+
+	text := doc.Text()
+	headings := doc.Headings()
+	if len(headings) != 2 {
+		t.Fatalf("expected 2 headings, got %+v", headings)
+	}
+	for _, h := range headings {
+		if !strings.HasPrefix(text[h.Offset:], h.Title+"\n\n") {
+			t.Errorf("heading %q at %d doesn't start a paragraph of %q", h.Title, h.Offset, text)
+		}
+	}
+
+	first := &Document{Sections: []Section{{Title: "Only", Paragraphs: []string{"Text."}}}}
+	if got := first.Headings(); len(got) != 1 || got[0].Offset != 0 {
+		t.Errorf("expected a leading heading at offset 0, got %+v", got)
+	}
+}
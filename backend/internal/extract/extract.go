@@ -0,0 +1,127 @@
+// Package extract turns uploaded files into plain text suitable for the tokenizer.
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedFormat indicates the uploaded file type cannot be imported
+	ErrUnsupportedFormat = errors.New("unsupported file format")
+
+	// ErrNoText indicates the file was parsed but contained no readable text
+	ErrNoText = errors.New("no readable text found")
+
+	// ErrScannedPDF indicates a PDF whose pages are images without a text layer
+	ErrScannedPDF = errors.New("pdf has no text layer")
+
+	// ErrEncrypted indicates a password-protected file
+	ErrEncrypted = errors.New("encrypted files are not supported")
+)
+
+// maxArchiveBytes caps the total decompressed size read from zipped formats
+const maxArchiveBytes = 64 * 1024 * 1024
+
+// Section is a titled run of paragraphs, such as a book chapter
+type Section struct {
+	Title      string
+	Paragraphs []string
+}
+
+// Document is the text and metadata extracted from a file
+type Document struct {
+	Title    string
+	Author   string
+	Format   string // source format, e.g. "epub"
+	Sections []Section
+}
+
+// Heading is a section title and where it starts in the document's Text
+type Heading struct {
+	Title  string
+	Offset int // byte offset in Text
+}
+
+// Text renders the document as tokenizer input. Paragraphs are separated by
+// blank lines and each section title becomes its own paragraph, so section
+// boundaries survive as paragraph boundaries in the token stream.
+func (d *Document) Text() string {
+	text, _ := d.render()
+	return text
+}
+
+// Headings lists the titled sections in order with where each starts in Text
+func (d *Document) Headings() []Heading {
+	_, headings := d.render()
+	return headings
+}
+
+// render builds Text, noting where each section title lands in it
+func (d *Document) render() (string, []Heading) {
+	var b strings.Builder
+	var headings []Heading
+	parts := 0
+	add := func(part string) {
+		if parts > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(part)
+		parts++
+	}
+
+	for _, section := range d.Sections {
+		if section.Title != "" {
+			if parts > 0 {
+				headings = append(headings, Heading{Title: section.Title, Offset: b.Len() + 2})
+			} else {
+				headings = append(headings, Heading{Title: section.Title})
+			}
+			add(section.Title)
+		}
+		for _, p := range section.Paragraphs {
+			add(p)
+		}
+	}
+	return b.String(), headings
+}
+
+// File extracts a document from an uploaded file, choosing the parser by file extension
+func File(filename string, r io.ReaderAt, size int64) (*Document, error) {
+	var (
+		doc *Document
+		err error
+	)
+
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".epub":
+		doc, err = EPUB(r, size)
+	case ".html", ".htm", ".xhtml":
+		doc, err = HTML(io.NewSectionReader(r, 0, size), "")
+	case ".docx":
+		doc, err = DOCX(r, size)
+	case ".odt":
+		doc, err = ODT(r, size)
+	case ".pdf":
+		doc, err = PDF(r, size)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, path.Ext(filename))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(doc.Text()) == "" {
+		return nil, ErrNoText
+	}
+
+	return doc, nil
+}
+
+// collapseSpace trims text and collapses internal whitespace runs to single spaces
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,474 @@
+package extract
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+var (
+	// unlikelyCandidates match class/id values of page chrome rather than content
+	unlikelyCandidates = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|consent|cookie|disqus|extra|footer|gdpr|header|legends|menu|modal|nav|newsletter|pager|pagination|popup|promo|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|toolbar|tweet|twitter|ad-break|advert`)
+
+	// maybeCandidates rescue elements matching unlikelyCandidates that are probably content
+	maybeCandidates = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow|story|entry|post`)
+
+	// positiveHints and negativeHints adjust a node's score by class/id
+	positiveHints = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	negativeHints = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+
+	// titleSeparators split a site name off a page title, e.g. "Story | Site"
+	titleSeparators = regexp.MustCompile(`\s+[|\-–—»:]\s+`)
+)
+
+// htmlRemovedElements never contain article prose
+var htmlRemovedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Form: true, atom.Nav: true, atom.Footer: true, atom.Aside: true,
+	atom.Svg: true, atom.Button: true, atom.Input: true, atom.Select: true,
+	atom.Textarea: true, atom.Object: true, atom.Embed: true, atom.Canvas: true,
+	atom.Template: true, atom.Dialog: true, atom.Menu: true,
+}
+
+// htmlBlockElements start and end a paragraph-level block
+var htmlBlockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Li: true, atom.Dt: true, atom.Dd: true,
+	atom.Tr: true, atom.Figcaption: true, atom.Header: true, atom.Table: true,
+	atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Figure: true, atom.Address: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+// minParagraphLength is the shortest paragraph text that contributes to scoring
+const minParagraphLength = 25
+
+// HTML extracts the main article from a web page, dropping navigation, banners
+// and other page chrome. contentType is used to detect the page's charset.
+func HTML(r io.Reader, contentType string) (*Document, error) {
+	utf8Reader, err := charset.NewReader(r, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect charset: %w", err)
+	}
+
+	root, err := html.Parse(utf8Reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid html: %w", err)
+	}
+
+	doc := &Document{Format: "html"}
+	meta := htmlMetadata(root)
+	doc.Title = meta.title
+	doc.Author = meta.author
+
+	body := findElement(root, atom.Body)
+	if body == nil {
+		body = root
+	}
+
+	pruneHTML(body)
+
+	article := topCandidate(body)
+	if article == nil {
+		article = body
+	}
+
+	doc.Sections = sectionsFromBlocks(htmlBlocks(article))
+	return doc, nil
+}
+
+// pageMetadata holds document-level metadata from <head>
+type pageMetadata struct {
+	title  string
+	author string
+}
+
+// htmlMetadata reads the title and author from <title> and OpenGraph/meta tags
+func htmlMetadata(root *html.Node) pageMetadata {
+	var (
+		titleTag string
+		ogTitle  string
+		siteName string
+		author   string
+	)
+
+	walk(root, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Title:
+			if titleTag == "" {
+				titleTag = collapseSpace(textContent(n))
+			}
+		case atom.Meta:
+			key := strings.ToLower(attr(n, "property"))
+			if key == "" {
+				key = strings.ToLower(attr(n, "name"))
+			}
+			value := collapseSpace(attr(n, "content"))
+			switch key {
+			case "og:title", "twitter:title":
+				if ogTitle == "" {
+					ogTitle = value
+				}
+			case "og:site_name":
+				siteName = value
+			case "author", "article:author", "byl":
+				if author == "" && !strings.HasPrefix(value, "http") {
+					author = value
+				}
+			}
+		case atom.Body:
+			return false
+		}
+		return true
+	})
+
+	title := ogTitle
+	if title == "" {
+		title = cleanPageTitle(titleTag, siteName)
+	}
+
+	return pageMetadata{title: title, author: author}
+}
+
+// cleanPageTitle strips a trailing or leading site name from a <title> value
+func cleanPageTitle(title, siteName string) string {
+	if siteName != "" {
+		for _, sep := range []string{" | ", " - ", " – ", " — ", " · "} {
+			title = strings.TrimSuffix(title, sep+siteName)
+			title = strings.TrimPrefix(title, siteName+sep)
+		}
+	}
+
+	parts := titleSeparators.Split(title, -1)
+	if len(parts) < 2 {
+		return title
+	}
+
+	// Keep the longest part when it still reads like a headline
+	longest := parts[0]
+	for _, p := range parts[1:] {
+		if len(p) > len(longest) {
+			longest = p
+		}
+	}
+	if len(strings.Fields(longest)) >= 3 {
+		return longest
+	}
+	return title
+}
+
+// pruneHTML removes elements that are never part of the main content
+func pruneHTML(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.CommentNode || (child.Type == html.ElementNode && isUnlikely(child)) {
+			n.RemoveChild(child)
+		} else {
+			pruneHTML(child)
+		}
+		child = next
+	}
+}
+
+// isUnlikely reports whether an element looks like page chrome
+func isUnlikely(n *html.Node) bool {
+	if htmlRemovedElements[n.DataAtom] {
+		return true
+	}
+
+	if _, hidden := attrLookup(n, "hidden"); hidden || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	switch strings.ToLower(attr(n, "role")) {
+	case "navigation", "banner", "complementary", "contentinfo", "dialog", "alertdialog", "menu":
+		return true
+	}
+
+	// Never drop the structural containers that usually hold the article
+	switch n.DataAtom {
+	case atom.Body, atom.Article, atom.Main, atom.Table, atom.Tbody, atom.Tr, atom.Td:
+		return false
+	}
+
+	hints := attr(n, "class") + " " + attr(n, "id")
+	return unlikelyCandidates.MatchString(hints) && !maybeCandidates.MatchString(hints)
+}
+
+// topCandidate scores content containers and returns the best one, or nil
+// when no element holds enough paragraph text to be an article
+func topCandidate(body *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node // in document order, so ties resolve deterministically
+
+	initialize := func(n *html.Node) {
+		if _, ok := scores[n]; !ok {
+			scores[n] = baseScore(n)
+			candidates = append(candidates, n)
+		}
+	}
+
+	walk(body, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		default:
+			return true
+		}
+
+		text := collapseSpace(textContent(n))
+		if len(text) < minParagraphLength {
+			return true
+		}
+
+		// One point for the paragraph, one per comma, one per 100 chars (max 3)
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+
+		if parent := n.Parent; parent != nil && parent.Type == html.ElementNode {
+			initialize(parent)
+			scores[parent] += score
+			if grand := parent.Parent; grand != nil && grand.Type == html.ElementNode {
+				initialize(grand)
+				scores[grand] += score / 2
+			}
+		}
+		return true
+	})
+
+	var (
+		best      *html.Node
+		bestScore float64
+	)
+	for _, n := range candidates {
+		score := scores[n] * (1 - linkDensity(n))
+		scores[n] = score
+		if best == nil || score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best == nil || bestScore < 5 {
+		return nil
+	}
+
+	// Articles split across sibling containers: pull in strong siblings
+	threshold := math.Max(10, bestScore*0.2)
+	parent := best.Parent
+	if parent == nil {
+		return best
+	}
+
+	wrapper := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	for sibling := parent.FirstChild; sibling != nil; {
+		next := sibling.NextSibling
+		if sibling == best || scores[sibling] >= threshold || isStrongParagraph(sibling) {
+			parent.RemoveChild(sibling)
+			wrapper.AppendChild(sibling)
+		}
+		sibling = next
+	}
+
+	return wrapper
+}
+
+// baseScore is a node's starting score from its tag and class/id hints
+func baseScore(n *html.Node) float64 {
+	var score float64
+	switch n.DataAtom {
+	case atom.Div, atom.Article, atom.Main, atom.Section:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+
+	for _, hint := range []string{attr(n, "class"), attr(n, "id")} {
+		if hint == "" {
+			continue
+		}
+		if negativeHints.MatchString(hint) {
+			score -= 25
+		}
+		if positiveHints.MatchString(hint) {
+			score += 25
+		}
+	}
+
+	return score
+}
+
+// isStrongParagraph reports whether a sibling <p> is long prose worth keeping
+func isStrongParagraph(n *html.Node) bool {
+	if n.DataAtom != atom.P {
+		return false
+	}
+	text := collapseSpace(textContent(n))
+	density := linkDensity(n)
+	if len(text) > 80 {
+		return density < 0.25
+	}
+	return len(text) > 0 && density == 0 && strings.ContainsAny(text, ".!?")
+}
+
+// linkDensity is the fraction of a node's text that sits inside links
+func linkDensity(n *html.Node) float64 {
+	total := len(collapseSpace(textContent(n)))
+	if total == 0 {
+		return 0
+	}
+
+	linked := 0
+	walk(n, func(c *html.Node) bool {
+		if c.DataAtom == atom.A {
+			linked += len(collapseSpace(textContent(c)))
+			return false
+		}
+		return true
+	})
+
+	return float64(linked) / float64(total)
+}
+
+// htmlBlocks flattens an element tree into paragraph-level blocks
+func htmlBlocks(root *html.Node) []block {
+	var (
+		blocks  []block
+		current strings.Builder
+		kind    = blockParagraph
+	)
+
+	flush := func() {
+		text := collapseSpace(current.String())
+		current.Reset()
+		if text != "" {
+			blocks = append(blocks, block{kind: kind, text: text})
+		}
+		kind = blockParagraph
+	}
+
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			current.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if n.DataAtom == atom.Br {
+				current.WriteByte(' ')
+				return
+			}
+		}
+
+		isBlock := n.Type == html.ElementNode && htmlBlockElements[n.DataAtom]
+		if isBlock {
+			flush()
+			switch {
+			case isHeading(n.Data):
+				kind = blockHeading
+			case n.DataAtom == atom.Li:
+				kind = blockListItem
+			}
+		}
+
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+
+		if isBlock {
+			flush()
+		}
+	}
+	visit(root)
+	flush()
+
+	return blocks
+}
+
+// sectionsFromBlocks groups blocks into sections, starting a new section at each heading
+func sectionsFromBlocks(blocks []block) []Section {
+	var (
+		sections []Section
+		current  Section
+	)
+
+	for _, b := range blocks {
+		if b.kind == blockHeading {
+			if current.Title != "" || len(current.Paragraphs) > 0 {
+				sections = append(sections, current)
+			}
+			current = Section{Title: b.text}
+			continue
+		}
+		current.Paragraphs = append(current.Paragraphs, b.text)
+	}
+	if current.Title != "" || len(current.Paragraphs) > 0 {
+		sections = append(sections, current)
+	}
+
+	return sections
+}
+
+// walk visits n and its descendants depth-first; fn returns false to skip children
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	if n.Type == html.ElementNode && !fn(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, fn)
+	}
+}
+
+// findElement returns the first element with the given tag
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	var found *html.Node
+	walk(n, func(c *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if c.DataAtom == a {
+			found = c
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// textContent concatenates all text beneath a node
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var visit func(*html.Node)
+	visit = func(c *html.Node) {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+			b.WriteByte(' ')
+			return
+		}
+		for child := c.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+	}
+	visit(n)
+	return b.String()
+}
+
+// attrLookup returns an attribute value and whether it is present
+func attrLookup(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && strings.EqualFold(a.Key, key) {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// attr returns an attribute value, or "" when absent
+func attr(n *html.Node, key string) string {
+	v, _ := attrLookup(n, key)
+	return v
+}
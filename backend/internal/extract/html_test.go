@@ -0,0 +1,104 @@
+package extract
+
+import (
+	"strings"
+	"testing"
+)
+
+const testArticlePage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Why Owls Read at Night | The Nocturnal Times</title>
+  <meta property="og:site_name" content="The Nocturnal Times">
+  <meta name="author" content="Wren Finch">
+  <script>var tracking = "ignore me";</script>
+</head>
+<body>
+  <header class="site-header"><a href="/">Home</a> <a href="/news">News</a></header>
+  <nav><ul><li><a href="/a">Section A</a></li><li><a href="/b">Section B</a></li></ul></nav>
+  <div id="cookie-banner">We use cookies to improve your experience. Accept all cookies?</div>
+  <div class="layout">
+    <div class="sidebar"><p>Subscribe to our newsletter for daily updates, offers, and more news.</p></div>
+    <article class="post-content">
+      <h1>Why Owls Read at Night</h1>
+      <p>Owls have long been associated with wisdom, and researchers now think they read by moonlight.</p>
+      <h2>The moonlight hypothesis</h2>
+      <p>According to the study, owls prefer long, quiet evenings, with few distractions and plenty of light.</p>
+      <p>Critics, however, point out that <a href="/owls">owls</a> cannot turn pages, which complicates things.</p>
+    </article>
+  </div>
+  <footer><p>Copyright 2026 The Nocturnal Times. All rights reserved, forever and ever.</p></footer>
+</body>
+</html>`
+
+func TestHTML_ExtractsArticle(t *testing.T) {
+	doc, err := HTML(strings.NewReader(testArticlePage), "text/html; charset=utf-8")
+	if err != nil {
+		t.Fatalf("HTML failed: %v", err)
+	}
+
+	if doc.Title != "Why Owls Read at Night" {
+		t.Errorf("expected site name stripped from title, got %q", doc.Title)
+	}
+	if doc.Author != "Wren Finch" {
+		t.Errorf("expected author 'Wren Finch', got %q", doc.Author)
+	}
+
+	text := doc.Text()
+	for _, unwanted := range []string{"cookies", "newsletter", "Copyright", "Section A", "tracking", "Home"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("expected %q to be removed, got text:\n%s", unwanted, text)
+		}
+	}
+
+	want := strings.Join([]string{
+		"Why Owls Read at Night",
+		"Owls have long been associated with wisdom, and researchers now think they read by moonlight.",
+		"The moonlight hypothesis",
+		"According to the study, owls prefer long, quiet evenings, with few distractions and plenty of light.",
+		"Critics, however, point out that owls cannot turn pages, which complicates things.",
+	}, "\n\n")
+	if text != want {
+		t.Errorf("unexpected article text:\n%s", text)
+	}
+
+	if len(doc.Sections) != 2 || doc.Sections[1].Title != "The moonlight hypothesis" {
+		t.Errorf("expected headings to start sections, got %+v", doc.Sections)
+	}
+}
+
+func TestHTML_PrefersOpenGraphTitle(t *testing.T) {
+	page := `<html><head><title>Home - Example</title><meta property="og:title" content="A Better Title"></head>
+		<body><p>Short page without much going on but with enough text to count.</p></body></html>`
+
+	doc, err := HTML(strings.NewReader(page), "")
+	if err != nil {
+		t.Fatalf("HTML failed: %v", err)
+	}
+	if doc.Title != "A Better Title" {
+		t.Errorf("expected og:title, got %q", doc.Title)
+	}
+	if !strings.Contains(doc.Text(), "Short page") {
+		t.Errorf("expected body text fallback, got %q", doc.Text())
+	}
+}
+
+func TestCleanPageTitle(t *testing.T) {
+	tests := []struct {
+		title    string
+		siteName string
+		want     string
+	}{
+		{"Story Title | Site", "Site", "Story Title"},
+		{"Site - Story Title", "Site", "Story Title"},
+		{"A Long Headline About Things - Example", "", "A Long Headline About Things"},
+		{"Short - Title", "", "Short - Title"},
+		{"Plain Title", "", "Plain Title"},
+	}
+
+	for _, tt := range tests {
+		if got := cleanPageTitle(tt.title, tt.siteName); got != tt.want {
+			t.Errorf("cleanPageTitle(%q, %q) = %q, want %q", tt.title, tt.siteName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package extract
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// XML namespaces used by WordprocessingML (.docx) and OpenDocument (.odt)
+const (
+	nsWord      = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	nsODFText   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	nsODFOffice = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+)
+
+// officeProperties is the title/author metadata shared by docProps/core.xml and meta.xml
+type officeProperties struct {
+	Titles         []string `xml:"title"`
+	Creators       []string `xml:"creator"`
+	InitialCreator []string `xml:"meta>initial-creator"`
+	ODFTitles      []string `xml:"meta>title"`
+	ODFCreators    []string `xml:"meta>creator"`
+}
+
+// DOCX extracts paragraphs, headings and list items from a Word document,
+// taking the title and author from the document properties
+func DOCX(r io.ReaderAt, size int64) (*Document, error) {
+	files, budget, err := openOfficeArchive(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := files["word/document.xml"]
+	if !ok {
+		return nil, fmt.Errorf("invalid docx: missing word/document.xml")
+	}
+	rc, err := budget.open(f)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	blocks, err := parseDOCXBody(rc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docx body: %w", err)
+	}
+
+	doc := &Document{Format: "docx", Sections: sectionsFromBlocks(blocks)}
+
+	var props officeProperties
+	if err := decodeZipXML(files, "docProps/core.xml", budget, &props); err == nil {
+		doc.Title = firstNonEmpty(props.Titles)
+		doc.Author = firstNonEmpty(props.Creators)
+	}
+
+	return doc, nil
+}
+
+// ODT extracts paragraphs, headings and list items from an OpenDocument text
+// file, taking the title and author from meta.xml
+func ODT(r io.ReaderAt, size int64) (*Document, error) {
+	files, budget, err := openOfficeArchive(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := files["content.xml"]
+	if !ok {
+		return nil, fmt.Errorf("invalid odt: missing content.xml")
+	}
+	rc, err := budget.open(f)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	blocks, err := parseODTBody(rc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid odt body: %w", err)
+	}
+
+	doc := &Document{Format: "odt", Sections: sectionsFromBlocks(blocks)}
+
+	var props officeProperties
+	if err := decodeZipXML(files, "meta.xml", budget, &props); err == nil {
+		doc.Title = firstNonEmpty(props.ODFTitles)
+		doc.Author = firstNonEmpty(props.ODFCreators)
+		if doc.Author == "" {
+			doc.Author = firstNonEmpty(props.InitialCreator)
+		}
+	}
+
+	return doc, nil
+}
+
+// openOfficeArchive indexes the entries of a zipped office document
+func openOfficeArchive(r io.ReaderAt, size int64) (map[string]*zip.File, *archiveBudget, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid document archive: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	return files, &archiveBudget{remaining: maxArchiveBytes}, nil
+}
+
+// docxParagraph is a w:p being read: its text so far and what kind of block
+// it becomes
+type docxParagraph struct {
+	text strings.Builder
+	kind blockKind
+}
+
+// maxDOCXOutlineLevel is the deepest outline level Word shows in the
+// navigation pane. Level 9 marks body text, and many templates set it on
+// ordinary paragraphs.
+const maxDOCXOutlineLevel = 8
+
+// parseDOCXBody walks word/document.xml, emitting one block per w:p.
+// Paragraphs can nest, as in text boxes (w:txbxContent), so each is read on
+// a stack and a nested one becomes its own block, ahead of the one around it.
+func parseDOCXBody(r io.Reader) ([]block, error) {
+	decoder := xml.NewDecoder(r)
+
+	var (
+		blocks []block
+		stack  []*docxParagraph
+		inText bool
+	)
+
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return blocks, nil
+			}
+			return nil, err
+		}
+
+		var para *docxParagraph
+		if len(stack) > 0 {
+			para = stack[len(stack)-1]
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space != nsWord {
+				continue
+			}
+			if t.Name.Local == "p" {
+				stack = append(stack, &docxParagraph{kind: blockParagraph})
+				continue
+			}
+			if para == nil {
+				continue
+			}
+			switch t.Name.Local {
+			case "pStyle":
+				style := strings.ToLower(xmlAttr(t, "val"))
+				if strings.HasPrefix(style, "heading") || style == "title" || style == "subtitle" {
+					para.kind = blockHeading
+				} else if strings.HasPrefix(style, "list") && para.kind == blockParagraph {
+					para.kind = blockListItem
+				}
+			case "outlineLvl":
+				if level, err := strconv.Atoi(xmlAttr(t, "val")); err == nil && level >= 0 && level <= maxDOCXOutlineLevel {
+					para.kind = blockHeading
+				}
+			case "numPr":
+				if para.kind == blockParagraph {
+					para.kind = blockListItem
+				}
+			case "t":
+				inText = true
+			case "tab", "br", "cr":
+				para.text.WriteByte(' ')
+			}
+		case xml.EndElement:
+			if t.Name.Space != nsWord {
+				continue
+			}
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if para == nil {
+					continue
+				}
+				if text := collapseSpace(para.text.String()); text != "" {
+					blocks = append(blocks, block{kind: para.kind, text: text})
+				}
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if para != nil && inText {
+				para.text.Write(t)
+			}
+		}
+	}
+}
+
+// parseODTBody walks content.xml, emitting one block per text:p or text:h
+func parseODTBody(r io.Reader) ([]block, error) {
+	decoder := xml.NewDecoder(r)
+
+	var (
+		blocks    []block
+		current   strings.Builder
+		kind      = blockParagraph
+		depth     int // nesting of text:p/text:h, > 0 while inside a paragraph
+		listDepth int
+		skip      int // inside notes or annotations
+		inBody    bool
+	)
+
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return blocks, nil
+			}
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space == nsODFOffice {
+				switch t.Name.Local {
+				case "text":
+					inBody = true
+				case "annotation":
+					skip++
+				}
+				continue
+			}
+			if t.Name.Space != nsODFText || !inBody {
+				continue
+			}
+			switch t.Name.Local {
+			case "note", "tracked-changes":
+				skip++
+			case "list":
+				listDepth++
+			case "p", "h":
+				if skip > 0 {
+					continue
+				}
+				depth++
+				if depth == 1 {
+					current.Reset()
+					switch {
+					case t.Name.Local == "h":
+						kind = blockHeading
+					case listDepth > 0:
+						kind = blockListItem
+					default:
+						kind = blockParagraph
+					}
+				}
+			case "s":
+				if skip == 0 && depth > 0 {
+					count, err := strconv.Atoi(xmlAttr(t, "c"))
+					if err != nil || count < 1 {
+						count = 1
+					}
+					current.WriteString(strings.Repeat(" ", count))
+				}
+			case "tab", "line-break":
+				if skip == 0 && depth > 0 {
+					current.WriteByte(' ')
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Space == nsODFOffice {
+				switch t.Name.Local {
+				case "text":
+					inBody = false
+				case "annotation":
+					skip--
+				}
+				continue
+			}
+			if t.Name.Space != nsODFText || !inBody {
+				continue
+			}
+			switch t.Name.Local {
+			case "note", "tracked-changes":
+				skip--
+			case "list":
+				listDepth--
+			case "p", "h":
+				if skip > 0 {
+					continue
+				}
+				depth--
+				if depth == 0 {
+					if text := collapseSpace(current.String()); text != "" {
+						blocks = append(blocks, block{kind: kind, text: text})
+					}
+					current.Reset()
+				}
+			}
+		case xml.CharData:
+			if skip == 0 && depth > 0 {
+				current.Write(t)
+			}
+		}
+	}
+}
+
+// xmlAttr returns the value of an attribute by local name
+func xmlAttr(el xml.StartElement, local string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// firstNonEmpty returns the first value that isn't blank
+func firstNonEmpty(values []string) string {
+	for _, v := range values {
+		if v = collapseSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
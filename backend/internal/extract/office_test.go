@@ -0,0 +1,170 @@
+package extract
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDOCXBody = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    <w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Quarterly</w:t></w:r><w:r><w:t xml:space="preserve"> Report</w:t></w:r></w:p>
+    <w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Revenue grew</w:t></w:r><w:r><w:tab/><w:t>this quarter.</w:t></w:r></w:p>
+    <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>First point</w:t></w:r></w:p>
+    <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Second point</w:t></w:r></w:p>
+    <w:p><w:r><w:delText>removed</w:delText><w:instrText>PAGE</w:instrText></w:r></w:p>
+    <w:tbl><w:tr><w:tc><w:p><w:r><w:t>Cell text</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+    <w:sectPr/>
+  </w:body>
+</w:document>`
+
+const testDOCXCore = `<?xml version="1.0" encoding="UTF-8"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties"
+  xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <dc:title>Q3 Results</dc:title>
+  <dc:creator>Finance Team</dc:creator>
+</cp:coreProperties>`
+
+func TestDOCX(t *testing.T) {
+	r := buildZip(t, map[string]string{
+		"[Content_Types].xml": `<Types/>`,
+		"word/document.xml":   testDOCXBody,
+		"docProps/core.xml":   testDOCXCore,
+	})
+
+	doc, err := File("report.docx", r, r.Size())
+	if err != nil {
+		t.Fatalf("File failed: %v", err)
+	}
+
+	if doc.Title != "Q3 Results" || doc.Author != "Finance Team" {
+		t.Errorf("unexpected metadata: title=%q author=%q", doc.Title, doc.Author)
+	}
+
+	want := "Quarterly Report\n\nRevenue grew this quarter.\n\nFirst point\n\nSecond point\n\nCell text"
+	if got := doc.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if len(doc.Sections) != 1 || doc.Sections[0].Title != "Quarterly Report" {
+		t.Errorf("expected heading to title the section, got %+v", doc.Sections)
+	}
+}
+
+const testODTContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+  xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+  <office:body>
+    <office:text>
+      <text:h text:outline-level="1">Meeting Notes</text:h>
+      <text:p>We met<text:s text:c="3"/>on <text:span>Tuesday</text:span>.<text:note><text:note-body><text:p>A footnote.</text:p></text:note-body></text:note></text:p>
+      <text:list>
+        <text:list-item><text:p>Budget</text:p></text:list-item>
+        <text:list-item><text:p>Hiring</text:p></text:list-item>
+      </text:list>
+      <text:h text:outline-level="2">Next Steps</text:h>
+      <text:p>Follow up<text:line-break/>next week.</text:p>
+    </office:text>
+  </office:body>
+</office:document-content>`
+
+const testODTMeta = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-meta xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+  xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <office:meta>
+    <dc:title>Team Sync</dc:title>
+    <meta:initial-creator>Sam Reader</meta:initial-creator>
+  </office:meta>
+</office:document-meta>`
+
+func TestODT(t *testing.T) {
+	r := buildZip(t, map[string]string{
+		"mimetype":    "application/vnd.oasis.opendocument.text",
+		"content.xml": testODTContent,
+		"meta.xml":    testODTMeta,
+	})
+
+	doc, err := File("notes.odt", r, r.Size())
+	if err != nil {
+		t.Fatalf("File failed: %v", err)
+	}
+
+	if doc.Title != "Team Sync" || doc.Author != "Sam Reader" {
+		t.Errorf("unexpected metadata: title=%q author=%q", doc.Title, doc.Author)
+	}
+
+	want := "Meeting Notes\n\nWe met on Tuesday.\n\nBudget\n\nHiring\n\nNext Steps\n\nFollow up next week."
+	if got := doc.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if len(doc.Sections) != 2 {
+		t.Errorf("expected 2 sections, got %d", len(doc.Sections))
+	}
+}
+
+func TestDOCX_MissingBody(t *testing.T) {
+	r := buildZip(t, map[string]string{"docProps/core.xml": testDOCXCore})
+
+	if _, err := DOCX(r, r.Size()); err == nil {
+		t.Error("expected error for docx without word/document.xml")
+	}
+}
+
+const testDOCXOutlineBody = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    <w:p><w:pPr><w:outlineLvl w:val="0"/></w:pPr><w:r><w:t>Overview</w:t></w:r></w:p>
+    <w:p><w:pPr><w:outlineLvl w:val="9"/></w:pPr><w:r><w:t>Body text at level nine.</w:t></w:r></w:p>
+    <w:p><w:pPr><w:outlineLvl w:val="8"/></w:pPr><w:r><w:t>Details</w:t></w:r></w:p>
+    <w:p><w:r><w:t>More body text.</w:t></w:r></w:p>
+  </w:body>
+</w:document>`
+
+func TestDOCX_OutlineLevels(t *testing.T) {
+	r := buildZip(t, map[string]string{"word/document.xml": testDOCXOutlineBody})
+
+	doc, err := DOCX(r, r.Size())
+	if err != nil {
+		t.Fatalf("DOCX failed: %v", err)
+	}
+
+	// Level 9 is body text, so only levels 0 and 8 start sections
+	if len(doc.Sections) != 2 || doc.Sections[0].Title != "Overview" || doc.Sections[1].Title != "Details" {
+		t.Errorf("expected sections Overview and Details, got %+v", doc.Sections)
+	}
+}
+
+const testDOCXTextBoxBody = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:v="urn:schemas-microsoft-com:vml">
+  <w:body>
+    <w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Field</w:t></w:r><w:r><w:pict><v:shape><v:textbox><w:txbxContent>
+      <w:p><w:r><w:t>Boxed note</w:t></w:r></w:p>
+    </w:txbxContent></v:textbox></v:shape></w:pict></w:r><w:r><w:t xml:space="preserve"> Guide</w:t></w:r></w:p>
+    <w:p><w:r><w:t>Before the box</w:t></w:r><w:r><w:pict><v:shape><v:textbox><w:txbxContent>
+      <w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Sidebar</w:t></w:r></w:p>
+    </w:txbxContent></v:textbox></v:shape></w:pict></w:r><w:r><w:t xml:space="preserve"> and after it.</w:t></w:r></w:p>
+  </w:body>
+</w:document>`
+
+func TestDOCX_TextBoxParagraphs(t *testing.T) {
+	r := buildZip(t, map[string]string{"word/document.xml": testDOCXTextBoxBody})
+
+	doc, err := DOCX(r, r.Size())
+	if err != nil {
+		t.Fatalf("DOCX failed: %v", err)
+	}
+
+	// A text box's paragraphs come out on their own, and the paragraph
+	// around one keeps both its text and its style
+	want := "Boxed note\n\nField Guide\n\nSidebar\n\nBefore the box and after it."
+	if got := doc.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	var titles []string
+	for _, s := range doc.Sections {
+		titles = append(titles, s.Title)
+	}
+	if strings.Join(titles, "|") != "|Field Guide|Sidebar" {
+		t.Errorf("expected sections untitled, Field Guide and Sidebar, got %q", titles)
+	}
+}
@@ -0,0 +1,732 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxFormDepth bounds nesting of form XObjects drawn from content streams
+	maxFormDepth = 8
+
+	// maxContentOperators bounds the total operators interpreted per file, since
+	// form XObjects can be drawn repeatedly from each other
+	maxContentOperators = 5_000_000
+
+	// headingSizeRatio is how much larger than body text a line must be to read as a heading
+	headingSizeRatio = 1.15
+)
+
+var (
+	// pageNumberPattern matches lines that are only a page number, e.g. "12", "- 12 -", "xiv" or "Page 3 of 10"
+	pageNumberPattern = regexp.MustCompile(`(?i)^(page\s+)?[-–—(\[]?\s*(\d{1,4}|[ivxlcdm]{1,7})\s*[-–—)\]]?(\s*(of|/)\s*\d{1,4})?$`)
+
+	// pdfLigatures expands presentation-form ligatures left behind by some fonts
+	pdfLigatures = strings.NewReplacer("\ufb00", "ff", "\ufb01", "fi", "\ufb02", "fl", "\ufb03", "ffi", "\ufb04", "ffl", "\ufb05", "st", "\ufb06", "st")
+)
+
+// PDF extracts the text layer of a PDF. Lines are reassembled into paragraphs,
+// words hyphenated across line breaks are rejoined, and running headers,
+// footers and page numbers are dropped. Pages that carry only images fail
+// with ErrScannedPDF.
+func PDF(r io.ReaderAt, size int64) (*Document, error) {
+	data := make([]byte, size)
+	if _, err := r.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read pdf: %w", err)
+	}
+
+	file, err := parsePDF(data)
+	if err != nil {
+		return nil, err
+	}
+	if file.trailer["Encrypt"] != nil {
+		return nil, ErrEncrypted
+	}
+
+	pages := file.pages()
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("invalid pdf: no pages found")
+	}
+
+	content := &pdfContent{file: file, fonts: make(map[pdfRef]*pdfFont)}
+	pageLines := make([][]pdfLine, 0, len(pages))
+	for _, page := range pages {
+		content.spans = content.spans[:0]
+		content.run(file.pageContents(page.dict), page.resources, newPDFGraphicsState(), 0)
+		if content.operators > maxContentOperators {
+			return nil, errPDFTooLarge
+		}
+		pageLines = append(pageLines, buildPDFLines(content.spans))
+	}
+
+	blocks := pdfBlocks(dropPageFurniture(pageLines))
+	if len(blocks) == 0 {
+		if content.images > 0 {
+			return nil, ErrScannedPDF
+		}
+		return nil, ErrNoText
+	}
+
+	doc := &Document{Format: "pdf", Sections: sectionsFromBlocks(blocks)}
+	info := file.dict(file.trailer["Info"])
+	if title, ok := file.resolve(info["Title"]).([]byte); ok {
+		doc.Title = cleanPDFTitle(decodePDFText(title))
+	}
+	if author, ok := file.resolve(info["Author"]).([]byte); ok {
+		doc.Author = collapseSpace(decodePDFText(author))
+	}
+
+	return doc, nil
+}
+
+// cleanPDFTitle discards document-info titles that are really file names,
+// which many PDF producers write by default
+func cleanPDFTitle(title string) string {
+	title = collapseSpace(strings.TrimPrefix(title, "Microsoft Word - "))
+	lower := strings.ToLower(title)
+	for _, ext := range []string{".doc", ".docx", ".pdf", ".tex", ".dvi", ".indd", ".odt", ".rtf"} {
+		if strings.HasSuffix(lower, ext) {
+			return ""
+		}
+	}
+	if lower == "untitled" {
+		return ""
+	}
+	return title
+}
+
+// pdfPage is a leaf of the page tree with its inherited resources
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages walks the page tree in reading order. Files whose tree is missing or
+// broken fall back to every page object in object-number order.
+func (f *pdfFile) pages() []pdfPage {
+	var pages []pdfPage
+	seen := make(map[pdfRef]bool)
+
+	var visit func(v any, resources pdfDict, depth int)
+	visit = func(v any, resources pdfDict, depth int) {
+		if ref, ok := v.(pdfRef); ok {
+			if seen[ref] {
+				return
+			}
+			seen[ref] = true
+		}
+		node := f.dict(v)
+		if node == nil || depth > maxPDFNesting {
+			return
+		}
+		if res := f.dict(node["Resources"]); res != nil {
+			resources = res
+		}
+		if kids := f.array(node["Kids"]); kids != nil || f.name(node["Type"]) == "Pages" {
+			for _, kid := range kids {
+				visit(kid, resources, depth+1)
+			}
+			return
+		}
+		pages = append(pages, pdfPage{dict: node, resources: resources})
+	}
+
+	root := f.dict(f.trailer["Root"])
+	visit(root["Pages"], nil, 0)
+	if len(pages) > 0 {
+		return pages
+	}
+
+	for _, num := range f.objectNumbers() {
+		node, ok := f.objects[num].(pdfDict)
+		if !ok || f.name(node["Type"]) != "Page" {
+			continue
+		}
+		resources := f.dict(node["Resources"])
+		if resources == nil {
+			resources = f.dict(f.dict(node["Parent"])["Resources"])
+		}
+		pages = append(pages, pdfPage{dict: node, resources: resources})
+	}
+	return pages
+}
+
+// pageContents concatenates a page's content streams
+func (f *pdfFile) pageContents(page pdfDict) []byte {
+	var streams []any
+	switch v := f.resolve(page["Contents"]).(type) {
+	case *pdfStream:
+		streams = []any{v}
+	case pdfArray:
+		streams = v
+	}
+
+	var buf bytes.Buffer
+	for _, item := range streams {
+		stream, ok := f.resolve(item).(*pdfStream)
+		if !ok {
+			continue
+		}
+		if data, err := f.decode(stream); err == nil {
+			buf.Write(data)
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// pdfMatrix is an affine transform [a b c d e f]
+type pdfMatrix [6]float64
+
+var identityMatrix = pdfMatrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m × n, i.e. m applied first
+func (m pdfMatrix) mul(n pdfMatrix) pdfMatrix {
+	return pdfMatrix{
+		m[0]*n[0] + m[1]*n[2], m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2], m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4], m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func translation(tx, ty float64) pdfMatrix {
+	return pdfMatrix{1, 0, 0, 1, tx, ty}
+}
+
+// pdfGraphicsState is the subset of graphics and text state that affects where text lands
+type pdfGraphicsState struct {
+	ctm       pdfMatrix
+	font      *pdfFont
+	size      float64
+	charSpace float64
+	wordSpace float64
+	scale     float64 // horizontal scaling, 1 = 100%
+	leading   float64
+	rise      float64
+}
+
+func newPDFGraphicsState() pdfGraphicsState {
+	return pdfGraphicsState{ctm: identityMatrix, scale: 1}
+}
+
+// pdfSpan is a run of text shown by a single string operand
+type pdfSpan struct {
+	text       string
+	x, y, endX float64
+	size       float64
+}
+
+// pdfContent interprets content streams, collecting text spans
+type pdfContent struct {
+	file      *pdfFile
+	fonts     map[pdfRef]*pdfFont
+	spans     []pdfSpan
+	images    int
+	operators int
+}
+
+func (c *pdfContent) run(data []byte, resources pdfDict, gs pdfGraphicsState, depth int) {
+	p := &pdfParser{data: data}
+
+	var (
+		operands []any
+		stack    []pdfGraphicsState
+		tm, tlm  = identityMatrix, identityMatrix
+	)
+
+	nextLine := func(tx, ty float64) {
+		tlm = translation(tx, ty).mul(tlm)
+		tm = tlm
+	}
+
+	for c.operators <= maxContentOperators {
+		v, err := p.parse(0)
+		if err != nil {
+			return
+		}
+		op, ok := v.(pdfKeyword)
+		if !ok {
+			if len(operands) < 64 {
+				operands = append(operands, v)
+			}
+			continue
+		}
+		c.operators++
+
+		switch op {
+		case "q":
+			stack = append(stack, gs)
+		case "Q":
+			if n := len(stack); n > 0 {
+				gs, stack = stack[n-1], stack[:n-1]
+			}
+		case "cm":
+			if m, ok := c.numbers(operands, 6); ok {
+				gs.ctm = pdfMatrix(m).mul(gs.ctm)
+			}
+		case "BT":
+			tm, tlm = identityMatrix, identityMatrix
+		case "Tf":
+			if len(operands) >= 2 {
+				gs.font = c.font(resources, c.file.name(operands[len(operands)-2]))
+				gs.size, _ = c.file.number(operands[len(operands)-1])
+			}
+		case "Tc":
+			if v, ok := c.numbers(operands, 1); ok {
+				gs.charSpace = v[0]
+			}
+		case "Tw":
+			if v, ok := c.numbers(operands, 1); ok {
+				gs.wordSpace = v[0]
+			}
+		case "Tz":
+			if v, ok := c.numbers(operands, 1); ok {
+				gs.scale = v[0] / 100
+			}
+		case "TL":
+			if v, ok := c.numbers(operands, 1); ok {
+				gs.leading = v[0]
+			}
+		case "Ts":
+			if v, ok := c.numbers(operands, 1); ok {
+				gs.rise = v[0]
+			}
+		case "Td":
+			if v, ok := c.numbers(operands, 2); ok {
+				nextLine(v[0], v[1])
+			}
+		case "TD":
+			if v, ok := c.numbers(operands, 2); ok {
+				gs.leading = -v[1]
+				nextLine(v[0], v[1])
+			}
+		case "Tm":
+			if m, ok := c.numbers(operands, 6); ok {
+				tlm = pdfMatrix(m)
+				tm = tlm
+			}
+		case "T*":
+			nextLine(0, -gs.leading)
+		case "Tj", "'", "\"":
+			if op == "\"" && len(operands) >= 3 {
+				if v, ok := c.numbers(operands[:len(operands)-1], 2); ok {
+					gs.wordSpace, gs.charSpace = v[0], v[1]
+				}
+			}
+			if op != "Tj" {
+				nextLine(0, -gs.leading)
+			}
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].([]byte); ok {
+					c.show(&gs, &tm, s)
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				arr, _ := operands[len(operands)-1].(pdfArray)
+				for _, item := range arr {
+					if s, ok := item.([]byte); ok {
+						c.show(&gs, &tm, s)
+					} else if adjust, ok := c.file.number(item); ok {
+						tm = translation(-adjust/1000*gs.size*gs.scale, 0).mul(tm)
+					}
+				}
+			}
+		case "Do":
+			if len(operands) > 0 {
+				c.xobject(resources, c.file.name(operands[len(operands)-1]), gs, depth)
+			}
+		case "BI":
+			c.images++
+			skipInlineImage(p)
+		}
+		operands = operands[:0]
+	}
+}
+
+// numbers returns the last n operands as numbers
+func (c *pdfContent) numbers(operands []any, n int) ([]float64, bool) {
+	if len(operands) < n {
+		return nil, false
+	}
+	out := make([]float64, n)
+	for i, o := range operands[len(operands)-n:] {
+		num, ok := c.file.number(o)
+		if !ok {
+			return nil, false
+		}
+		out[i] = num
+	}
+	return out, true
+}
+
+// show advances the text matrix over a string and records it as a span
+func (c *pdfContent) show(gs *pdfGraphicsState, tm *pdfMatrix, s []byte) {
+	if gs.font == nil {
+		return
+	}
+
+	render := pdfMatrix{gs.size * gs.scale, 0, 0, gs.size, 0, gs.rise}
+	start := render.mul(*tm).mul(gs.ctm)
+
+	var text strings.Builder
+	for _, g := range gs.font.decode(s) {
+		text.WriteString(g.text)
+		tx := g.width*gs.size + gs.charSpace
+		if g.space {
+			tx += gs.wordSpace
+		}
+		*tm = translation(tx*gs.scale, 0).mul(*tm)
+	}
+	if text.Len() == 0 {
+		return
+	}
+
+	end := render.mul(*tm).mul(gs.ctm)
+	c.spans = append(c.spans, pdfSpan{
+		text: text.String(),
+		x:    start[4],
+		y:    start[5],
+		endX: end[4],
+		size: math.Hypot(start[2], start[3]),
+	})
+}
+
+func (c *pdfContent) font(resources pdfDict, name pdfName) *pdfFont {
+	ref := c.file.dict(resources["Font"])[name]
+	if r, ok := ref.(pdfRef); ok {
+		if font, ok := c.fonts[r]; ok {
+			return font
+		}
+		font := c.file.loadFont(r)
+		c.fonts[r] = font
+		return font
+	}
+	return c.file.loadFont(ref)
+}
+
+// xobject draws a form XObject's content, or counts an image
+func (c *pdfContent) xobject(resources pdfDict, name pdfName, gs pdfGraphicsState, depth int) {
+	stream, ok := c.file.resolve(c.file.dict(resources["XObject"])[name]).(*pdfStream)
+	if !ok {
+		return
+	}
+
+	switch c.file.name(stream.dict["Subtype"]) {
+	case "Image":
+		c.images++
+	case "Form":
+		if depth >= maxFormDepth {
+			return
+		}
+		data, err := c.file.decode(stream)
+		if err != nil {
+			return
+		}
+		if m := c.file.array(stream.dict["Matrix"]); len(m) == 6 {
+			var matrix pdfMatrix
+			for i := range matrix {
+				matrix[i], _ = c.file.number(m[i])
+			}
+			gs.ctm = matrix.mul(gs.ctm)
+		}
+		formResources := c.file.dict(stream.dict["Resources"])
+		if formResources == nil {
+			formResources = resources
+		}
+		c.run(data, formResources, gs, depth+1)
+	}
+}
+
+// skipInlineImage moves past BI ... ID <binary> EI
+func skipInlineImage(p *pdfParser) {
+	for {
+		v, err := p.parse(0)
+		if err != nil {
+			return
+		}
+		if kw, ok := v.(pdfKeyword); ok && kw == "ID" {
+			break
+		}
+	}
+	p.pos++ // single whitespace after ID
+
+	for p.pos < len(p.data) {
+		idx := bytes.Index(p.data[p.pos:], []byte("EI"))
+		if idx < 0 {
+			p.pos = len(p.data)
+			return
+		}
+		at := p.pos + idx
+		p.pos = at + 2
+		if at > 0 && isPDFSpace(p.data[at-1]) && (p.pos == len(p.data) || isPDFSpace(p.data[p.pos])) {
+			return
+		}
+	}
+}
+
+// pdfLine is a row of spans sharing a baseline
+type pdfLine struct {
+	text       string
+	x, y, endX float64
+	size       float64
+}
+
+// buildPDFLines joins consecutive spans on the same baseline into lines,
+// inserting a space wherever the gap between spans is wide enough to be one
+func buildPDFLines(spans []pdfSpan) []pdfLine {
+	var lines []pdfLine
+	for _, s := range spans {
+		if n := len(lines); n > 0 {
+			l := &lines[n-1]
+			tolerance := math.Max(l.size, s.size) * 0.5
+			if math.Abs(s.y-l.y) < tolerance && s.x > l.endX-tolerance {
+				gap := s.x - l.endX
+				if gap > math.Min(l.size, s.size)*0.15 && !strings.HasSuffix(l.text, " ") && !strings.HasPrefix(s.text, " ") {
+					l.text += " "
+				}
+				l.text += s.text
+				l.endX = math.Max(l.endX, s.endX)
+				l.size = math.Max(l.size, s.size)
+				continue
+			}
+		}
+		lines = append(lines, pdfLine{text: s.text, x: s.x, y: s.y, endX: s.endX, size: s.size})
+	}
+
+	out := lines[:0]
+	for _, l := range lines {
+		if l.text = collapseSpace(pdfLigatures.Replace(l.text)); l.text != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// dropPageFurniture removes page numbers and running headers and footers.
+// Only the top and bottom two lines of each page are candidates; a candidate
+// is dropped when it is a bare page number or when the same text, ignoring
+// digits, recurs at the edge of enough pages.
+func dropPageFurniture(pages [][]pdfLine) [][]pdfLine {
+	type position struct{ page, line int }
+
+	drop := make(map[position]bool)
+	recurring := make(map[string][]position)
+	for pi, lines := range pages {
+		for _, li := range edgeLines(lines) {
+			text := lines[li].text
+			if pageNumberPattern.MatchString(text) {
+				drop[position{pi, li}] = true
+				continue
+			}
+			key := furnitureKey(text)
+			recurring[key] = append(recurring[key], position{pi, li})
+		}
+	}
+
+	minRepeat := max(2, int(math.Ceil(float64(len(pages))*0.4)))
+	for _, positions := range recurring {
+		distinct := make(map[int]bool)
+		for _, pos := range positions {
+			distinct[pos.page] = true
+		}
+		if len(distinct) >= minRepeat {
+			for _, pos := range positions {
+				drop[pos] = true
+			}
+		}
+	}
+
+	out := make([][]pdfLine, len(pages))
+	for pi, lines := range pages {
+		for li, line := range lines {
+			if !drop[position{pi, li}] {
+				out[pi] = append(out[pi], line)
+			}
+		}
+	}
+	return out
+}
+
+// edgeLines returns the indices of the two highest and two lowest lines on a page
+func edgeLines(lines []pdfLine) []int {
+	order := make([]int, len(lines))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return lines[order[a]].y > lines[order[b]].y })
+	if len(order) <= 4 {
+		return order
+	}
+	return append(order[:2:2], order[len(order)-2:]...)
+}
+
+func furnitureKey(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return '#'
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
+
+// pdfBlocks reassembles lines into paragraphs and headings using the page
+// geometry: vertical gaps, changes in font size, short closing lines and
+// first-line indents all start a new paragraph
+func pdfBlocks(pages [][]pdfLine) []block {
+	bodySize := pdfBodySize(pages)
+	lineGap := pdfLineGap(pages, bodySize)
+
+	var (
+		blocks []block
+		para   strings.Builder
+		size   float64
+	)
+	flush := func() {
+		text := collapseSpace(strings.ReplaceAll(para.String(), "\u00ad", ""))
+		if text != "" {
+			kind := blockParagraph
+			if size >= bodySize*headingSizeRatio && len(text) <= 150 {
+				kind = blockHeading
+			}
+			blocks = append(blocks, block{kind: kind, text: text})
+		}
+		para.Reset()
+	}
+
+	var prev *pdfLine
+	var prevEdge float64
+	for _, lines := range pages {
+		edges := columnEdges(lines)
+		for i := range lines {
+			line := &lines[i]
+			if prev != nil && startsParagraph(prev, prevEdge, line, i > 0, lineGap) {
+				flush()
+			}
+			if para.Len() == 0 {
+				para.WriteString(line.text)
+				size = line.size
+			} else {
+				joined := joinPDFLines(para.String(), line.text)
+				para.Reset()
+				para.WriteString(joined)
+			}
+			prev, prevEdge = line, edges[i]
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// startsParagraph decides whether line begins a new paragraph after prev.
+// samePage is false for the first line of a page, where vertical gaps and
+// indents carry no meaning relative to the previous page.
+func startsParagraph(prev *pdfLine, prevEdge float64, line *pdfLine, samePage bool, lineGap float64) bool {
+	if math.Abs(line.size-prev.size) > 0.15*math.Max(line.size, prev.size) {
+		return true
+	}
+
+	width := prevEdge - prev.x
+	if width > 0 {
+		short := prevEdge - prev.endX
+		if short > 0.5*width || (short > 0.1*width && endsSentence(prev.text)) {
+			return true
+		}
+	}
+
+	if samePage {
+		gap := prev.y - line.y
+		if lineGap > 0 && gap > lineGap*1.5 {
+			return true
+		}
+		if gap > 0 && line.x-prev.x > line.size {
+			return true
+		}
+	}
+
+	return false
+}
+
+func endsSentence(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return strings.ContainsRune(".!?:;\"”’)", r)
+}
+
+// joinPDFLines appends a line to paragraph text, rejoining words that were
+// hyphenated across the line break
+func joinPDFLines(text, next string) string {
+	last, size := utf8.DecodeLastRuneInString(text)
+	if last == '\u00ad' {
+		return text[:len(text)-size] + next
+	}
+	if last == '-' || last == '\u2010' {
+		before, _ := utf8.DecodeLastRuneInString(text[:len(text)-size])
+		first, _ := utf8.DecodeRuneInString(next)
+		if unicode.IsLetter(before) && unicode.IsLower(first) {
+			return text[:len(text)-size] + next
+		}
+	}
+	return text + " " + next
+}
+
+// columnEdges estimates the right margin for each line as the furthest extent
+// of lines on the page starting near the same x, so short closing lines can
+// be told apart in both single and multi-column layouts
+func columnEdges(lines []pdfLine) []float64 {
+	edges := make([]float64, len(lines))
+	for i, line := range lines {
+		edges[i] = line.endX
+		if len(lines) > 2000 {
+			continue
+		}
+		for _, other := range lines {
+			if math.Abs(other.x-line.x) < 3*line.size && other.endX > edges[i] {
+				edges[i] = other.endX
+			}
+		}
+	}
+	return edges
+}
+
+// pdfBodySize returns the font size carrying the most characters
+func pdfBodySize(pages [][]pdfLine) float64 {
+	counts := make(map[float64]int)
+	for _, lines := range pages {
+		for _, line := range lines {
+			counts[math.Round(line.size*2)/2] += len(line.text)
+		}
+	}
+	var best float64
+	for size, count := range counts {
+		if count > counts[best] || (count == counts[best] && size < best) {
+			best = size
+		}
+	}
+	return best
+}
+
+// pdfLineGap returns the median baseline distance between consecutive body lines
+func pdfLineGap(pages [][]pdfLine, bodySize float64) float64 {
+	var gaps []float64
+	for _, lines := range pages {
+		for i := 1; i < len(lines); i++ {
+			gap := lines[i-1].y - lines[i].y
+			if gap > 0 && gap < 3*bodySize && math.Abs(lines[i].size-bodySize) < 0.15*bodySize {
+				gaps = append(gaps, gap)
+			}
+		}
+	}
+	if len(gaps) == 0 {
+		return 0
+	}
+	sort.Float64s(gaps)
+	return gaps[len(gaps)/2]
+}
@@ -0,0 +1,417 @@
+package extract
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxCMapEntries bounds how many codes a single ToUnicode CMap may define
+const maxCMapEntries = 1 << 17
+
+// pdfFont maps the character codes in shown strings to text and glyph widths
+type pdfFont struct {
+	composite    bool // Type0 font with multi-byte codes
+	toUnicode    *pdfCMap
+	encoding     [256]string // simple fonts only
+	widths       map[int]float64
+	defaultWidth float64
+	scale        float64 // glyph space to text space, normally 1/1000
+}
+
+// pdfGlyph is one decoded character code
+type pdfGlyph struct {
+	text  string
+	width float64 // advance in text space units before scaling by font size
+	space bool    // single-byte code 32, which receives word spacing
+}
+
+func (f *pdfFile) loadFont(v any) *pdfFont {
+	d := f.dict(v)
+	if d == nil {
+		return nil
+	}
+
+	font := &pdfFont{widths: make(map[int]float64), scale: 0.001}
+	if m := f.array(d["FontMatrix"]); len(m) == 6 {
+		if a, ok := f.number(m[0]); ok && a != 0 {
+			font.scale = a
+		}
+	}
+	if stream, ok := f.resolve(d["ToUnicode"]).(*pdfStream); ok {
+		if data, err := f.decode(stream); err == nil {
+			font.toUnicode = parseCMap(data)
+		}
+	}
+
+	if f.name(d["Subtype"]) == "Type0" {
+		font.composite = true
+		font.defaultWidth = 1000
+		if descendants := f.array(d["DescendantFonts"]); len(descendants) > 0 {
+			desc := f.dict(descendants[0])
+			if dw, ok := f.number(desc["DW"]); ok {
+				font.defaultWidth = dw
+			}
+			f.loadCIDWidths(font, f.array(desc["W"]))
+		}
+		return font
+	}
+
+	font.encoding = f.simpleEncoding(d)
+	first, _ := f.integer(d["FirstChar"])
+	for i, w := range f.array(d["Widths"]) {
+		if width, ok := f.number(w); ok {
+			font.widths[first+i] = width
+		}
+	}
+	if len(font.widths) == 0 {
+		// Standard 14 fonts may omit widths; assume an average glyph
+		font.defaultWidth = 500
+		if strings.Contains(string(f.name(d["BaseFont"])), "Courier") {
+			font.defaultWidth = 600
+		}
+	} else if mw, ok := f.number(f.dict(d["FontDescriptor"])["MissingWidth"]); ok {
+		font.defaultWidth = mw
+	}
+
+	return font
+}
+
+// loadCIDWidths reads a CID font W array, which mixes "c [w1 w2 ...]" and
+// "cfirst clast w" entries
+func (f *pdfFile) loadCIDWidths(font *pdfFont, w pdfArray) {
+	for i := 0; i < len(w); {
+		start, ok := f.integer(w[i])
+		if !ok || i+1 >= len(w) {
+			return
+		}
+		if list := f.array(w[i+1]); list != nil {
+			for j, item := range list {
+				if width, ok := f.number(item); ok {
+					font.widths[start+j] = width
+				}
+			}
+			i += 2
+			continue
+		}
+		end, ok1 := f.integer(w[i+1])
+		if i+2 >= len(w) || !ok1 || end-start > maxCMapEntries {
+			return
+		}
+		if width, ok := f.number(w[i+2]); ok {
+			for c := start; c <= end; c++ {
+				font.widths[c] = width
+			}
+		}
+		i += 3
+	}
+}
+
+// simpleEncoding builds the code-to-text table for a single-byte font from its
+// base encoding and Differences array
+func (f *pdfFile) simpleEncoding(d pdfDict) [256]string {
+	base := charmap.Windows1252
+	var differences pdfArray
+	switch enc := f.resolve(d["Encoding"]).(type) {
+	case pdfName:
+		if enc == "MacRomanEncoding" {
+			base = charmap.Macintosh
+		}
+	case pdfDict:
+		if f.name(enc["BaseEncoding"]) == "MacRomanEncoding" {
+			base = charmap.Macintosh
+		}
+		differences = f.array(enc["Differences"])
+	}
+
+	var table [256]string
+	for i := 32; i < 256; i++ {
+		if r := base.DecodeByte(byte(i)); r != utf8.RuneError && !unicode.IsControl(r) {
+			table[i] = string(r)
+		}
+	}
+
+	code := -1
+	for _, item := range differences {
+		switch v := f.resolve(item).(type) {
+		case int:
+			code = v
+		case pdfName:
+			if code >= 0 && code < 256 {
+				table[code] = glyphText(string(v))
+			}
+			code++
+		}
+	}
+
+	return table
+}
+
+// decode splits a shown string into glyphs
+func (font *pdfFont) decode(s []byte) []pdfGlyph {
+	glyphs := make([]pdfGlyph, 0, len(s))
+	for i := 0; i < len(s); {
+		n := 1
+		if font.toUnicode != nil && len(font.toUnicode.ranges) > 0 {
+			if l := font.toUnicode.codeLength(s[i:]); l > 0 {
+				n = l
+			} else if font.composite {
+				n = 2
+			}
+		} else if font.composite {
+			n = 2
+		}
+		n = min(n, len(s)-i)
+
+		code := bigEndian(s[i : i+n])
+		g := pdfGlyph{space: n == 1 && code == 32}
+		if w, ok := font.widths[int(code)]; ok {
+			g.width = w * font.scale
+		} else {
+			g.width = font.defaultWidth * font.scale
+		}
+		if font.toUnicode != nil {
+			g.text = font.toUnicode.mapping[cmapKey{n: n, code: code}]
+		}
+		if g.text == "" && !font.composite {
+			g.text = font.encoding[code]
+		}
+
+		glyphs = append(glyphs, g)
+		i += n
+	}
+	return glyphs
+}
+
+func bigEndian(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
+
+// pdfCMap is a parsed ToUnicode CMap
+type pdfCMap struct {
+	ranges  []codeRange
+	mapping map[cmapKey]string
+}
+
+type codeRange struct {
+	n      int
+	lo, hi uint32
+}
+
+type cmapKey struct {
+	n    int
+	code uint32
+}
+
+// codeLength returns the byte length of the code at the start of s according
+// to the codespace ranges, or 0 when no range matches
+func (cm *pdfCMap) codeLength(s []byte) int {
+	for n := 1; n <= 4 && n <= len(s); n++ {
+		code := bigEndian(s[:n])
+		for _, r := range cm.ranges {
+			if r.n == n && code >= r.lo && code <= r.hi {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+func parseCMap(data []byte) *pdfCMap {
+	cm := &pdfCMap{mapping: make(map[cmapKey]string)}
+	p := &pdfParser{data: data}
+
+	var operands []any
+	for len(cm.mapping) < maxCMapEntries {
+		v, err := p.parse(0)
+		if err != nil {
+			break
+		}
+		kw, ok := v.(pdfKeyword)
+		if !ok {
+			operands = append(operands, v)
+			continue
+		}
+
+		switch kw {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 && len(lo) == len(hi) && len(lo) > 0 && len(lo) <= 4 {
+					cm.ranges = append(cm.ranges, codeRange{n: len(lo), lo: bigEndian(lo), hi: bigEndian(hi)})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok := operands[i].([]byte)
+				if !ok || len(src) == 0 || len(src) > 4 {
+					continue
+				}
+				cm.mapping[cmapKey{n: len(src), code: bigEndian(src)}] = cmapText(operands[i+1])
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 || len(lo) == 0 || len(lo) > 4 {
+					continue
+				}
+				cm.addRange(len(lo), bigEndian(lo), bigEndian(hi), operands[i+2])
+			}
+		}
+		operands = operands[:0]
+	}
+
+	return cm
+}
+
+// addRange maps a bfrange entry. A string destination is incremented for
+// each code in the range; an array lists each destination explicitly.
+func (cm *pdfCMap) addRange(n int, lo, hi uint32, dst any) {
+	if hi < lo || hi-lo > 0xFFFF {
+		return
+	}
+	switch d := dst.(type) {
+	case []byte:
+		if len(d) == 0 {
+			return
+		}
+		for code := lo; code <= hi; code++ {
+			next := append([]byte(nil), d...)
+			offset := code - lo
+			for i := len(next) - 1; i >= 0 && offset > 0; i-- {
+				sum := uint32(next[i]) + offset
+				next[i] = byte(sum)
+				offset = sum >> 8
+			}
+			cm.mapping[cmapKey{n: n, code: code}] = cmapText(next)
+		}
+	case pdfArray:
+		for i, item := range d {
+			if code := lo + uint32(i); code <= hi {
+				cm.mapping[cmapKey{n: n, code: code}] = cmapText(item)
+			}
+		}
+	}
+}
+
+// cmapText decodes a ToUnicode destination, which is UTF-16BE or a glyph name
+func cmapText(v any) string {
+	switch t := v.(type) {
+	case []byte:
+		if len(t)%2 == 1 {
+			return string(t)
+		}
+		units := make([]uint16, len(t)/2)
+		for i := range units {
+			units[i] = uint16(t[2*i])<<8 | uint16(t[2*i+1])
+		}
+		return string(utf16.Decode(units))
+	case pdfName:
+		return glyphText(string(t))
+	}
+	return ""
+}
+
+// decodePDFText decodes a PDF text string such as a document title, which is
+// either UTF-16BE with a byte order mark or PDFDocEncoding
+func decodePDFText(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		return cmapText(b[2:])
+	}
+	if len(b) >= 3 && b[0] == 0xEF && b[1] == 0xBB && b[2] == 0xBF {
+		return string(b[3:])
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// glyphNames covers the Adobe glyph names that aren't derivable from their spelling
+var glyphNames = map[string]string{
+	"space": " ", "exclam": "!", "quotedbl": "\"", "numbersign": "#", "dollar": "$",
+	"percent": "%", "ampersand": "&", "quotesingle": "'", "parenleft": "(", "parenright": ")",
+	"asterisk": "*", "plus": "+", "comma": ",", "hyphen": "-", "period": ".", "slash": "/",
+	"zero": "0", "one": "1", "two": "2", "three": "3", "four": "4",
+	"five": "5", "six": "6", "seven": "7", "eight": "8", "nine": "9",
+	"colon": ":", "semicolon": ";", "less": "<", "equal": "=", "greater": ">", "question": "?",
+	"at": "@", "bracketleft": "[", "backslash": "\\", "bracketright": "]", "asciicircum": "^",
+	"underscore": "_", "grave": "`", "braceleft": "{", "bar": "|", "braceright": "}", "asciitilde": "~",
+	"quoteleft": "‘", "quoteright": "’", "quotedblleft": "“", "quotedblright": "”",
+	"quotesinglbase": "‚", "quotedblbase": "„", "guillemotleft": "«", "guillemotright": "»",
+	"guilsinglleft": "‹", "guilsinglright": "›", "endash": "–", "emdash": "—",
+	"bullet": "•", "ellipsis": "…", "dagger": "†", "daggerdbl": "‡",
+	"trademark": "™", "copyright": "©", "registered": "®", "degree": "°",
+	"section": "§", "paragraph": "¶", "periodcentered": "·", "minus": "−",
+	"multiply": "×", "divide": "÷", "nbspace": "\u00a0", "sfthyphen": "\u00ad",
+	"exclamdown": "¡", "questiondown": "¿", "cent": "¢", "sterling": "£",
+	"yen": "¥", "Euro": "€", "perthousand": "‰", "florin": "ƒ",
+	"fi": "fi", "fl": "fl", "ff": "ff", "ffi": "ffi", "ffl": "ffl",
+	"germandbls": "ß", "ae": "æ", "AE": "Æ", "oe": "œ", "OE": "Œ",
+	"oslash": "ø", "Oslash": "Ø", "dotlessi": "ı", "eth": "ð", "Eth": "Ð",
+	"thorn": "þ", "Thorn": "Þ", "lslash": "ł", "Lslash": "Ł",
+}
+
+// accentMarks maps glyph name suffixes to combining characters, so names such
+// as "eacute" compose to "é"
+var accentMarks = []struct {
+	suffix string
+	mark   rune
+}{
+	{"acute", '\u0301'}, {"grave", '\u0300'}, {"circumflex", '\u0302'}, {"dieresis", '\u0308'},
+	{"tilde", '\u0303'}, {"ring", '\u030a'}, {"cedilla", '\u0327'}, {"caron", '\u030c'},
+}
+
+// glyphText maps a glyph name to its text following the Adobe glyph naming rules
+func glyphText(name string) string {
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		name = name[:i]
+	}
+	if strings.Contains(name, "_") {
+		var b strings.Builder
+		for _, part := range strings.Split(name, "_") {
+			b.WriteString(glyphText(part))
+		}
+		return b.String()
+	}
+	if text, ok := glyphNames[name]; ok {
+		return text
+	}
+	if len(name) == 1 && ((name[0] >= 'a' && name[0] <= 'z') || (name[0] >= 'A' && name[0] <= 'Z')) {
+		return name
+	}
+	if strings.HasPrefix(name, "uni") && len(name) >= 7 && (len(name)-3)%4 == 0 {
+		var b strings.Builder
+		for i := 3; i < len(name); i += 4 {
+			r, err := strconv.ParseUint(name[i:i+4], 16, 32)
+			if err != nil {
+				return ""
+			}
+			b.WriteRune(rune(r))
+		}
+		return b.String()
+	}
+	if strings.HasPrefix(name, "u") && len(name) >= 5 && len(name) <= 7 {
+		if r, err := strconv.ParseUint(name[1:], 16, 32); err == nil && utf8.ValidRune(rune(r)) {
+			return string(rune(r))
+		}
+	}
+	for _, accent := range accentMarks {
+		if base, ok := strings.CutSuffix(name, accent.suffix); ok && len(base) == 1 {
+			return norm.NFC.String(base + string(accent.mark))
+		}
+	}
+	return ""
+}
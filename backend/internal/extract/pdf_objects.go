@@ -0,0 +1,631 @@
+package extract
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// PDF object model. Strings are []byte, integers are int and reals are float64.
+type (
+	pdfName    string
+	pdfKeyword string
+	pdfDict    map[pdfName]any
+	pdfArray   []any
+	pdfRef     struct{ num, gen int }
+	pdfStream  struct {
+		dict pdfDict
+		raw  []byte
+	}
+)
+
+// maxPDFNesting bounds how deeply arrays and dictionaries may nest
+const maxPDFNesting = 64
+
+var (
+	errPDFTooLarge = errors.New("pdf content exceeds size limit")
+
+	pdfObjectHeader = regexp.MustCompile(`(\d+)[\x00\t\n\f\r ]+\d+[\x00\t\n\f\r ]+obj`)
+)
+
+func isPDFSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// pdfParser reads PDF objects from a byte slice. The same parser serves the
+// file body, object streams, content streams and CMaps.
+type pdfParser struct {
+	data []byte
+	pos  int
+}
+
+func (p *pdfParser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		if !isPDFSpace(c) {
+			return
+		}
+		p.pos++
+	}
+}
+
+// atKeyword reports whether the next token is the given keyword
+func (p *pdfParser) atKeyword(kw string) bool {
+	end := p.pos + len(kw)
+	if !bytes.HasPrefix(p.data[p.pos:], []byte(kw)) {
+		return false
+	}
+	return end == len(p.data) || isPDFSpace(p.data[end]) || isPDFDelimiter(p.data[end])
+}
+
+// parse reads the next object. Operators and other bare words come back as
+// pdfKeyword; io.EOF is returned once the data is exhausted.
+func (p *pdfParser) parse(depth int) (any, error) {
+	if depth > maxPDFNesting {
+		return nil, errors.New("pdf objects nested too deeply")
+	}
+
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, io.EOF
+	}
+
+	c := p.data[p.pos]
+	switch {
+	case c == '/':
+		p.pos++
+		return p.name(), nil
+	case c == '(':
+		p.pos++
+		return p.literalString(), nil
+	case c == '<':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == '<' {
+			p.pos += 2
+			return p.dict(depth)
+		}
+		p.pos++
+		return p.hexString(), nil
+	case c == '[':
+		p.pos++
+		return p.array(depth)
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		n := p.number()
+		if num, ok := n.(int); ok && num >= 0 {
+			if ref, ok := p.reference(num); ok {
+				return ref, nil
+			}
+		}
+		return n, nil
+	case isPDFDelimiter(c):
+		p.pos++
+		return pdfKeyword(c), nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.data) && !isPDFSpace(p.data[p.pos]) && !isPDFDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	switch kw := string(p.data[start:p.pos]); kw {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		return pdfKeyword(kw), nil
+	}
+}
+
+func (p *pdfParser) name() pdfName {
+	var name []byte
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isPDFSpace(c) || isPDFDelimiter(c) {
+			break
+		}
+		if c == '#' && p.pos+2 < len(p.data) {
+			if b, err := hex.DecodeString(string(p.data[p.pos+1 : p.pos+3])); err == nil {
+				name = append(name, b[0])
+				p.pos += 3
+				continue
+			}
+		}
+		name = append(name, c)
+		p.pos++
+	}
+	return pdfName(name)
+}
+
+func (p *pdfParser) literalString() []byte {
+	var out []byte
+	depth := 1
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\r':
+			// Bare EOLs inside strings read as a single newline
+			if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+				p.pos++
+			}
+			c = '\n'
+		case '\\':
+			if p.pos >= len(p.data) {
+				return out
+			}
+			e := p.data[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (p *pdfParser) hexString() []byte {
+	var digits []byte
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		if c == '>' {
+			break
+		}
+		if isHexDigit(c) {
+			digits = append(digits, c)
+		}
+	}
+	return decodeHexDigits(digits)
+}
+
+func (p *pdfParser) dict(depth int) (pdfDict, error) {
+	d := pdfDict{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return d, io.ErrUnexpectedEOF
+		}
+		if p.data[p.pos] == '>' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '>' {
+			p.pos += 2
+			return d, nil
+		}
+		key, err := p.parse(depth + 1)
+		if err != nil {
+			return d, err
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			continue
+		}
+		val, err := p.parse(depth + 1)
+		if err != nil {
+			return d, err
+		}
+		d[name] = val
+	}
+}
+
+func (p *pdfParser) array(depth int) (pdfArray, error) {
+	var arr pdfArray
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return arr, io.ErrUnexpectedEOF
+		}
+		if p.data[p.pos] == ']' {
+			p.pos++
+			return arr, nil
+		}
+		val, err := p.parse(depth + 1)
+		if err != nil {
+			return arr, err
+		}
+		arr = append(arr, val)
+	}
+}
+
+func (p *pdfParser) number() any {
+	start := p.pos
+	real := false
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == '.' {
+			real = true
+		} else if !(c >= '0' && c <= '9') && !(p.pos == start && (c == '+' || c == '-')) {
+			break
+		}
+		p.pos++
+	}
+	s := string(p.data[start:p.pos])
+	if !real {
+		if n, err := strconv.Atoi(s); err == nil {
+			return n
+		}
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// reference checks whether num begins an indirect reference "num gen R",
+// restoring the position when it doesn't
+func (p *pdfParser) reference(num int) (pdfRef, bool) {
+	save := p.pos
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos > start {
+		gen, _ := strconv.Atoi(string(p.data[start:p.pos]))
+		p.skipSpace()
+		if p.atKeyword("R") {
+			p.pos++
+			return pdfRef{num: num, gen: gen}, true
+		}
+	}
+	p.pos = save
+	return pdfRef{}, false
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func decodeHexDigits(digits []byte) []byte {
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	hex.Decode(out, digits)
+	return out
+}
+
+// pdfFile holds every object in a PDF, keyed by object number
+type pdfFile struct {
+	objects map[int]any
+	trailer pdfDict
+	budget  int64 // decompressed bytes still allowed
+	decoded map[*pdfStream][]byte
+}
+
+// parsePDF indexes a PDF by scanning for object definitions rather than
+// trusting the cross-reference table, which is frequently damaged in the
+// wild. Later definitions win, matching incremental updates.
+func parsePDF(data []byte) (*pdfFile, error) {
+	if !bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return nil, errors.New("invalid pdf: missing header")
+	}
+
+	f := &pdfFile{
+		objects: make(map[int]any),
+		budget:  maxArchiveBytes,
+		decoded: make(map[*pdfStream][]byte),
+	}
+
+	var trailers []pdfDict
+	pos := 0
+	for pos < len(data) {
+		loc := pdfObjectHeader.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		numText, end := data[pos+loc[2]:pos+loc[3]], pos+loc[1]
+		pos = end
+		if end < len(data) && !isPDFSpace(data[end]) && !isPDFDelimiter(data[end]) {
+			continue
+		}
+		num, err := strconv.Atoi(string(numText))
+		if err != nil {
+			continue
+		}
+
+		p := &pdfParser{data: data, pos: end}
+		val, err := p.parse(0)
+		if err != nil {
+			continue
+		}
+		p.skipSpace()
+		if dict, ok := val.(pdfDict); ok && p.atKeyword("stream") {
+			p.pos += len("stream")
+			stream := &pdfStream{dict: dict}
+			stream.raw, p.pos = f.streamData(data, p.pos, dict)
+			val = stream
+
+			if dict["Root"] != nil {
+				trailers = append(trailers, dict) // cross-reference stream
+			}
+		}
+		f.objects[num] = val
+		if stream, ok := val.(*pdfStream); ok && f.name(stream.dict["Type"]) == "ObjStm" {
+			f.expandObjectStream(stream)
+		}
+		pos = p.pos
+	}
+
+	for i := 0; ; {
+		idx := bytes.Index(data[i:], []byte("trailer"))
+		if idx < 0 {
+			break
+		}
+		p := &pdfParser{data: data, pos: i + idx + len("trailer")}
+		if d, err := p.parse(0); err == nil {
+			if dict, ok := d.(pdfDict); ok && dict["Root"] != nil {
+				trailers = append(trailers, dict)
+			}
+		}
+		i = p.pos
+	}
+
+	if len(trailers) > 0 {
+		f.trailer = trailers[len(trailers)-1]
+	} else {
+		f.trailer = pdfDict{}
+		for _, num := range f.objectNumbers() {
+			if d, ok := f.objects[num].(pdfDict); ok && f.name(d["Type"]) == "Catalog" {
+				f.trailer["Root"] = pdfRef{num: num}
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// streamData returns the raw bytes of a stream starting just after the
+// "stream" keyword, and the position after "endstream"
+func (f *pdfFile) streamData(data []byte, pos int, dict pdfDict) ([]byte, int) {
+	if pos < len(data) && data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(data) && data[pos] == '\n' {
+		pos++
+	}
+
+	endstream := []byte("endstream")
+	if length, ok := f.integer(dict["Length"]); ok && length >= 0 && pos+length <= len(data) {
+		rest := pdfParser{data: data, pos: pos + length}
+		rest.skipSpace()
+		if bytes.HasPrefix(data[rest.pos:], endstream) {
+			return data[pos : pos+length], rest.pos + len(endstream)
+		}
+	}
+
+	idx := bytes.Index(data[pos:], endstream)
+	if idx < 0 {
+		return data[pos:], len(data)
+	}
+	raw := data[pos : pos+idx]
+	raw = bytes.TrimSuffix(raw, []byte("\n"))
+	raw = bytes.TrimSuffix(raw, []byte("\r"))
+	return raw, pos + idx + len(endstream)
+}
+
+// expandObjectStream registers the objects packed inside a compressed object stream
+func (f *pdfFile) expandObjectStream(s *pdfStream) {
+	data, err := f.decode(s)
+	if err != nil {
+		return
+	}
+	n, _ := f.integer(s.dict["N"])
+	first, _ := f.integer(s.dict["First"])
+	if first <= 0 || first > len(data) {
+		return
+	}
+
+	header := &pdfParser{data: data[:first]}
+	for i := 0; i < n; i++ {
+		numVal, err1 := header.parse(0)
+		offVal, err2 := header.parse(0)
+		num, ok1 := numVal.(int)
+		off, ok2 := offVal.(int)
+		if err1 != nil || err2 != nil || !ok1 || !ok2 {
+			return
+		}
+		if first+off >= len(data) {
+			continue
+		}
+		p := &pdfParser{data: data, pos: first + off}
+		if val, err := p.parse(0); err == nil {
+			f.objects[num] = val
+		}
+	}
+}
+
+func (f *pdfFile) objectNumbers() []int {
+	nums := make([]int, 0, len(f.objects))
+	for num := range f.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+// resolve follows indirect references
+func (f *pdfFile) resolve(v any) any {
+	for i := 0; i < 8; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = f.objects[ref.num]
+	}
+	return nil
+}
+
+func (f *pdfFile) dict(v any) pdfDict {
+	switch t := f.resolve(v).(type) {
+	case pdfDict:
+		return t
+	case *pdfStream:
+		return t.dict
+	}
+	return nil
+}
+
+func (f *pdfFile) array(v any) pdfArray {
+	arr, _ := f.resolve(v).(pdfArray)
+	return arr
+}
+
+func (f *pdfFile) name(v any) pdfName {
+	name, _ := f.resolve(v).(pdfName)
+	return name
+}
+
+func (f *pdfFile) number(v any) (float64, bool) {
+	switch t := f.resolve(v).(type) {
+	case int:
+		return float64(t), true
+	case float64:
+		return t, true
+	}
+	return 0, false
+}
+
+func (f *pdfFile) integer(v any) (int, bool) {
+	switch t := f.resolve(v).(type) {
+	case int:
+		return t, true
+	case float64:
+		return int(t), true
+	}
+	return 0, false
+}
+
+// decode applies a stream's filters. Only the filters used for text-bearing
+// streams are supported; image codecs are never needed for extraction.
+func (f *pdfFile) decode(s *pdfStream) ([]byte, error) {
+	if data, ok := f.decoded[s]; ok {
+		return data, nil
+	}
+
+	var filters []pdfName
+	switch v := f.resolve(s.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []pdfName{v}
+	case pdfArray:
+		for _, item := range v {
+			filters = append(filters, f.name(item))
+		}
+	}
+
+	data := s.raw
+	for _, filter := range filters {
+		var err error
+		switch filter {
+		case "FlateDecode", "Fl":
+			data, err = f.inflate(data)
+		case "ASCIIHexDecode", "AHx":
+			var digits []byte
+			for _, c := range data {
+				if c == '>' {
+					break
+				}
+				if isHexDigit(c) {
+					digits = append(digits, c)
+				}
+			}
+			data = decodeHexDigits(digits)
+		case "ASCII85Decode", "A85":
+			data, err = decodeASCII85(data)
+		default:
+			err = fmt.Errorf("unsupported pdf filter %s", filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f.decoded[s] = data
+	return data, nil
+}
+
+// inflate decompresses a FlateDecode stream within the file's budget. Truncated
+// or checksum-damaged streams are common, so partial output is kept.
+func (f *pdfFile) inflate(data []byte) ([]byte, error) {
+	var r io.Reader
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		r = zr
+	} else {
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+
+	out, err := io.ReadAll(io.LimitReader(r, f.budget+1))
+	if int64(len(out)) > f.budget {
+		return nil, errPDFTooLarge
+	}
+	f.budget -= int64(len(out))
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("failed to inflate pdf stream: %w", err)
+	}
+	return out, nil
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+	if idx := bytes.Index(data, []byte("~>")); idx >= 0 {
+		data = data[:idx]
+	}
+	return io.ReadAll(ascii85.NewDecoder(bytes.NewReader(data)))
+}
@@ -0,0 +1,222 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF assembles a PDF from object bodies numbered from 1, with object 1
+// as the catalog and an optional info dictionary object
+func buildPDF(t *testing.T, objects []string, info int) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R", len(objects)+1)
+	if info > 0 {
+		fmt.Fprintf(&buf, " /Info %d 0 R", info)
+	}
+	fmt.Fprintf(&buf, " >>\nstartxref\n%d\n%%%%EOF\n", xref)
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+// pdfStreamObject renders a stream object, optionally flate-compressed
+func pdfStreamObject(t *testing.T, dict, content string, compress bool) string {
+	t.Helper()
+
+	data := []byte(content)
+	if compress {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			t.Fatalf("failed to compress stream: %v", err)
+		}
+		zw.Close()
+		data = buf.Bytes()
+		dict += " /Filter /FlateDecode"
+	}
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+// testPDFPage lays out a page with a running header, body lines at 11pt and a
+// footer. Body lines starting with "# " are set at 16pt; empty lines leave a gap.
+func testPDFPage(header string, body []string, footer string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "BT /F1 9 Tf 72 770 Td (%s) Tj ET\n", header)
+	b.WriteString("BT /F1 11 Tf 14 TL 72 700 Td\n")
+	for i, line := range body {
+		if i > 0 {
+			b.WriteString("T*\n")
+		}
+		if heading, ok := strings.CutPrefix(line, "# "); ok {
+			fmt.Fprintf(&b, "/F1 16 Tf (%s) Tj /F1 11 Tf\n", heading)
+		} else if line != "" {
+			fmt.Fprintf(&b, "(%s) Tj\n", line)
+		}
+	}
+	b.WriteString("ET\n")
+	fmt.Fprintf(&b, "BT /F1 9 Tf 300 40 Td (%s) Tj ET\n", footer)
+	return b.String()
+}
+
+func TestPDF_ReassemblesParagraphs(t *testing.T) {
+	pages := [][]string{
+		{
+			"A study of reading speed shows that most",
+			"people can comfortably read faster with prac-",
+			"tice and a little patience.",
+			"",
+			"The second paragraph continues onto the",
+			"next page without any break in the middle",
+		},
+		{
+			"of the sentence, which should be rejoined.",
+			"",
+			"Kerning and word spacing are handled when",
+			"text is positioned piece by piece.",
+		},
+		{
+			"# Conclusions",
+			"Readers improve with practice.",
+		},
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [5 0 R 7 0 R 9 0 R] /Count 3 /Resources << /Font << /F1 3 0 R >> >> >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Title (Reading Faster) /Author <FEFF0041006E006E00E9> >>",
+	}
+	for i, body := range pages {
+		content := testPDFPage("The Journal of Examples", body, fmt.Sprint(i+1))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %d 0 R >>", len(objects)+2),
+			pdfStreamObject(t, "", content, i == 1),
+		)
+	}
+
+	r := buildPDF(t, objects, 4)
+	doc, err := File("paper.pdf", r, r.Size())
+	if err != nil {
+		t.Fatalf("File failed: %v", err)
+	}
+
+	if doc.Title != "Reading Faster" || doc.Author != "Anné" {
+		t.Errorf("unexpected metadata: title=%q author=%q", doc.Title, doc.Author)
+	}
+
+	want := strings.Join([]string{
+		"A study of reading speed shows that most people can comfortably read faster with practice and a little patience.",
+		"The second paragraph continues onto the next page without any break in the middle of the sentence, which should be rejoined.",
+		"Kerning and word spacing are handled when text is positioned piece by piece.",
+		"Conclusions",
+		"Readers improve with practice.",
+	}, "\n\n")
+	if got := doc.Text(); got != want {
+		t.Errorf("unexpected text:\n%s", got)
+	}
+
+	if len(doc.Sections) != 2 || doc.Sections[1].Title != "Conclusions" {
+		t.Errorf("expected larger text to start a section, got %+v", doc.Sections)
+	}
+}
+
+func TestPDF_PositionedText(t *testing.T) {
+	content := "BT /F1 12 Tf 72 700 Td [(Hel) -20 (lo) -300 (world)] TJ ET\n" +
+		"BT /F1 12 Tf 72 684 Td (Spaced) Tj 40 0 Td (apart) Tj ET\n" +
+		"BT /F1 12 Tf 72 668 Td (caf\\351 \\(open\\)) Tj ET"
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+		pdfStreamObject(t, "", content, true),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	}
+
+	r := buildPDF(t, objects, 0)
+	doc, err := PDF(r, r.Size())
+	if err != nil {
+		t.Fatalf("PDF failed: %v", err)
+	}
+
+	if got, want := doc.Text(), "Hello world Spaced apart café (open)"; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+}
+
+func TestPDF_ScannedPages(t *testing.T) {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Im1 5 0 R >> >> /Contents 4 0 R >>",
+		pdfStreamObject(t, "", "q 612 0 0 792 0 0 cm /Im1 Do Q", false),
+		pdfStreamObject(t, "/Type /XObject /Subtype /Image /Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8", "\x00", false),
+	}
+
+	r := buildPDF(t, objects, 0)
+	if _, err := File("scan.pdf", r, r.Size()); !errors.Is(err, ErrScannedPDF) {
+		t.Errorf("expected ErrScannedPDF, got %v", err)
+	}
+}
+
+func TestParseCMap(t *testing.T) {
+	cmap := parseCMap([]byte(`/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0003> <0020>
+<0011> <00660069>
+endbfchar
+1 beginbfrange
+<0024> <0026> <0041>
+endbfrange
+endcmap`))
+
+	font := &pdfFont{composite: true, toUnicode: cmap, widths: map[int]float64{}, defaultWidth: 1000, scale: 0.001}
+	var text strings.Builder
+	for _, g := range font.decode([]byte{0x00, 0x24, 0x00, 0x26, 0x00, 0x03, 0x00, 0x11, 0x00, 0x25}) {
+		text.WriteString(g.text)
+	}
+	if got, want := text.String(), "AC fiB"; got != want {
+		t.Errorf("decoded %q, want %q", got, want)
+	}
+}
+
+func TestJoinPDFLines(t *testing.T) {
+	tests := []struct {
+		text, next string
+		want       string
+	}{
+		{"with prac-", "tice", "with practice"},
+		{"the well-", "Known", "the well- Known"},
+		{"pages 2-", "3", "pages 2- 3"},
+		{"co\u00ad", "operate", "cooperate"},
+		{"the end.", "Next", "the end. Next"},
+	}
+
+	for _, tt := range tests {
+		if got := joinPDFLines(tt.text, tt.next); got != tt.want {
+			t.Errorf("joinPDFLines(%q, %q) = %q, want %q", tt.text, tt.next, got, tt.want)
+		}
+	}
+}
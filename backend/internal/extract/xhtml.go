@@ -0,0 +1,121 @@
+package extract
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// blockKind classifies a block of extracted text
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockListItem
+)
+
+// block is a paragraph-level unit of text
+type block struct {
+	kind blockKind
+	text string
+}
+
+// xhtmlBlockElements start and end a paragraph-level block
+var xhtmlBlockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "blockquote": true,
+	"pre": true, "li": true, "dt": true, "dd": true, "tr": true, "figcaption": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "footer": true, "aside": true, "table": true, "ul": true, "ol": true,
+}
+
+// xhtmlSkipElements have content that is never reader-visible prose
+var xhtmlSkipElements = map[string]bool{
+	"head": true, "script": true, "style": true, "nav": true, "svg": true, "math": true,
+}
+
+// isHeading reports whether an element name is h1-h6
+func isHeading(name string) bool {
+	return len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6'
+}
+
+// parseXHTML extracts paragraph-level blocks from an XHTML document.
+// The decoder is lenient so slightly malformed content documents still parse.
+func parseXHTML(r io.Reader) ([]block, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var (
+		blocks  []block
+		current strings.Builder
+		kind    = blockParagraph
+		skip    int
+	)
+
+	flush := func() {
+		text := collapseSpace(current.String())
+		current.Reset()
+		if text != "" {
+			blocks = append(blocks, block{kind: kind, text: text})
+		}
+		kind = blockParagraph
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			// Keep what was read before the document went bad
+			if len(blocks) > 0 || current.Len() > 0 {
+				break
+			}
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if xhtmlSkipElements[name] {
+				skip++
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			if xhtmlBlockElements[name] {
+				flush()
+				switch {
+				case isHeading(name):
+					kind = blockHeading
+				case name == "li":
+					kind = blockListItem
+				}
+			} else if name == "br" {
+				current.WriteByte(' ')
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if xhtmlSkipElements[name] {
+				if skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if skip == 0 && xhtmlBlockElements[name] {
+				flush()
+			}
+		case xml.CharData:
+			if skip == 0 {
+				current.Write(t)
+			}
+		}
+	}
+	flush()
+
+	return blocks, nil
+}
@@ -0,0 +1,21 @@
+package goals
+
+import "errors"
+
+var (
+	// ErrGoalNotFound indicates the goal doesn't exist or belongs to another user.
+	ErrGoalNotFound = errors.New("goal not found")
+)
+
+// ValidationError represents an invalid goal payload.
+type ValidationError struct {
+	message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.message
+}
+
+func newValidationError(message string) *ValidationError {
+	return &ValidationError{message: message}
+}
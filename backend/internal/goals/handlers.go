@@ -0,0 +1,146 @@
+package goals
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+	"golang.org/x/exp/slog"
+)
+
+// Handlers contains HTTP handlers for reading goals
+type Handlers struct {
+	service   *Service
+	logger    *slog.Logger
+	sanitizer *logging.Sanitizer
+}
+
+// NewHandlers creates a new goals Handlers instance
+func NewHandlers(service *Service, logger *slog.Logger, sanitizer *logging.Sanitizer) *Handlers {
+	return &Handlers{
+		service:   service,
+		logger:    logger,
+		sanitizer: sanitizer,
+	}
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
+
+func mapGoalsError(err error, internalMessage string) (status int, message string) {
+	if errors.Is(err, ErrGoalNotFound) {
+		return http.StatusNotFound, "goal not found"
+	}
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest, validationErr.Error()
+	}
+
+	return http.StatusInternalServerError, internalMessage
+}
+
+// userFromRequest returns the authenticated user, writing a 401 if there isn't one
+func (h *Handlers) userFromRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "not authenticated")
+		return uuid.Nil, false
+	}
+
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddString("user.id", h.sanitizer.UserID(userID.String()))
+	}
+
+	return userID, true
+}
+
+// fail logs err on the wide event and writes the mapped error response
+func fail(w http.ResponseWriter, r *http.Request, err error, internalMessage string) {
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddError(err)
+	}
+	status, message := mapGoalsError(err, internalMessage)
+	writeError(w, status, message)
+}
+
+// GetGoals handles GET /api/goals
+func (h *Handlers) GetGoals(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	statuses, err := h.service.GetStatus(r.Context(), userID)
+	if err != nil {
+		fail(w, r, err, "failed to get goals")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+// SetGoal handles PUT /api/goals
+// The goal replaces any existing goal with the same metric and period
+func (h *Handlers) SetGoal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req GoalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if we := logging.WideEventFromContext(r.Context()); we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	status, err := h.service.SetGoal(r.Context(), userID, &req)
+	if err != nil {
+		fail(w, r, err, "failed to set goal")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// DeleteGoal handles DELETE /api/goals/:id
+func (h *Handlers) DeleteGoal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	goalID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid goal ID")
+		return
+	}
+
+	if err := h.service.DeleteGoal(r.Context(), userID, goalID); err != nil {
+		fail(w, r, err, "failed to delete goal")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
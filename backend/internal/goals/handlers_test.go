@@ -0,0 +1,28 @@
+package goals
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestMapGoalsErrorNotFound(t *testing.T) {
+	status, message := mapGoalsError(ErrGoalNotFound, "failed")
+	if status != http.StatusNotFound || message != "goal not found" {
+		t.Errorf("expected 404 goal not found, got %d %q", status, message)
+	}
+}
+
+func TestMapGoalsErrorValidation(t *testing.T) {
+	status, message := mapGoalsError(newValidationError("invalid timezone"), "failed")
+	if status != http.StatusBadRequest || message != "invalid timezone" {
+		t.Errorf("expected 400 invalid timezone, got %d %q", status, message)
+	}
+}
+
+func TestMapGoalsErrorInternalFallback(t *testing.T) {
+	status, message := mapGoalsError(errors.New("connection refused"), "failed to get goals")
+	if status != http.StatusInternalServerError || message != "failed to get goals" {
+		t.Errorf("expected 500 with the internal message, got %d %q", status, message)
+	}
+}
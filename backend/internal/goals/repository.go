@@ -0,0 +1,89 @@
+package goals
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Repository handles database operations for reading goals
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a new goals repository
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// List returns a user's goals, daily before weekly
+func (r *Repository) List(ctx context.Context, userID uuid.UUID) ([]Goal, error) {
+	query := `
+		SELECT id, metric, period, target, timezone, created_at, updated_at
+		FROM reading_goals
+		WHERE user_id = $1
+		ORDER BY period, metric
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goals: %w", err)
+	}
+	defer rows.Close()
+
+	goals := []Goal{}
+	for rows.Next() {
+		var g Goal
+		if err := rows.Scan(&g.ID, &g.Metric, &g.Period, &g.Target, &g.Timezone, &g.CreatedAt, &g.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		goals = append(goals, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating goals: %w", err)
+	}
+
+	return goals, nil
+}
+
+// Upsert sets a user's goal for its metric and period
+func (r *Repository) Upsert(ctx context.Context, userID uuid.UUID, g *Goal) error {
+	query := `
+		INSERT INTO reading_goals (user_id, metric, period, target, timezone, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		ON CONFLICT (user_id, metric, period) DO UPDATE SET
+			target = EXCLUDED.target,
+			timezone = EXCLUDED.timezone,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, userID, g.Metric, g.Period, g.Target, g.Timezone).
+		Scan(&g.ID, &g.CreatedAt, &g.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save goal: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes one of a user's goals
+func (r *Repository) Delete(ctx context.Context, userID, goalID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM reading_goals WHERE id = $1 AND user_id = $2`, goalID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete goal: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrGoalNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package goals
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/stats"
+)
+
+const (
+	// dateLayout formats the calendar days goals are measured on
+	dateLayout = "2006-01-02"
+
+	// historyDays bounds how far back streaks are computed
+	historyDays = 730
+)
+
+// Service handles business logic for reading goals
+type Service struct {
+	repo    *Repository
+	reading *stats.Repository // daily reading totals goals are measured on
+}
+
+// NewService creates a new goals service
+func NewService(repo *Repository, reading *stats.Repository) *Service {
+	return &Service{repo: repo, reading: reading}
+}
+
+// GetStatus returns a user's goals with their progress and streaks
+func (s *Service) GetStatus(ctx context.Context, userID uuid.UUID) ([]GoalStatus, error) {
+	goals, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	statuses := make([]GoalStatus, 0, len(goals))
+	activity := make(map[string][]DayActivity) // by timezone, shared by goals in the same zone
+	for _, g := range goals {
+		loc, err := stats.LoadTimezone(g.Timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+
+		days, ok := activity[loc.String()]
+		if !ok {
+			days, err = s.dailyActivity(ctx, userID, loc, now)
+			if err != nil {
+				return nil, err
+			}
+			activity[loc.String()] = days
+		}
+
+		statuses = append(statuses, computeStatus(g, days, now.In(loc)))
+	}
+
+	return statuses, nil
+}
+
+// SetGoal creates or replaces the user's goal for a metric and period
+func (s *Service) SetGoal(ctx context.Context, userID uuid.UUID, req *GoalRequest) (*GoalStatus, error) {
+	g, err := validateGoal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Upsert(ctx, userID, g); err != nil {
+		return nil, err
+	}
+
+	loc, err := stats.LoadTimezone(g.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	days, err := s.dailyActivity(ctx, userID, loc, now)
+	if err != nil {
+		return nil, err
+	}
+
+	status := computeStatus(*g, days, now.In(loc))
+	return &status, nil
+}
+
+// DeleteGoal removes one of the user's goals
+func (s *Service) DeleteGoal(ctx context.Context, userID, goalID uuid.UUID) error {
+	return s.repo.Delete(ctx, userID, goalID)
+}
+
+// dailyActivity returns a user's reading per day in loc over the last
+// historyDays days, oldest first
+func (s *Service) dailyActivity(ctx context.Context, userID uuid.UUID, loc *time.Location, now time.Time) ([]DayActivity, error) {
+	daily, err := s.reading.DailyReading(ctx, userID, stats.NewWindow(now, historyDays, loc))
+	if err != nil {
+		return nil, err
+	}
+	return activityDays(daily, loc)
+}
+
+// activityDays converts daily reading totals keyed by date into days in loc,
+// oldest first
+func activityDays(daily map[string]stats.DayStats, loc *time.Location) ([]DayActivity, error) {
+	days := make([]DayActivity, 0, len(daily))
+	for date, d := range daily {
+		day, err := time.ParseInLocation(dateLayout, date, loc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse activity date: %w", err)
+		}
+		days = append(days, DayActivity{Date: day, WordsRead: d.WordsRead, SecondsRead: d.SecondsRead})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days, nil
+}
+
+// validateGoal checks a goal request and returns the goal it describes
+func validateGoal(req *GoalRequest) (*Goal, error) {
+	switch req.Metric {
+	case MetricWords:
+		if req.Target < 1 || req.Target > MaxWordsTarget {
+			return nil, newValidationError("target must be between 1 and 1000000 words")
+		}
+	case MetricMinutes:
+		if req.Target < 1 || req.Target > MaxMinutesTarget {
+			return nil, newValidationError("target must be between 1 and 10080 minutes")
+		}
+	default:
+		return nil, newValidationError("metric must be 'words' or 'minutes'")
+	}
+
+	if req.Period != PeriodDaily && req.Period != PeriodWeekly {
+		return nil, newValidationError("period must be 'daily' or 'weekly'")
+	}
+
+	loc, err := stats.LoadTimezone(req.Timezone)
+	if err != nil {
+		return nil, newValidationError("invalid timezone")
+	}
+
+	return &Goal{Metric: req.Metric, Period: req.Period, Target: req.Target, Timezone: loc.String()}, nil
+}
+
+// periodStart returns the first day of the period containing day, which must
+// be a local midnight
+func periodStart(period Period, day time.Time) time.Time {
+	if period == PeriodWeekly {
+		// Weeks start on Monday
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+// nextPeriod returns the start of the period after the one starting at start
+func nextPeriod(period Period, start time.Time) time.Time {
+	if period == PeriodWeekly {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// computeStatus measures a goal against daily activity in its timezone. now
+// must be in the same location as the activity dates.
+func computeStatus(g Goal, days []DayActivity, now time.Time) GoalStatus {
+	// Seconds are summed per period before converting, so short sessions add up
+	totals := make(map[string]int)
+	for _, d := range days {
+		key := periodStart(g.Period, d.Date).Format(dateLayout)
+		if g.Metric == MetricMinutes {
+			totals[key] += d.SecondsRead
+		} else {
+			totals[key] += d.WordsRead
+		}
+	}
+	amount := func(start time.Time) int {
+		total := totals[start.Format(dateLayout)]
+		if g.Metric == MetricMinutes {
+			return total / 60
+		}
+		return total
+	}
+
+	y, m, d := now.Date()
+	current := periodStart(g.Period, time.Date(y, m, d, 0, 0, 0, 0, now.Location()))
+
+	status := GoalStatus{
+		Goal:        g,
+		PeriodStart: current.Format(dateLayout),
+		Progress:    amount(current),
+	}
+	status.Met = status.Progress >= g.Target
+
+	if len(days) == 0 {
+		return status
+	}
+
+	// Walk every period from the first with activity to the current one
+	run := 0
+	for p := periodStart(g.Period, days[0].Date); !p.After(current); p = nextPeriod(g.Period, p) {
+		switch {
+		case amount(p) >= g.Target:
+			run++
+		case p.Equal(current):
+			// The current period can still be met; it doesn't break the streak
+		default:
+			run = 0
+		}
+		status.LongestStreak = max(status.LongestStreak, run)
+	}
+	status.CurrentStreak = run
+
+	return status
+}
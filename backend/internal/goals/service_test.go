@@ -0,0 +1,136 @@
+package goals
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/stats"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestValidateGoal(t *testing.T) {
+	g, err := validateGoal(&GoalRequest{Metric: MetricMinutes, Period: PeriodDaily, Target: 20})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.Timezone != "UTC" {
+		t.Errorf("expected timezone to default to UTC, got %q", g.Timezone)
+	}
+
+	invalid := []GoalRequest{
+		{Metric: "pages", Period: PeriodDaily, Target: 10},
+		{Metric: MetricWords, Period: "monthly", Target: 10},
+		{Metric: MetricWords, Period: PeriodDaily, Target: 0},
+		{Metric: MetricMinutes, Period: PeriodWeekly, Target: MaxMinutesTarget + 1},
+		{Metric: MetricWords, Period: PeriodDaily, Target: 10, Timezone: "Mars/Olympus"},
+		{Metric: MetricWords, Period: PeriodDaily, Target: 10, Timezone: "Local"},
+	}
+	for _, req := range invalid {
+		var validationErr *ValidationError
+		if _, err := validateGoal(&req); !errors.As(err, &validationErr) {
+			t.Errorf("expected validation error for %+v, got %v", req, err)
+		}
+	}
+}
+
+func TestPeriodStart_WeeksStartMonday(t *testing.T) {
+	// March 1st 2026 is a Sunday
+	if got := periodStart(PeriodWeekly, day(2026, 3, 1)); !got.Equal(day(2026, 2, 23)) {
+		t.Errorf("expected Monday 2026-02-23, got %v", got)
+	}
+	if got := periodStart(PeriodWeekly, day(2026, 3, 2)); !got.Equal(day(2026, 3, 2)) {
+		t.Errorf("expected Monday 2026-03-02 to start its own week, got %v", got)
+	}
+	if got := periodStart(PeriodDaily, day(2026, 3, 1)); !got.Equal(day(2026, 3, 1)) {
+		t.Errorf("expected days to start themselves, got %v", got)
+	}
+}
+
+func TestActivityDays_SortsOldestFirst(t *testing.T) {
+	daily := map[string]stats.DayStats{
+		"2026-03-05": {Date: "2026-03-05", WordsRead: 300, SecondsRead: 60},
+		"2026-03-01": {Date: "2026-03-01", WordsRead: 100, SecondsRead: 20},
+		"2026-03-03": {Date: "2026-03-03", WordsRead: 200, SecondsRead: 40},
+	}
+
+	days, err := activityDays(daily, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(days) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(days))
+	}
+	for i, want := range []time.Time{day(2026, 3, 1), day(2026, 3, 3), day(2026, 3, 5)} {
+		if !days[i].Date.Equal(want) {
+			t.Errorf("day %d: expected %v, got %v", i, want, days[i].Date)
+		}
+	}
+	if days[2].WordsRead != 300 || days[2].SecondsRead != 60 {
+		t.Errorf("expected totals carried over, got %+v", days[2])
+	}
+}
+
+func TestComputeStatus_DailyStreaks(t *testing.T) {
+	goal := Goal{Metric: MetricWords, Period: PeriodDaily, Target: 1000}
+	days := []DayActivity{
+		{Date: day(2026, 3, 1), WordsRead: 1200},
+		{Date: day(2026, 3, 2), WordsRead: 1500},
+		{Date: day(2026, 3, 3), WordsRead: 1000},
+		{Date: day(2026, 3, 4), WordsRead: 200}, // missed
+		{Date: day(2026, 3, 5), WordsRead: 3000},
+		{Date: day(2026, 3, 6), WordsRead: 1100},
+		{Date: day(2026, 3, 7), WordsRead: 400}, // today, in progress
+	}
+	now := time.Date(2026, 3, 7, 15, 0, 0, 0, time.UTC)
+
+	status := computeStatus(goal, days, now)
+	if status.PeriodStart != "2026-03-07" || status.Progress != 400 || status.Met {
+		t.Errorf("unexpected current period: %+v", status)
+	}
+	// Today isn't met yet but still can be, so yesterday's streak holds
+	if status.CurrentStreak != 2 {
+		t.Errorf("expected current streak 2, got %d", status.CurrentStreak)
+	}
+	if status.LongestStreak != 3 {
+		t.Errorf("expected longest streak 3, got %d", status.LongestStreak)
+	}
+
+	// A whole day without reading breaks the streak
+	status = computeStatus(goal, days, now.AddDate(0, 0, 2))
+	if status.CurrentStreak != 0 || status.LongestStreak != 3 {
+		t.Errorf("expected the streak broken, got current %d longest %d", status.CurrentStreak, status.LongestStreak)
+	}
+}
+
+func TestComputeStatus_WeeklyMinutes(t *testing.T) {
+	goal := Goal{Metric: MetricMinutes, Period: PeriodWeekly, Target: 60}
+	days := []DayActivity{
+		// Week of Feb 23: 40 + 30 minutes, made of seconds that only add up per week
+		{Date: day(2026, 2, 24), SecondsRead: 2430},
+		{Date: day(2026, 2, 27), SecondsRead: 1800},
+		// Week of Mar 2: 61 minutes
+		{Date: day(2026, 3, 3), SecondsRead: 3660},
+	}
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	status := computeStatus(goal, days, now)
+	if status.PeriodStart != "2026-03-02" || status.Progress != 61 || !status.Met {
+		t.Errorf("unexpected current period: %+v", status)
+	}
+	if status.CurrentStreak != 2 || status.LongestStreak != 2 {
+		t.Errorf("expected streaks of 2, got current %d longest %d", status.CurrentStreak, status.LongestStreak)
+	}
+}
+
+func TestComputeStatus_NoActivity(t *testing.T) {
+	goal := Goal{Metric: MetricWords, Period: PeriodDaily, Target: 500}
+	status := computeStatus(goal, nil, time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC))
+
+	if status.Progress != 0 || status.Met || status.CurrentStreak != 0 || status.LongestStreak != 0 {
+		t.Errorf("expected an empty status, got %+v", status)
+	}
+}
@@ -0,0 +1,70 @@
+package goals
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Metric is what a goal counts
+type Metric string
+
+const (
+	MetricWords   Metric = "words"
+	MetricMinutes Metric = "minutes"
+)
+
+// Period is how often a goal resets
+type Period string
+
+const (
+	PeriodDaily  Period = "daily"
+	PeriodWeekly Period = "weekly" // weeks start on Monday
+)
+
+const (
+	// MaxWordsTarget bounds a words goal
+	MaxWordsTarget = 1000000
+
+	// MaxMinutesTarget bounds a minutes goal; a week has 10080 minutes
+	MaxMinutesTarget = 10080
+)
+
+// Goal is a target amount of reading per period, measured in Timezone
+type Goal struct {
+	ID        uuid.UUID `json:"id"`
+	Metric    Metric    `json:"metric"`
+	Period    Period    `json:"period"`
+	Target    int       `json:"target"`
+	Timezone  string    `json:"timezone"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// GoalStatus is a goal with progress in the current period and its streaks.
+// The current streak still counts while the current period is in progress,
+// so it only drops to zero once a whole period passes without meeting the goal.
+type GoalStatus struct {
+	Goal
+	PeriodStart   string `json:"periodStart"` // YYYY-MM-DD
+	Progress      int    `json:"progress"`
+	Met           bool   `json:"met"`
+	CurrentStreak int    `json:"currentStreak"`
+	LongestStreak int    `json:"longestStreak"`
+}
+
+// GoalRequest sets the goal for a metric and period, replacing any existing
+// one. The timezone defaults to UTC.
+type GoalRequest struct {
+	Metric   Metric `json:"metric"`
+	Period   Period `json:"period"`
+	Target   int    `json:"target"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// DayActivity is one local day's reading
+type DayActivity struct {
+	Date        time.Time // midnight in the goal's timezone
+	WordsRead   int
+	SecondsRead int
+}
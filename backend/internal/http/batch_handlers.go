@@ -0,0 +1,186 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+)
+
+// maxClientIDLength caps the client-side IDs echoed back in batch results
+const maxClientIDLength = 128
+
+// BatchCreateItem is one document in a batch create request. ClientID is the
+// caller's own ID for it, used to match up the result.
+type BatchCreateItem struct {
+	ClientID string `json:"clientId"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+}
+
+// BatchCreateRequest represents the request body for creating documents in bulk
+type BatchCreateRequest struct {
+	Documents []BatchCreateItem `json:"documents"`
+}
+
+// Outcomes of one item in a batch create
+const (
+	batchCreated   = "created"   // new document, still processing
+	batchExisting  = "existing"  // the user already had the text; document is theirs
+	batchDuplicate = "duplicate" // the user already had the text and duplicate=reject
+	batchInvalid   = "invalid"   // the item failed validation
+	batchFailed    = "failed"    // the document couldn't be created
+)
+
+// BatchCreateItemResult is the outcome for one item, in request order
+type BatchCreateItemResult struct {
+	ClientID   string              `json:"clientId"`
+	Status     string              `json:"status"`
+	Document   *documents.Document `json:"document,omitempty"`
+	DocumentID *uuid.UUID          `json:"documentId,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// BatchCreateResponse lists the outcome of every item in a batch
+type BatchCreateResponse struct {
+	Results []BatchCreateItemResult `json:"results"`
+}
+
+// CreateDocumentsBatch handles POST /api/documents/batch
+// Creates up to documents.MaxBatchCreate documents. Each item is validated and
+// created on its own, so one bad item doesn't fail the rest; the response has
+// a result per item in request order. ?duplicate= applies to every item, as
+// for a single create.
+func (h *Handlers) CreateDocumentsBatch(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	policy, ok := parseDuplicatePolicy(r.URL.Query().Get("duplicate"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "duplicate must be existing, reject or allow")
+		return
+	}
+
+	var req BatchCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if len(req.Documents) == 0 {
+		writeError(w, http.StatusBadRequest, "documents is required")
+		return
+	}
+	if len(req.Documents) > documents.MaxBatchCreate {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d documents per batch", documents.MaxBatchCreate))
+		return
+	}
+
+	results := make([]BatchCreateItemResult, len(req.Documents))
+	var inputs []*documents.CreateDocumentInput
+	var positions []int // index in results of each input
+
+	seen := make(map[string]bool, len(req.Documents))
+	sizeLimit := contentSizeLimit(r)
+	for i, item := range req.Documents {
+		results[i].ClientID = item.ClientID
+		if msg := validateBatchItem(item, seen, sizeLimit); msg != "" {
+			results[i].Status = batchInvalid
+			results[i].Error = msg
+			continue
+		}
+		seen[item.ClientID] = true
+
+		title := strings.TrimSpace(item.Title)
+		if title == "" {
+			title = generateTitleFromContent(item.Content, 6)
+		}
+		inputs = append(inputs, &documents.CreateDocumentInput{
+			Title:          title,
+			Content:        item.Content,
+			AllowDuplicate: policy == duplicateAllow,
+		})
+		positions = append(positions, i)
+	}
+
+	if len(inputs) > 0 {
+		created, err := h.docService.CreateDocuments(r.Context(), inputs)
+		if err != nil {
+			if we != nil {
+				we.AddError(err)
+			}
+			writeError(w, http.StatusInternalServerError, "failed to create documents")
+			return
+		}
+		for j, res := range created {
+			results[positions[j]] = batchItemResult(results[positions[j]].ClientID, res, policy)
+		}
+	}
+
+	if we != nil {
+		counts := make(map[string]int)
+		for _, res := range results {
+			counts[res.Status]++
+		}
+		we.AddInt("batch.size", len(results))
+		we.AddInt("batch.created", counts[batchCreated])
+		we.AddInt("batch.existing", counts[batchExisting]+counts[batchDuplicate])
+		we.AddInt("batch.failed", counts[batchInvalid]+counts[batchFailed])
+	}
+
+	writeJSON(w, http.StatusOK, BatchCreateResponse{Results: results})
+}
+
+// validateBatchItem checks one batch item, returning why it's invalid or ""
+// if it isn't. seen holds the client IDs of earlier valid items.
+func validateBatchItem(item BatchCreateItem, seen map[string]bool, sizeLimit int) string {
+	switch {
+	case item.ClientID == "":
+		return "clientId is required"
+	case len(item.ClientID) > maxClientIDLength:
+		return fmt.Sprintf("clientId exceeds %d characters", maxClientIDLength)
+	case seen[item.ClientID]:
+		return "clientId is repeated in the batch"
+	case item.Content == "":
+		return "content is required"
+	case len(item.Content) > sizeLimit:
+		return "content exceeds maximum size"
+	}
+	return ""
+}
+
+// batchItemResult describes the service's outcome for one item
+func batchItemResult(clientID string, res documents.BatchCreateResult, policy string) BatchCreateItemResult {
+	result := BatchCreateItemResult{ClientID: clientID}
+
+	var dupErr *documents.DuplicateError
+	switch {
+	case errors.As(res.Err, &dupErr):
+		id := dupErr.Existing.ID
+		result.DocumentID = &id
+		if policy == duplicateReject {
+			result.Status = batchDuplicate
+			result.Error = "duplicate document"
+		} else {
+			result.Status = batchExisting
+			result.Document = dupErr.Existing
+		}
+	case res.Err != nil:
+		result.Status = batchFailed
+		result.Error = "failed to create document"
+	default:
+		id := res.Document.ID
+		result.Status = batchCreated
+		result.Document = res.Document
+		result.DocumentID = &id
+	}
+
+	return result
+}
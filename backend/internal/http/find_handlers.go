@@ -0,0 +1,106 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+)
+
+// FindInDocument handles GET /api/documents/:id/find?q=
+// Matches are returned as token indices with surrounding context
+func (h *Handlers) FindInDocument(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid document ID")
+		return
+	}
+
+	if we != nil {
+		we.AddString("doc.id", id.String())
+	}
+
+	query, limit, ok := parseFindParams(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.docService.FindInDocument(r.Context(), id, query, limit)
+	if err != nil {
+		h.writeFindError(w, r, err)
+		return
+	}
+
+	if we != nil {
+		we.AddInt("find.total", result.Total)
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// FindInSharedDocument handles GET /api/shared/:token/find?q=
+func (h *Handlers) FindInSharedDocument(w http.ResponseWriter, r *http.Request) {
+	token, err := uuid.Parse(chi.URLParam(r, "token"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid share token")
+		return
+	}
+
+	query, limit, ok := parseFindParams(w, r)
+	if !ok {
+		return
+	}
+
+	doc, err := h.sharingService.GetDocumentByShareToken(r.Context(), token)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "shared document not found")
+		return
+	}
+
+	result, err := h.docService.FindInChunks(doc.ID, doc.ChunkCount, query, limit)
+	if err != nil {
+		h.writeFindError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// parseFindParams reads the query and limit, writing a 400 response if either is invalid
+func parseFindParams(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	query := r.URL.Query().Get("q")
+	if len(query) > documents.MaxSearchQueryLength {
+		writeError(w, http.StatusBadRequest, "find query is too long")
+		return "", 0, false
+	}
+
+	limit := documents.DefaultFindLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return "", 0, false
+		}
+		limit = min(n, documents.MaxFindLimit)
+	}
+
+	return query, limit, true
+}
+
+func (h *Handlers) writeFindError(w http.ResponseWriter, r *http.Request, err error) {
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddError(err)
+	}
+
+	if errors.Is(err, documents.ErrEmptyFindQuery) {
+		writeError(w, http.StatusBadRequest, "find query is required")
+		return
+	}
+	writeError(w, http.StatusNotFound, "document not found")
+}
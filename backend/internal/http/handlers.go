@@ -2,9 +2,12 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/go-chi/chi/v5"
@@ -12,8 +15,10 @@ import (
 	"github.com/mikepersonal/speed-reader/backend/internal/auth"
 	"github.com/mikepersonal/speed-reader/backend/internal/config"
 	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/extract"
 	"github.com/mikepersonal/speed-reader/backend/internal/logging"
 	"github.com/mikepersonal/speed-reader/backend/internal/sharing"
+	"github.com/mikepersonal/speed-reader/backend/internal/webfetch"
 	"golang.org/x/exp/slog"
 )
 
@@ -21,15 +26,17 @@ import (
 type Handlers struct {
 	docService     *documents.Service
 	sharingService *sharing.Service
+	fetcher        *webfetch.Fetcher
 	logger         *slog.Logger
 	sanitizer      *logging.Sanitizer
 }
 
 // NewHandlers creates a new Handlers instance
-func NewHandlers(docService *documents.Service, sharingService *sharing.Service, logger *slog.Logger, sanitizer *logging.Sanitizer) *Handlers {
+func NewHandlers(docService *documents.Service, sharingService *sharing.Service, fetcher *webfetch.Fetcher, logger *slog.Logger, sanitizer *logging.Sanitizer) *Handlers {
 	return &Handlers{
 		docService:     docService,
 		sharingService: sharingService,
+		fetcher:        fetcher,
 		logger:         logger,
 		sanitizer:      sanitizer,
 	}
@@ -43,9 +50,22 @@ type CreateDocumentRequest struct {
 
 // UpdateReadingStateRequest represents the request body for updating reading state
 type UpdateReadingStateRequest struct {
-	TokenIndex int `json:"tokenIndex"`
-	WPM        int `json:"wpm"`
-	ChunkSize  int `json:"chunkSize"`
+	TokenIndex int    `json:"tokenIndex"`
+	WPM        int    `json:"wpm"`
+	ChunkSize  int    `json:"chunkSize"`
+	Client     string `json:"client,omitempty"` // web, mobile, desktop or extension
+
+	// Optional guards against rewinding a newer position: the version last
+	// read from the server, and when the client reached this position
+	Version         *int64     `json:"version,omitempty"`
+	ClientUpdatedAt *time.Time `json:"clientUpdatedAt,omitempty"`
+}
+
+// StaleReadingStateResponse is the 409 body when a reading state update is
+// older than the saved one, which it returns for the client to adopt
+type StaleReadingStateResponse struct {
+	Error   string                  `json:"error"`
+	Current *documents.ReadingState `json:"current"`
 }
 
 // UpdateDocumentRequest represents the request body for updating a document
@@ -82,6 +102,14 @@ func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, ErrorResponse{Error: message})
 }
 
+// contentSizeLimit returns the maximum document text size for the requesting user
+func contentSizeLimit(r *http.Request) int {
+	if user, ok := auth.UserFromContext(r.Context()); ok && !user.IsGuest {
+		return config.MaxAuthPasteSize
+	}
+	return config.MaxGuestPasteSize
+}
+
 // generateTitleFromContent creates a title from the first few words of content
 func generateTitleFromContent(content string, maxWords int) string {
 	// Split content into words
@@ -111,9 +139,30 @@ func generateTitleFromContent(content string, maxWords int) string {
 }
 
 // CreateDocument handles POST /api/documents
+// A text/html body is treated as a web page: the main article is extracted and
+// the optional "title" query parameter overrides the page title.
+// ?duplicate= handles text the user already has, as in createDocumentAndRespond.
 func (h *Handlers) CreateDocument(w http.ResponseWriter, r *http.Request) {
 	we := logging.WideEventFromContext(r.Context())
 
+	if contentType := r.Header.Get("Content-Type"); strings.HasPrefix(contentType, "text/html") {
+		extracted, err := extract.HTML(r.Body, contentType)
+		if err != nil {
+			if we != nil {
+				we.AddError(err)
+			}
+			writeError(w, http.StatusBadRequest, "invalid html")
+			return
+		}
+		if strings.TrimSpace(extracted.Text()) == "" {
+			writeError(w, http.StatusUnprocessableEntity, "no article text found")
+			return
+		}
+
+		h.createExtractedDocument(w, r, extracted, r.URL.Query().Get("title"), "")
+		return
+	}
+
 	var req CreateDocumentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		if we != nil {
@@ -141,8 +190,68 @@ func (h *Handlers) CreateDocument(w http.ResponseWriter, r *http.Request) {
 		we.AddInt("doc.content_length", len(req.Content))
 	}
 
-	doc, err := h.docService.CreateDocument(r.Context(), title, req.Content)
-	if err != nil {
+	h.createDocumentAndRespond(w, r, &documents.CreateDocumentInput{
+		Title:   title,
+		Content: req.Content,
+	})
+}
+
+// Values of ?duplicate= on document creation
+const (
+	duplicateExisting = "existing"
+	duplicateReject   = "reject"
+	duplicateAllow    = "allow"
+)
+
+// DuplicateDocumentResponse is the 409 body when a new document duplicates one
+// the user already has
+type DuplicateDocumentResponse struct {
+	Error      string    `json:"error"`
+	DocumentID uuid.UUID `json:"documentId"`
+}
+
+// parseDuplicatePolicy reads ?duplicate=, defaulting to returning the existing document
+func parseDuplicatePolicy(v string) (string, bool) {
+	switch v {
+	case "":
+		return duplicateExisting, true
+	case duplicateExisting, duplicateReject, duplicateAllow:
+		return v, true
+	default:
+		return "", false
+	}
+}
+
+// createDocumentAndRespond creates a document and writes the response.
+// ?duplicate= decides what happens when the user already has the same text:
+// "existing" (the default) returns that document with 200, "reject" responds
+// 409 with its ID, and "allow" creates another copy.
+func (h *Handlers) createDocumentAndRespond(w http.ResponseWriter, r *http.Request, input *documents.CreateDocumentInput) {
+	we := logging.WideEventFromContext(r.Context())
+
+	policy, ok := parseDuplicatePolicy(r.URL.Query().Get("duplicate"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "duplicate must be existing, reject or allow")
+		return
+	}
+	input.AllowDuplicate = policy == duplicateAllow
+
+	doc, err := h.docService.CreateDocument(r.Context(), input)
+	var dupErr *documents.DuplicateError
+	switch {
+	case errors.As(err, &dupErr):
+		existing := dupErr.Existing
+		if we != nil {
+			we.AddString("doc.duplicate_of", existing.ID.String())
+		}
+		if policy == duplicateReject {
+			writeJSON(w, http.StatusConflict, DuplicateDocumentResponse{Error: "duplicate document", DocumentID: existing.ID})
+			return
+		}
+		w.Header().Set("Location", "/api/documents/"+existing.ID.String())
+		writeJSON(w, http.StatusOK, existing)
+		return
+	case err != nil:
 		if we != nil {
 			we.AddError(err)
 		}
@@ -153,11 +262,42 @@ func (h *Handlers) CreateDocument(w http.ResponseWriter, r *http.Request) {
 	// Log document metrics
 	if we != nil {
 		we.AddString("doc.id", doc.ID.String())
-		we.AddInt("doc.token_count", doc.TokenCount)
-		we.AddInt("doc.chunk_count", doc.ChunkCount)
 	}
 
-	writeJSON(w, http.StatusCreated, doc)
+	// Tokenization happens in the background; clients poll the status endpoint
+	w.Header().Set("Location", "/api/documents/"+doc.ID.String()+"/status")
+	writeJSON(w, http.StatusAccepted, doc)
+}
+
+// GetDocumentStatus handles GET /api/documents/:id/status
+func (h *Handlers) GetDocumentStatus(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid document ID")
+		return
+	}
+
+	if we != nil {
+		we.AddString("doc.id", id.String())
+	}
+
+	status, err := h.docService.GetProcessingStatus(r.Context(), id)
+	if err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusNotFound, "document not found")
+		return
+	}
+
+	if we != nil {
+		we.AddString("doc.status", string(status.Status))
+	}
+
+	writeJSON(w, http.StatusOK, status)
 }
 
 // GetDocument handles GET /api/documents/:id
@@ -184,6 +324,12 @@ func (h *Handlers) GetDocument(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The estimate is a convenience; the document is still returned without it
+	if err := h.docService.FillRemainingTime(r.Context(), doc); err != nil && we != nil {
+		we.AddError(err)
+	}
+
+	w.Header().Set("ETag", documentETag(doc.Version))
 	writeJSON(w, http.StatusOK, doc)
 }
 
@@ -249,6 +395,9 @@ func (h *Handlers) GetReadingState(w http.ResponseWriter, r *http.Request) {
 }
 
 // UpdateReadingState handles PUT /api/documents/:id/reading-state
+// Returns the saved state with its new version. When the request carries a
+// version or client timestamp and the saved position is newer, nothing is
+// written and a 409 returns the saved state instead.
 func (h *Handlers) UpdateReadingState(w http.ResponseWriter, r *http.Request) {
 	we := logging.WideEventFromContext(r.Context())
 
@@ -275,17 +424,25 @@ func (h *Handlers) UpdateReadingState(w http.ResponseWriter, r *http.Request) {
 		ChunkSize:  req.ChunkSize,
 	}
 
+	cond := documents.WriteCondition{Version: req.Version, ClientTime: req.ClientUpdatedAt}
+
 	// Log reading progress metrics
 	if we != nil {
 		we.AddString("doc.id", id.String())
 		we.AddInt("reading.token_index", req.TokenIndex)
 		we.AddInt("reading.wpm", req.WPM)
+		we.AddBool("reading.conditional", req.Version != nil || req.ClientUpdatedAt != nil)
 	}
 
-	if err := h.docService.UpdateReadingState(r.Context(), state); err != nil {
+	if err := h.docService.UpdateReadingState(r.Context(), state, documents.ParseClientType(req.Client), cond); err != nil {
 		if we != nil {
 			we.AddError(err)
 		}
+		var staleErr *documents.StaleReadingStateError
+		if errors.As(err, &staleErr) {
+			writeJSON(w, http.StatusConflict, StaleReadingStateResponse{Error: "stale reading state", Current: staleErr.Current})
+			return
+		}
 		writeError(w, http.StatusNotFound, "document not found")
 		return
 	}
@@ -293,19 +450,163 @@ func (h *Handlers) UpdateReadingState(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, state)
 }
 
+// maxTagFilters caps how many tags a document list can be filtered by
+const maxTagFilters = 10
+
 // ListDocuments handles GET /api/documents
+// Supports ?sort=, ?order=asc|desc, ?limit= and ?cursor= for paging, and the
+// filters tag (repeatable, documents must carry every tag), collection, status,
+// reading (unread|in_progress|finished), visibility and hasContent. When more
+// documents follow a page, the cursor for the next one is in X-Next-Cursor.
 func (h *Handlers) ListDocuments(w http.ResponseWriter, r *http.Request) {
-	docs, err := h.docService.ListDocuments(r.Context())
+	we := logging.WideEventFromContext(r.Context())
+
+	opts, err := parseListOptions(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	docs, next, err := h.docService.ListDocuments(r.Context(), opts)
 	if err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		if errors.Is(err, documents.ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "failed to list documents")
 		return
 	}
 
+	if we != nil {
+		we.AddInt("list.count", len(docs))
+		we.AddBool("list.has_more", next != "")
+	}
+
+	if next != "" {
+		w.Header().Set("X-Next-Cursor", next)
+	}
 	writeJSON(w, http.StatusOK, docs)
 }
 
+// parseListOptions reads document list filters, sorting and paging from the
+// query string. Errors carry a message suitable for the client.
+func parseListOptions(query url.Values) (documents.ListOptions, error) {
+	var opts documents.ListOptions
+
+	tagStrs := query["tag"]
+	if len(tagStrs) > maxTagFilters {
+		return opts, errors.New("too many tag filters")
+	}
+	for _, tagStr := range tagStrs {
+		tagID, err := uuid.Parse(tagStr)
+		if err != nil {
+			return opts, errors.New("invalid tag ID")
+		}
+		opts.TagIDs = append(opts.TagIDs, tagID)
+	}
+
+	if collectionStr := query.Get("collection"); collectionStr != "" {
+		collectionID, err := uuid.Parse(collectionStr)
+		if err != nil {
+			return opts, errors.New("invalid collection ID")
+		}
+		opts.CollectionID = &collectionID
+	}
+
+	switch status := documents.DocumentStatus(query.Get("status")); status {
+	case "", documents.StatusPending, documents.StatusProcessing, documents.StatusReady, documents.StatusError:
+		opts.Status = status
+	default:
+		return opts, errors.New("invalid status")
+	}
+
+	opts.Reading = documents.ReadingFilter(query.Get("reading"))
+	if opts.Reading != "" && !documents.ValidReadingFilter(opts.Reading) {
+		return opts, errors.New("reading must be 'unread', 'in_progress' or 'finished'")
+	}
+
+	switch visibility := documents.Visibility(query.Get("visibility")); visibility {
+	case "", documents.VisibilityPrivate, documents.VisibilityPublic:
+		opts.Visibility = visibility
+	default:
+		return opts, errors.New("visibility must be 'private' or 'public'")
+	}
+
+	if hasContentStr := query.Get("hasContent"); hasContentStr != "" {
+		hasContent, err := strconv.ParseBool(hasContentStr)
+		if err != nil {
+			return opts, errors.New("invalid hasContent")
+		}
+		opts.HasContent = &hasContent
+	}
+
+	opts.Sort = documents.ListSort(query.Get("sort"))
+	if opts.Sort != "" && !documents.ValidSort(opts.Sort) {
+		return opts, errors.New("sort must be one of created, title, last_read, progress or length")
+	}
+
+	switch query.Get("order") {
+	case "":
+	case "asc":
+		ascending := true
+		opts.Ascending = &ascending
+	case "desc":
+		ascending := false
+		opts.Ascending = &ascending
+	default:
+		return opts, errors.New("order must be 'asc' or 'desc'")
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return opts, errors.New("invalid limit")
+		}
+		opts.Limit = min(limit, documents.MaxListLimit)
+	}
+
+	opts.Cursor = query.Get("cursor")
+	if opts.Cursor != "" && opts.Limit == 0 {
+		opts.Limit = documents.MaxListLimit
+	}
+
+	return opts, nil
+}
+
+// documentETag is the ETag of a document at a version
+func documentETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch reads an If-Match header into the document version an edit is
+// conditional on, nil if it's absent or "*". Weak tags are accepted, since a
+// proxy may have weakened the ETag the client saw. ok is false for tags that
+// can't name a version, which never match.
+func parseIfMatch(header string) (expected *int64, ok bool) {
+	tag := strings.TrimSpace(header)
+	if tag == "" || tag == "*" {
+		return nil, true
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return nil, false
+	}
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	return &version, true
+}
+
 // UpdateDocument handles PUT /api/documents/:id
-// If content is provided, the document will be re-tokenized and reading progress will be reset
+// If content is provided, the document is queued to be re-tokenized and the response is 202 Accepted with the
+// pending document; reading positions are carried over to the new text once it's processed. Documents that are
+// still being processed can't be edited and get 409 Conflict.
+// With If-Match set to an ETag from GetDocument or GetDocumentContent, the update only applies if nobody has
+// edited the document since, and gets 412 Precondition Failed otherwise.
 func (h *Handlers) UpdateDocument(w http.ResponseWriter, r *http.Request) {
 	we := logging.WideEventFromContext(r.Context())
 
@@ -316,6 +617,13 @@ func (h *Handlers) UpdateDocument(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ifMatch := r.Header.Get("If-Match")
+	expected, ok := parseIfMatch(ifMatch)
+	if !ok {
+		writeError(w, http.StatusPreconditionFailed, "document was modified")
+		return
+	}
+
 	var req UpdateDocumentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		if we != nil {
@@ -333,6 +641,7 @@ func (h *Handlers) UpdateDocument(w http.ResponseWriter, r *http.Request) {
 	if we != nil {
 		we.AddString("doc.id", id.String())
 		we.AddBool("doc.content_update", req.Content != nil)
+		we.AddBool("doc.conditional", ifMatch != "")
 	}
 
 	// If content is provided, do a full content update (re-tokenize)
@@ -343,12 +652,7 @@ func (h *Handlers) UpdateDocument(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Enforce content size limit (same as create)
-		user, _ := auth.UserFromContext(r.Context())
-		maxSize := config.MaxGuestPasteSize
-		if user != nil && !user.IsGuest {
-			maxSize = config.MaxAuthPasteSize
-		}
-		if len(*req.Content) > maxSize {
+		if len(*req.Content) > contentSizeLimit(r) {
 			writeError(w, http.StatusBadRequest, "content exceeds maximum size")
 			return
 		}
@@ -357,21 +661,24 @@ func (h *Handlers) UpdateDocument(w http.ResponseWriter, r *http.Request) {
 			we.AddInt("doc.content_length", len(*req.Content))
 		}
 
-		doc, err := h.docService.UpdateDocumentContent(r.Context(), id, req.Title, *req.Content)
+		doc, err := h.docService.UpdateDocumentContent(r.Context(), id, req.Title, *req.Content, expected)
 		if err != nil {
 			if we != nil {
 				we.AddError(err)
 			}
-			writeError(w, http.StatusInternalServerError, "failed to update document")
+			switch {
+			case errors.Is(err, documents.ErrVersionMismatch):
+				writeError(w, http.StatusPreconditionFailed, "document was modified")
+			case errors.Is(err, documents.ErrDocumentProcessing):
+				writeError(w, http.StatusConflict, "document is still being processed")
+			default:
+				writeError(w, http.StatusInternalServerError, "failed to update document")
+			}
 			return
 		}
 
-		if we != nil {
-			we.AddInt("doc.token_count", doc.TokenCount)
-			we.AddInt("doc.chunk_count", doc.ChunkCount)
-		}
-
-		writeJSON(w, http.StatusOK, doc)
+		w.Header().Set("ETag", documentETag(doc.Version))
+		writeJSON(w, http.StatusAccepted, doc)
 		return
 	}
 
@@ -380,11 +687,18 @@ func (h *Handlers) UpdateDocument(w http.ResponseWriter, r *http.Request) {
 		we.AddString("doc.title", h.sanitizer.DocumentTitle(req.Title))
 	}
 
-	if err := h.docService.UpdateDocumentTitle(r.Context(), id, req.Title); err != nil {
+	if err := h.docService.UpdateDocumentTitle(r.Context(), id, req.Title, expected); err != nil {
 		if we != nil {
 			we.AddError(err)
 		}
-		writeError(w, http.StatusNotFound, "document not found")
+		switch {
+		case errors.Is(err, documents.ErrVersionMismatch):
+			writeError(w, http.StatusPreconditionFailed, "document was modified")
+		case errors.Is(err, documents.ErrDocumentProcessing):
+			writeError(w, http.StatusConflict, "document is still being processed")
+		default:
+			writeError(w, http.StatusNotFound, "document not found")
+		}
 		return
 	}
 
@@ -398,6 +712,7 @@ func (h *Handlers) UpdateDocument(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", documentETag(doc.Version))
 	writeJSON(w, http.StatusOK, doc)
 }
 
@@ -416,7 +731,7 @@ func (h *Handlers) GetDocumentContent(w http.ResponseWriter, r *http.Request) {
 		we.AddString("doc.id", id.String())
 	}
 
-	content, hasContent, err := h.docService.GetDocumentContent(r.Context(), id)
+	content, err := h.docService.GetDocumentContent(r.Context(), id)
 	if err != nil {
 		if we != nil {
 			we.AddError(err)
@@ -426,15 +741,16 @@ func (h *Handlers) GetDocumentContent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if we != nil {
-		we.AddBool("doc.has_content", hasContent)
-		if hasContent {
-			we.AddInt("doc.content_length", len(content))
+		we.AddBool("doc.has_content", content.HasContent)
+		if content.HasContent {
+			we.AddInt("doc.content_length", len(content.Content))
 		}
 	}
 
+	w.Header().Set("ETag", documentETag(content.Version))
 	writeJSON(w, http.StatusOK, GetContentResponse{
-		Content:    content,
-		HasContent: hasContent,
+		Content:    content.Content,
+		HasContent: content.HasContent,
 	})
 }
 
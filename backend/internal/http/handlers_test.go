@@ -2,10 +2,20 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
 )
 
 func TestWriteJSON(t *testing.T) {
@@ -172,3 +182,320 @@ func TestGenerateTitleFromContent(t *testing.T) {
 		})
 	}
 }
+
+func TestParseListOptions(t *testing.T) {
+	tagID := uuid.New()
+	query := url.Values{
+		"tag":        {tagID.String()},
+		"status":     {"ready"},
+		"reading":    {"in_progress"},
+		"visibility": {"public"},
+		"hasContent": {"true"},
+		"sort":       {"title"},
+		"order":      {"desc"},
+		"limit":      {"1000"},
+	}
+
+	opts, err := parseListOptions(query)
+	if err != nil {
+		t.Fatalf("parseListOptions failed: %v", err)
+	}
+	if len(opts.TagIDs) != 1 || opts.TagIDs[0] != tagID {
+		t.Errorf("unexpected tags: %v", opts.TagIDs)
+	}
+	if opts.Status != documents.StatusReady || opts.Reading != documents.ReadingInProgress || opts.Visibility != documents.VisibilityPublic {
+		t.Errorf("unexpected filters: %+v", opts)
+	}
+	if opts.HasContent == nil || !*opts.HasContent {
+		t.Error("expected hasContent filter")
+	}
+	if opts.Sort != documents.SortTitle || opts.Ascending == nil || *opts.Ascending {
+		t.Errorf("unexpected sort: %q %v", opts.Sort, opts.Ascending)
+	}
+	if opts.Limit != documents.MaxListLimit {
+		t.Errorf("expected limit capped at %d, got %d", documents.MaxListLimit, opts.Limit)
+	}
+}
+
+func TestParseListOptions_Defaults(t *testing.T) {
+	opts, err := parseListOptions(url.Values{})
+	if err != nil {
+		t.Fatalf("parseListOptions failed: %v", err)
+	}
+	if opts.Limit != 0 || opts.Sort != "" || opts.Ascending != nil || opts.HasContent != nil {
+		t.Errorf("expected unpaged default listing, got %+v", opts)
+	}
+}
+
+func TestParseListOptions_Invalid(t *testing.T) {
+	tests := []url.Values{
+		{"tag": {"nope"}},
+		{"collection": {"nope"}},
+		{"status": {"done"}},
+		{"reading": {"halfway"}},
+		{"visibility": {"friends"}},
+		{"hasContent": {"maybe"}},
+		{"sort": {"random"}},
+		{"order": {"sideways"}},
+		{"limit": {"0"}},
+	}
+
+	for _, query := range tests {
+		if _, err := parseListOptions(query); err == nil {
+			t.Errorf("expected error for %v", query)
+		}
+	}
+}
+
+func TestWriteTrashError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{documents.ErrNotInTrash, http.StatusNotFound},
+		{fmt.Errorf("restore: %w", documents.ErrNotInTrash), http.StatusNotFound},
+		{errors.New("connection reset"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/trash/x/restore", nil)
+
+		writeTrashError(w, r, tt.err, "failed to restore document")
+
+		if w.Code != tt.status {
+			t.Errorf("%v: expected status %d, got %d", tt.err, tt.status, w.Code)
+		}
+	}
+}
+
+func TestWriteHighlightError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("%w: note is too long", documents.ErrInvalidHighlight), http.StatusBadRequest},
+		{documents.ErrHighlightLimit, http.StatusConflict},
+		{documents.ErrHighlightNotFound, http.StatusNotFound},
+		{errors.New("access denied: not the owner"), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/documents/x/highlights", nil)
+
+		writeHighlightError(w, r, tt.err)
+
+		if w.Code != tt.status {
+			t.Errorf("%v: expected status %d, got %d", tt.err, tt.status, w.Code)
+		}
+	}
+}
+
+func TestParseSessionListOptions(t *testing.T) {
+	opts, err := parseSessionListOptions(url.Values{
+		"from":  {"2026-03-01"},
+		"to":    {"2026-03-07"},
+		"tz":    {"America/New_York"},
+		"limit": {"1000"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ny, _ := time.LoadLocation("America/New_York")
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, ny); !opts.From.Equal(want) {
+		t.Errorf("expected from %v, got %v", want, opts.From)
+	}
+	// The to date is inclusive
+	if want := time.Date(2026, 3, 8, 0, 0, 0, 0, ny); !opts.To.Equal(want) {
+		t.Errorf("expected to %v, got %v", want, opts.To)
+	}
+	if opts.Limit != documents.MaxSessionLimit {
+		t.Errorf("expected limit capped at %d, got %d", documents.MaxSessionLimit, opts.Limit)
+	}
+
+	opts, err = parseSessionListOptions(url.Values{"to": {"2026-03-01T15:04:05Z"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, 3, 1, 15, 4, 5, 0, time.UTC); !opts.To.Equal(want) {
+		t.Errorf("expected timestamps to be used as given, got %v", opts.To)
+	}
+}
+
+func TestParseSessionListOptions_Invalid(t *testing.T) {
+	tests := []url.Values{
+		{"from": {"yesterday"}},
+		{"to": {"03/01/2026"}},
+		{"tz": {"Mars/Olympus"}},
+		{"from": {"2026-03-02"}, "to": {"2026-03-01"}},
+		{"doc": {"nope"}},
+		{"limit": {"-1"}},
+	}
+
+	for _, query := range tests {
+		if _, err := parseSessionListOptions(query); err == nil {
+			t.Errorf("expected error for %v", query)
+		}
+	}
+}
+
+func TestParseDuplicatePolicy(t *testing.T) {
+	tests := map[string]string{
+		"":         duplicateExisting,
+		"existing": duplicateExisting,
+		"reject":   duplicateReject,
+		"allow":    duplicateAllow,
+	}
+
+	for input, want := range tests {
+		got, ok := parseDuplicatePolicy(input)
+		if !ok || got != want {
+			t.Errorf("parseDuplicatePolicy(%q) = %q, %v; want %q", input, got, ok, want)
+		}
+	}
+
+	if _, ok := parseDuplicatePolicy("skip"); ok {
+		t.Error("expected unknown policy to be rejected")
+	}
+}
+
+func TestValidateBatchItem(t *testing.T) {
+	seen := map[string]bool{"local-1": true}
+
+	tests := []struct {
+		item BatchCreateItem
+		want string
+	}{
+		{BatchCreateItem{ClientID: "local-2", Content: "text"}, ""},
+		{BatchCreateItem{Content: "text"}, "clientId is required"},
+		{BatchCreateItem{ClientID: strings.Repeat("x", maxClientIDLength+1), Content: "text"}, "clientId exceeds 128 characters"},
+		{BatchCreateItem{ClientID: "local-1", Content: "text"}, "clientId is repeated in the batch"},
+		{BatchCreateItem{ClientID: "local-3"}, "content is required"},
+		{BatchCreateItem{ClientID: "local-4", Content: "too long"}, "content exceeds maximum size"},
+	}
+
+	for _, tt := range tests {
+		if got := validateBatchItem(tt.item, seen, 5); got != tt.want {
+			t.Errorf("validateBatchItem(%+v) = %q, want %q", tt.item, got, tt.want)
+		}
+	}
+}
+
+func TestBatchItemResult(t *testing.T) {
+	existing := &documents.Document{ID: uuid.New()}
+	dup := documents.BatchCreateResult{Err: &documents.DuplicateError{Existing: existing}}
+
+	res := batchItemResult("a", dup, duplicateExisting)
+	if res.Status != batchExisting || res.Document != existing || *res.DocumentID != existing.ID {
+		t.Errorf("existing policy: got %+v", res)
+	}
+
+	res = batchItemResult("a", dup, duplicateReject)
+	if res.Status != batchDuplicate || res.Document != nil || *res.DocumentID != existing.ID {
+		t.Errorf("reject policy: got %+v", res)
+	}
+
+	res = batchItemResult("b", documents.BatchCreateResult{Err: errors.New("db down")}, duplicateExisting)
+	if res.Status != batchFailed || res.Error != "failed to create document" || res.ClientID != "b" {
+		t.Errorf("failure: got %+v", res)
+	}
+
+	created := &documents.Document{ID: uuid.New()}
+	res = batchItemResult("c", documents.BatchCreateResult{Document: created}, duplicateAllow)
+	if res.Status != batchCreated || res.Document != created || *res.DocumentID != created.ID {
+		t.Errorf("created: got %+v", res)
+	}
+}
+
+func TestParseIfMatch(t *testing.T) {
+	for _, header := range []string{"", "*", " * "} {
+		expected, ok := parseIfMatch(header)
+		if !ok || expected != nil {
+			t.Errorf("parseIfMatch(%q) = %v, %v; want unconditional", header, expected, ok)
+		}
+	}
+
+	tests := map[string]int64{
+		documentETag(7): 7,
+		`W/"12"`:        12,
+		` "3" `:         3,
+	}
+	for header, want := range tests {
+		expected, ok := parseIfMatch(header)
+		if !ok || expected == nil || *expected != want {
+			t.Errorf("parseIfMatch(%q) = %v, %v; want %d", header, expected, ok, want)
+		}
+	}
+
+	for _, header := range []string{"7", `"abc"`, `"`, `"1", "2"`} {
+		if _, ok := parseIfMatch(header); ok {
+			t.Errorf("expected parseIfMatch(%q) to never match", header)
+		}
+	}
+}
+
+func TestWriteRevisionError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{documents.ErrRevisionNotFound, http.StatusNotFound},
+		{fmt.Errorf("restore: %w", documents.ErrRevisionNotFound), http.StatusNotFound},
+		{documents.ErrDocumentProcessing, http.StatusConflict},
+		{errors.New("connection reset"), http.StatusInternalServerError},
+	}
+
+	h := &Handlers{}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/documents/x/revisions", nil)
+
+		h.writeRevisionError(w, r, tt.err)
+
+		if w.Code != tt.status {
+			t.Errorf("%v: expected status %d, got %d", tt.err, tt.status, w.Code)
+		}
+	}
+}
+
+func TestParseRevisionParams(t *testing.T) {
+	docID, revisionID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name       string
+		id, revID  string
+		wantOK     bool
+		wantStatus int
+	}{
+		{"valid", docID.String(), revisionID.String(), true, http.StatusOK},
+		{"bad document ID", "not-a-uuid", revisionID.String(), false, http.StatusBadRequest},
+		{"bad revision ID", docID.String(), "not-a-uuid", false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tt.id)
+		rctx.URLParams.Add("revisionId", tt.revID)
+		r := httptest.NewRequest(http.MethodGet, "/api/documents/x/revisions/y", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+
+		gotID, gotRevisionID, ok := parseRevisionParams(w, r)
+
+		if ok != tt.wantOK {
+			t.Errorf("%s: expected ok %v, got %v", tt.name, tt.wantOK, ok)
+			continue
+		}
+		if !ok {
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, w.Code)
+			}
+			continue
+		}
+		if gotID != docID || gotRevisionID != revisionID {
+			t.Errorf("%s: expected IDs %s/%s, got %s/%s", tt.name, docID, revisionID, gotID, gotRevisionID)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+)
+
+// ListHighlights handles GET /api/documents/:id/highlights
+func (h *Handlers) ListHighlights(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid document ID")
+		return
+	}
+
+	highlights, err := h.docService.ListHighlights(r.Context(), id)
+	if err != nil {
+		writeHighlightError(w, r, err)
+		return
+	}
+
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddInt("highlight.count", len(highlights))
+	}
+
+	writeJSON(w, http.StatusOK, highlights)
+}
+
+// CreateHighlight handles POST /api/documents/:id/highlights
+func (h *Handlers) CreateHighlight(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid document ID")
+		return
+	}
+
+	var req documents.HighlightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	highlight, err := h.docService.CreateHighlight(r.Context(), id, &req)
+	if err != nil {
+		writeHighlightError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, highlight)
+}
+
+// UpdateHighlight handles PUT /api/documents/:id/highlights/:highlightId
+func (h *Handlers) UpdateHighlight(w http.ResponseWriter, r *http.Request) {
+	id, highlightID, ok := parseHighlightParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req documents.HighlightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	highlight, err := h.docService.UpdateHighlight(r.Context(), id, highlightID, &req)
+	if err != nil {
+		writeHighlightError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, highlight)
+}
+
+// DeleteHighlight handles DELETE /api/documents/:id/highlights/:highlightId
+func (h *Handlers) DeleteHighlight(w http.ResponseWriter, r *http.Request) {
+	id, highlightID, ok := parseHighlightParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.docService.DeleteHighlight(r.Context(), id, highlightID); err != nil {
+		writeHighlightError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseHighlightParams reads the document and highlight IDs from the URL,
+// writing a 400 response if either is invalid
+func parseHighlightParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid document ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	highlightID, err := uuid.Parse(chi.URLParam(r, "highlightId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid highlight ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddString("highlight.id", highlightID.String())
+	}
+
+	return id, highlightID, true
+}
+
+// writeHighlightError maps highlight errors to responses. Anything else means
+// the document itself couldn't be read.
+func writeHighlightError(w http.ResponseWriter, r *http.Request, err error) {
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddError(err)
+	}
+
+	switch {
+	case errors.Is(err, documents.ErrInvalidHighlight):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, documents.ErrHighlightLimit):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, documents.ErrHighlightNotFound):
+		writeError(w, http.StatusNotFound, "highlight not found")
+	default:
+		writeError(w, http.StatusNotFound, "document not found")
+	}
+}
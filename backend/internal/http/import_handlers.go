@@ -0,0 +1,194 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/extract"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+	"github.com/mikepersonal/speed-reader/backend/internal/webfetch"
+)
+
+// maxUploadMemory is how much of a multipart upload is buffered in memory before spilling to disk
+const maxUploadMemory = 8 << 20
+
+// ImportDocument handles POST /api/documents/import
+// Accepts a multipart "file" field and an optional "title" field overriding the extracted title
+func (h *Handlers) ImportDocument(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusBadRequest, "invalid upload")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+
+	if we != nil {
+		we.AddString("import.filename_ext", strings.ToLower(path.Ext(header.Filename)))
+		we.AddInt64("import.size", header.Size)
+	}
+
+	extracted, err := extract.File(header.Filename, file, header.Size)
+	if err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		switch {
+		case errors.Is(err, extract.ErrUnsupportedFormat):
+			writeError(w, http.StatusUnsupportedMediaType, "unsupported file type")
+		case errors.Is(err, extract.ErrScannedPDF):
+			writeError(w, http.StatusUnprocessableEntity, "PDF contains only scanned images with no selectable text; run it through OCR first")
+		case errors.Is(err, extract.ErrEncrypted):
+			writeError(w, http.StatusUnprocessableEntity, "password-protected files are not supported")
+		case errors.Is(err, extract.ErrNoText):
+			writeError(w, http.StatusUnprocessableEntity, "file contains no readable text")
+		default:
+			writeError(w, http.StatusUnprocessableEntity, "could not read file")
+		}
+		return
+	}
+
+	h.createExtractedDocument(w, r, extracted, r.FormValue("title"), "")
+}
+
+// ImportURLRequest represents the request body for importing a web page
+type ImportURLRequest struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// ImportURL handles POST /api/documents/import-url
+// Fetches a web page or PDF server-side, extracts its text and creates a document
+func (h *Handlers) ImportURL(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	var req ImportURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	target, err := webfetch.ParseURL(req.URL)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "url must be an absolute http or https URL")
+		return
+	}
+	if we != nil {
+		we.AddString("import.host", target.Hostname())
+	}
+
+	page, err := h.fetcher.Fetch(r.Context(), target.String())
+	if err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		var statusErr *webfetch.StatusError
+		switch {
+		case errors.Is(err, webfetch.ErrBlockedAddress):
+			writeError(w, http.StatusBadRequest, "url points to a private or reserved address")
+		case errors.Is(err, webfetch.ErrInvalidURL), errors.Is(err, webfetch.ErrTooManyRedirects):
+			writeError(w, http.StatusBadRequest, "url redirects too many times or to an unsupported location")
+		case errors.Is(err, webfetch.ErrTooLarge):
+			writeError(w, http.StatusRequestEntityTooLarge, "page exceeds maximum size")
+		case errors.Is(err, webfetch.ErrUnsupportedContent):
+			writeError(w, http.StatusUnsupportedMediaType, "url is not a web page or PDF")
+		case errors.As(err, &statusErr):
+			writeError(w, http.StatusBadGateway, fmt.Sprintf("page returned status %d", statusErr.StatusCode))
+		default:
+			writeError(w, http.StatusBadGateway, "could not fetch url")
+		}
+		return
+	}
+
+	if we != nil {
+		we.AddString("import.media_type", page.MediaType)
+		we.AddInt("import.size", len(page.Body))
+	}
+
+	var extracted *extract.Document
+	if page.MediaType == "application/pdf" {
+		extracted, err = extract.PDF(bytes.NewReader(page.Body), int64(len(page.Body)))
+	} else {
+		extracted, err = extract.HTML(bytes.NewReader(page.Body), page.ContentType)
+	}
+	if err == nil && strings.TrimSpace(extracted.Text()) == "" {
+		err = extract.ErrNoText
+	}
+	if err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		switch {
+		case errors.Is(err, extract.ErrScannedPDF):
+			writeError(w, http.StatusUnprocessableEntity, "PDF contains only scanned images with no selectable text; run it through OCR first")
+		case errors.Is(err, extract.ErrEncrypted):
+			writeError(w, http.StatusUnprocessableEntity, "password-protected files are not supported")
+		default:
+			writeError(w, http.StatusUnprocessableEntity, "page contains no readable text")
+		}
+		return
+	}
+
+	h.createExtractedDocument(w, r, extracted, req.Title, page.URL)
+}
+
+// createExtractedDocument creates a document from extracted text through the same
+// path as pasted content, duplicate handling included, and writes the response.
+// sourceURL records the page the text was fetched from, if any.
+func (h *Handlers) createExtractedDocument(w http.ResponseWriter, r *http.Request, extracted *extract.Document, titleOverride, sourceURL string) {
+	we := logging.WideEventFromContext(r.Context())
+
+	content := extracted.Text()
+	if len(content) > contentSizeLimit(r) {
+		writeError(w, http.StatusBadRequest, "extracted text exceeds maximum size")
+		return
+	}
+
+	title := strings.TrimSpace(titleOverride)
+	if title == "" {
+		title = extracted.Title
+	}
+	if title == "" {
+		title = generateTitleFromContent(content, 6)
+	}
+
+	if we != nil {
+		we.AddString("doc.source_type", extracted.Format)
+		we.AddString("doc.title", h.sanitizer.DocumentTitle(title))
+		we.AddInt("doc.content_length", len(content))
+		we.AddInt("import.section_count", len(extracted.Sections))
+	}
+
+	headings := make([]documents.Heading, 0, len(extracted.Sections))
+	for _, heading := range extracted.Headings() {
+		headings = append(headings, documents.Heading{Title: heading.Title, Offset: heading.Offset})
+	}
+
+	h.createDocumentAndRespond(w, r, &documents.CreateDocumentInput{
+		Title:      title,
+		Content:    content,
+		SourceType: documents.SourceType(extracted.Format),
+		Author:     extracted.Author,
+		SourceURL:  sourceURL,
+		Headings:   headings,
+	})
+}
@@ -30,6 +30,27 @@ func ContextAwareMaxBodySize(next http.Handler) http.Handler {
 	})
 }
 
+// ContextAwareMaxUploadSize limits file upload size based on user type
+// Guest users get the paste limit, authenticated users get the larger upload limit
+func ContextAwareMaxUploadSize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := int64(config.MaxGuestPasteSize)
+		if user, ok := auth.UserFromContext(r.Context()); ok && !user.IsGuest {
+			limit = config.MaxUploadSize
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MaxAccountImportBodySize limits the size of an uploaded account export
+func MaxAccountImportBodySize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, config.MaxAccountImportSize)
+		next.ServeHTTP(w, r)
+	})
+}
+
 // SecurityHeaders adds security-related HTTP headers
 func SecurityHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -54,18 +75,42 @@ func SecurityHeaders(next http.Handler) http.Handler {
 
 // RequireJSONContentType validates that requests with bodies have JSON content type
 func RequireJSONContentType(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Skip for methods that don't have bodies
-		if r.Method == http.MethodGet || r.Method == http.MethodHead ||
-			r.Method == http.MethodOptions || r.Method == http.MethodDelete {
-			next.ServeHTTP(w, r)
-			return
-		}
+	return RequireContentType("application/json")(next)
+}
 
+// RequireContentType validates that requests with bodies use one of the allowed content types
+func RequireContentType(allowed ...string) func(http.Handler) http.Handler {
+	message := "Content-Type must be " + strings.Join(allowed, " or ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Skip for methods that don't have bodies
+			if r.Method == http.MethodGet || r.Method == http.MethodHead ||
+				r.Method == http.MethodOptions || r.Method == http.MethodDelete {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			contentType := r.Header.Get("Content-Type")
+			for _, t := range allowed {
+				if strings.HasPrefix(contentType, t) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			writeJSON(w, http.StatusUnsupportedMediaType, ErrorResponse{Error: message})
+		})
+	}
+}
+
+// RequireMultipartContentType validates that upload requests are multipart form data
+func RequireMultipartContentType(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		contentType := r.Header.Get("Content-Type")
-		if !strings.HasPrefix(contentType, "application/json") {
+		if !strings.HasPrefix(contentType, "multipart/form-data") {
 			writeJSON(w, http.StatusUnsupportedMediaType,
-				ErrorResponse{Error: "Content-Type must be application/json"})
+				ErrorResponse{Error: "Content-Type must be multipart/form-data"})
 			return
 		}
 		next.ServeHTTP(w, r)
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+)
+
+// AddToQueueRequest represents the request body for queueing a document
+type AddToQueueRequest struct {
+	DocumentID uuid.UUID `json:"documentId"`
+	Next       bool      `json:"next,omitempty"` // put it at the front rather than the end
+}
+
+// ReorderQueueRequest lists every queued document in its new order
+type ReorderQueueRequest struct {
+	DocumentIDs []uuid.UUID `json:"documentIds"`
+}
+
+// ListQueue handles GET /api/queue
+func (h *Handlers) ListQueue(w http.ResponseWriter, r *http.Request) {
+	items, err := h.docService.ListQueue(r.Context())
+	if err != nil {
+		if we := logging.WideEventFromContext(r.Context()); we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusInternalServerError, "failed to list queue")
+		return
+	}
+
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddInt("queue.length", len(items))
+	}
+
+	writeJSON(w, http.StatusOK, items)
+}
+
+// AddToQueue handles POST /api/queue
+// Queues a document at the end, or at the front with next set, and returns
+// the queue. Queueing a document that's already queued leaves it in place
+// unless next moves it to the front.
+func (h *Handlers) AddToQueue(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	var req AddToQueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.DocumentID == uuid.Nil {
+		writeError(w, http.StatusBadRequest, "documentId is required")
+		return
+	}
+
+	if we != nil {
+		we.AddString("doc.id", req.DocumentID.String())
+		we.AddBool("queue.next", req.Next)
+	}
+
+	if err := h.docService.AddToQueue(r.Context(), req.DocumentID, req.Next); err != nil {
+		writeQueueError(w, r, err)
+		return
+	}
+
+	h.ListQueue(w, r)
+}
+
+// ReorderQueue handles PUT /api/queue
+// Takes every queued document in the new order and returns the queue
+func (h *Handlers) ReorderQueue(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	var req ReorderQueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.DocumentIDs) > documents.MaxQueueLength {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d documents can be queued", documents.MaxQueueLength))
+		return
+	}
+
+	if err := h.docService.ReorderQueue(r.Context(), req.DocumentIDs); err != nil {
+		writeQueueError(w, r, err)
+		return
+	}
+
+	h.ListQueue(w, r)
+}
+
+// RemoveFromQueue handles DELETE /api/queue/:id
+func (h *Handlers) RemoveFromQueue(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid document ID")
+		return
+	}
+
+	if err := h.docService.RemoveFromQueue(r.Context(), id); err != nil {
+		writeQueueError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetQueueNext handles GET /api/queue/next
+// Returns the first queued document not yet finished, with the reading state
+// to resume it from. Finished documents leave the queue on their own.
+func (h *Handlers) GetQueueNext(w http.ResponseWriter, r *http.Request) {
+	h.queueNext(w, r, false)
+}
+
+// PopQueueNext handles POST /api/queue/pop
+// As GetQueueNext, but also takes the document out of the queue
+func (h *Handlers) PopQueueNext(w http.ResponseWriter, r *http.Request) {
+	h.queueNext(w, r, true)
+}
+
+func (h *Handlers) queueNext(w http.ResponseWriter, r *http.Request, pop bool) {
+	next, err := h.docService.NextInQueue(r.Context(), pop)
+	if err != nil {
+		writeQueueError(w, r, err)
+		return
+	}
+
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddString("doc.id", next.Document.ID.String())
+		we.AddBool("queue.pop", pop)
+	}
+
+	writeJSON(w, http.StatusOK, next)
+}
+
+// writeQueueError maps reading queue errors to responses
+func writeQueueError(w http.ResponseWriter, r *http.Request, err error) {
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddError(err)
+	}
+	switch {
+	case errors.Is(err, documents.ErrNotQueued):
+		writeError(w, http.StatusNotFound, "document not in queue")
+	case errors.Is(err, documents.ErrQueueEmpty):
+		writeError(w, http.StatusNotFound, "queue is empty")
+	case errors.Is(err, documents.ErrQueueFull):
+		writeError(w, http.StatusConflict, fmt.Sprintf("at most %d documents can be queued", documents.MaxQueueLength))
+	case errors.Is(err, documents.ErrQueueOrder):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusNotFound, "document not found")
+	}
+}
@@ -0,0 +1,118 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+)
+
+// ListRevisions handles GET /api/documents/:id/revisions
+func (h *Handlers) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid document ID")
+		return
+	}
+
+	if we != nil {
+		we.AddString("doc.id", id.String())
+	}
+
+	revisions, err := h.docService.ListRevisions(r.Context(), id)
+	if err != nil {
+		h.writeRevisionError(w, r, err)
+		return
+	}
+
+	if we != nil {
+		we.AddInt("revision.count", len(revisions))
+	}
+
+	writeJSON(w, http.StatusOK, revisions)
+}
+
+// GetRevision handles GET /api/documents/:id/revisions/:revisionId
+func (h *Handlers) GetRevision(w http.ResponseWriter, r *http.Request) {
+	id, revisionID, ok := parseRevisionParams(w, r)
+	if !ok {
+		return
+	}
+
+	revision, err := h.docService.GetRevision(r.Context(), id, revisionID)
+	if err != nil {
+		h.writeRevisionError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, revision)
+}
+
+// RestoreRevision handles POST /api/documents/:id/revisions/:revisionId/restore
+// The current version is kept as a new revision before the old one is restored.
+// Like a content edit, the restored text is queued for processing and the
+// response is 202 Accepted with the pending document.
+func (h *Handlers) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	id, revisionID, ok := parseRevisionParams(w, r)
+	if !ok {
+		return
+	}
+
+	doc, err := h.docService.RestoreRevision(r.Context(), id, revisionID)
+	if err != nil {
+		h.writeRevisionError(w, r, err)
+		return
+	}
+
+	if we != nil {
+		we.AddString("doc.status", string(doc.Status))
+	}
+
+	w.Header().Set("ETag", documentETag(doc.Version))
+	writeJSON(w, http.StatusAccepted, doc)
+}
+
+// parseRevisionParams reads the document and revision IDs from the URL,
+// writing a 400 response if either is invalid
+func parseRevisionParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid document ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	revisionID, err := uuid.Parse(chi.URLParam(r, "revisionId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid revision ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddString("doc.id", id.String())
+		we.AddString("revision.id", revisionID.String())
+	}
+
+	return id, revisionID, true
+}
+
+func (h *Handlers) writeRevisionError(w http.ResponseWriter, r *http.Request, err error) {
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddError(err)
+	}
+
+	switch {
+	case errors.Is(err, documents.ErrRevisionNotFound):
+		writeError(w, http.StatusNotFound, "revision not found")
+	case errors.Is(err, documents.ErrDocumentProcessing):
+		writeError(w, http.StatusConflict, "document is still being processed")
+	default:
+		writeError(w, http.StatusInternalServerError, "failed to load revisions")
+	}
+}
@@ -7,11 +7,16 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/mikepersonal/speed-reader/backend/internal/account"
 	"github.com/mikepersonal/speed-reader/backend/internal/auth"
 	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/goals"
+	"github.com/mikepersonal/speed-reader/backend/internal/library"
 	"github.com/mikepersonal/speed-reader/backend/internal/logging"
 	"github.com/mikepersonal/speed-reader/backend/internal/settings"
 	"github.com/mikepersonal/speed-reader/backend/internal/sharing"
+	"github.com/mikepersonal/speed-reader/backend/internal/stats"
+	"github.com/mikepersonal/speed-reader/backend/internal/webfetch"
 	"golang.org/x/exp/slog"
 )
 
@@ -21,6 +26,11 @@ type RouterDeps struct {
 	AuthService     *auth.Service
 	SharingService  *sharing.Service
 	SettingsService *settings.Service
+	GoalService     *goals.Service
+	LibraryService  *library.Service
+	StatsService    *stats.Service
+	AccountService  *account.Service
+	Fetcher         *webfetch.Fetcher
 	FrontendURL     string
 	SecureCookie    bool
 	Logger          *slog.Logger
@@ -39,16 +49,20 @@ func NewRouter(deps *RouterDeps) *chi.Mux {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:5173", "http://localhost:3000", deps.FrontendURL},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "X-CSRF-Token"},
+		ExposedHeaders:   []string{"Content-Disposition", "ETag", "Link", "Location", "X-Next-Cursor"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
 	// Handlers
-	docHandlers := NewHandlers(deps.DocService, deps.SharingService, deps.Logger, deps.Sanitizer)
+	docHandlers := NewHandlers(deps.DocService, deps.SharingService, deps.Fetcher, deps.Logger, deps.Sanitizer)
 	authHandlers := auth.NewHandlers(deps.AuthService, deps.FrontendURL, deps.SecureCookie)
-	settingsHandlers := settings.NewHandlers(deps.SettingsService, deps.Logger, deps.Sanitizer)
+	settingsHandlers := settings.NewHandlers(deps.SettingsService, deps.GoalService, deps.Logger, deps.Sanitizer)
+	goalHandlers := goals.NewHandlers(deps.GoalService, deps.Logger, deps.Sanitizer)
+	libraryHandlers := library.NewHandlers(deps.LibraryService, deps.Logger, deps.Sanitizer)
+	statsHandlers := stats.NewHandlers(deps.StatsService, deps.Logger, deps.Sanitizer)
+	accountHandlers := account.NewHandlers(deps.AccountService, deps.Logger, deps.Sanitizer)
 
 	// Health check endpoint (outside /api for simplicity)
 	r.Get("/api/health", func(w http.ResponseWriter, r *http.Request) {
@@ -105,24 +119,152 @@ func NewRouter(deps *RouterDeps) *chi.Mux {
 				EntryTTL:          10 * time.Minute,
 				SweepInterval:     time.Minute,
 			}))
+
+			// File imports (multipart uploads with a larger body limit)
+			r.Group(func(r chi.Router) {
+				r.Use(RequireMultipartContentType)
+				r.Use(ContextAwareMaxUploadSize)
+
+				r.Post("/import", docHandlers.ImportDocument)
+			})
+
+			// Document creation accepts pasted text as JSON or a web page as HTML
+			r.Group(func(r chi.Router) {
+				r.Use(RequireContentType("application/json", "text/html"))
+				r.Use(ContextAwareMaxBodySize)
+
+				r.Post("/", docHandlers.CreateDocument)
+			})
+
+			// Batch creation for client sync, allowed an upload-sized body
+			r.Group(func(r chi.Router) {
+				r.Use(RequireJSONContentType)
+				r.Use(ContextAwareMaxUploadSize)
+
+				r.Post("/batch", docHandlers.CreateDocumentsBatch)
+			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(RequireJSONContentType)
+				r.Use(ContextAwareMaxBodySize) // Apply body size limit after auth so we know user type
+
+				r.Get("/", docHandlers.ListDocuments)
+				r.Get("/search", docHandlers.SearchDocuments)
+				r.Post("/import-url", docHandlers.ImportURL)
+				r.Get("/{id}", docHandlers.GetDocument)
+				r.Get("/{id}/status", docHandlers.GetDocumentStatus)
+				r.Put("/{id}", docHandlers.UpdateDocument)
+				r.Delete("/{id}", docHandlers.DeleteDocument)
+				r.Get("/{id}/tokens", docHandlers.GetTokens)
+				r.Get("/{id}/find", docHandlers.FindInDocument)
+				r.Get("/{id}/content", docHandlers.GetDocumentContent)
+				r.Get("/{id}/reading-state", docHandlers.GetReadingState)
+				r.Put("/{id}/reading-state", docHandlers.UpdateReadingState)
+
+				// Bookmarks and highlights
+				r.Get("/{id}/highlights", docHandlers.ListHighlights)
+				r.Post("/{id}/highlights", docHandlers.CreateHighlight)
+				r.Put("/{id}/highlights/{highlightId}", docHandlers.UpdateHighlight)
+				r.Delete("/{id}/highlights/{highlightId}", docHandlers.DeleteHighlight)
+
+				// Revision history
+				r.Get("/{id}/revisions", docHandlers.ListRevisions)
+				r.Get("/{id}/revisions/{revisionId}", docHandlers.GetRevision)
+				r.Post("/{id}/revisions/{revisionId}/restore", docHandlers.RestoreRevision)
+
+				// Sharing routes
+				r.Get("/{id}/share", docHandlers.GetShareInfo)
+				r.Post("/{id}/share", docHandlers.GenerateShareToken)
+				r.Delete("/{id}/share", docHandlers.RevokeShareToken)
+				r.Put("/{id}/visibility", docHandlers.SetVisibility)
+			})
+		})
+
+		// Trash routes (require auth)
+		r.Route("/trash", func(r chi.Router) {
+			r.Use(auth.RequireAuth(deps.AuthService))
+			r.Use(auth.ValidateCSRF(deps.AuthService))
+			r.Use(ActorRateLimit(RateLimitConfig{
+				RequestsPerMinute: 120,
+				Burst:             40,
+				MaxEntries:        20000,
+				EntryTTL:          10 * time.Minute,
+				SweepInterval:     time.Minute,
+			}))
 			r.Use(RequireJSONContentType)
-			r.Use(ContextAwareMaxBodySize) // Apply body size limit after auth so we know user type
-
-			r.Get("/", docHandlers.ListDocuments)
-			r.Post("/", docHandlers.CreateDocument)
-			r.Get("/{id}", docHandlers.GetDocument)
-			r.Put("/{id}", docHandlers.UpdateDocument)
-			r.Delete("/{id}", docHandlers.DeleteDocument)
-			r.Get("/{id}/tokens", docHandlers.GetTokens)
-			r.Get("/{id}/content", docHandlers.GetDocumentContent)
-			r.Get("/{id}/reading-state", docHandlers.GetReadingState)
-			r.Put("/{id}/reading-state", docHandlers.UpdateReadingState)
-
-			// Sharing routes
-			r.Get("/{id}/share", docHandlers.GetShareInfo)
-			r.Post("/{id}/share", docHandlers.GenerateShareToken)
-			r.Delete("/{id}/share", docHandlers.RevokeShareToken)
-			r.Put("/{id}/visibility", docHandlers.SetVisibility)
+			r.Use(ContextAwareMaxBodySize)
+
+			r.Get("/", docHandlers.ListTrash)
+			r.Delete("/", docHandlers.EmptyTrash)
+			r.Post("/{id}/restore", docHandlers.RestoreFromTrash)
+			r.Delete("/{id}", docHandlers.DeleteFromTrash)
+		})
+
+		// Reading queue (requires auth)
+		r.Route("/queue", func(r chi.Router) {
+			r.Use(auth.RequireAuth(deps.AuthService))
+			r.Use(auth.ValidateCSRF(deps.AuthService))
+			r.Use(ActorRateLimit(RateLimitConfig{
+				RequestsPerMinute: 120,
+				Burst:             40,
+				MaxEntries:        20000,
+				EntryTTL:          10 * time.Minute,
+				SweepInterval:     time.Minute,
+			}))
+			r.Use(RequireJSONContentType)
+			r.Use(ContextAwareMaxBodySize)
+
+			r.Get("/", docHandlers.ListQueue)
+			r.Post("/", docHandlers.AddToQueue)
+			r.Put("/", docHandlers.ReorderQueue)
+			r.Get("/next", docHandlers.GetQueueNext)
+			r.Post("/pop", docHandlers.PopQueueNext)
+			r.Delete("/{id}", docHandlers.RemoveFromQueue)
+		})
+
+		// Incremental sync (requires auth)
+		r.Route("/sync", func(r chi.Router) {
+			r.Use(auth.RequireAuth(deps.AuthService))
+			r.Use(auth.ValidateCSRF(deps.AuthService))
+			r.Use(ActorRateLimit(RateLimitConfig{
+				RequestsPerMinute: 120,
+				Burst:             40,
+				MaxEntries:        20000,
+				EntryTTL:          10 * time.Minute,
+				SweepInterval:     time.Minute,
+			}))
+
+			r.Get("/changes", docHandlers.GetSyncChanges)
+		})
+
+		// Reading history (requires auth)
+		r.Route("/reading-sessions", func(r chi.Router) {
+			r.Use(auth.RequireAuth(deps.AuthService))
+			r.Use(auth.ValidateCSRF(deps.AuthService))
+			r.Use(ActorRateLimit(RateLimitConfig{
+				RequestsPerMinute: 60,
+				Burst:             20,
+				MaxEntries:        20000,
+				EntryTTL:          10 * time.Minute,
+				SweepInterval:     time.Minute,
+			}))
+
+			r.Get("/", docHandlers.ListReadingSessions)
+		})
+
+		// Reading statistics (require auth)
+		r.Route("/stats", func(r chi.Router) {
+			r.Use(auth.RequireAuth(deps.AuthService))
+			r.Use(auth.ValidateCSRF(deps.AuthService))
+			r.Use(ActorRateLimit(RateLimitConfig{
+				RequestsPerMinute: 60,
+				Burst:             20,
+				MaxEntries:        20000,
+				EntryTTL:          10 * time.Minute,
+				SweepInterval:     time.Minute,
+			}))
+
+			r.Get("/", statsHandlers.GetStats)
 		})
 
 		// Shared document route (no auth required)
@@ -137,6 +279,40 @@ func NewRouter(deps *RouterDeps) *chi.Mux {
 
 			r.Get("/{token}", docHandlers.GetSharedDocument)
 			r.Get("/{token}/tokens", docHandlers.GetSharedDocumentTokens)
+			r.Get("/{token}/find", docHandlers.FindInSharedDocument)
+		})
+
+		// Tag and collection routes (require auth)
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireAuth(deps.AuthService))
+			r.Use(auth.ValidateCSRF(deps.AuthService))
+			r.Use(ActorRateLimit(RateLimitConfig{
+				RequestsPerMinute: 120,
+				Burst:             40,
+				MaxEntries:        20000,
+				EntryTTL:          10 * time.Minute,
+				SweepInterval:     time.Minute,
+			}))
+			r.Use(RequireJSONContentType)
+			r.Use(ContextAwareMaxBodySize)
+
+			r.Route("/tags", func(r chi.Router) {
+				r.Get("/", libraryHandlers.ListTags)
+				r.Post("/", libraryHandlers.CreateTag)
+				r.Post("/bulk", libraryHandlers.BulkTag)
+				r.Put("/{id}", libraryHandlers.UpdateTag)
+				r.Delete("/{id}", libraryHandlers.DeleteTag)
+			})
+
+			r.Route("/collections", func(r chi.Router) {
+				r.Get("/", libraryHandlers.ListCollections)
+				r.Post("/", libraryHandlers.CreateCollection)
+				r.Get("/{id}", libraryHandlers.GetCollection)
+				r.Put("/{id}", libraryHandlers.UpdateCollection)
+				r.Delete("/{id}", libraryHandlers.DeleteCollection)
+				r.Post("/{id}/documents", libraryHandlers.AddToCollection)
+				r.Delete("/{id}/documents/{docId}", libraryHandlers.RemoveFromCollection)
+			})
 		})
 
 		// Settings routes (require auth)
@@ -153,6 +329,45 @@ func NewRouter(deps *RouterDeps) *chi.Mux {
 			r.Get("/", settingsHandlers.GetSettings)
 			r.Put("/", settingsHandlers.UpdateSettings)
 		})
+
+		// Reading goal routes (require auth)
+		r.Route("/goals", func(r chi.Router) {
+			r.Use(auth.RequireAuth(deps.AuthService))
+			r.Use(auth.ValidateCSRF(deps.AuthService))
+			r.Use(ActorRateLimit(RateLimitConfig{
+				RequestsPerMinute: 60,
+				Burst:             20,
+				MaxEntries:        20000,
+				EntryTTL:          10 * time.Minute,
+				SweepInterval:     time.Minute,
+			}))
+			r.Get("/", goalHandlers.GetGoals)
+			r.Put("/", goalHandlers.SetGoal)
+			r.Delete("/{id}", goalHandlers.DeleteGoal)
+		})
+
+		// Account export and import (require auth)
+		r.Route("/account", func(r chi.Router) {
+			r.Use(auth.RequireAuth(deps.AuthService))
+			r.Use(auth.ValidateCSRF(deps.AuthService))
+			r.Use(ActorRateLimit(RateLimitConfig{
+				RequestsPerMinute: 10,
+				Burst:             5,
+				MaxEntries:        20000,
+				EntryTTL:          10 * time.Minute,
+				SweepInterval:     time.Minute,
+			}))
+
+			r.Get("/export", accountHandlers.Export)
+
+			// Imports are multipart uploads of a previous export
+			r.Group(func(r chi.Router) {
+				r.Use(RequireMultipartContentType)
+				r.Use(MaxAccountImportBodySize)
+
+				r.Post("/import", accountHandlers.Import)
+			})
+		})
 	})
 
 	return r
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+)
+
+// SearchDocuments handles GET /api/documents/search?q=
+// Results are ranked, with highlighted snippets and the token index of the first hit
+func (h *Handlers) SearchDocuments(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "search query is required")
+		return
+	}
+	if utf8.RuneCountInString(query) > documents.MaxSearchQueryLength {
+		writeError(w, http.StatusBadRequest, "search query is too long")
+		return
+	}
+
+	limit := documents.DefaultSearchLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = min(n, documents.MaxSearchLimit)
+	}
+
+	results, err := h.docService.SearchDocuments(r.Context(), query, limit)
+	if err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusInternalServerError, "failed to search documents")
+		return
+	}
+
+	if we != nil {
+		we.AddInt("search.query_length", len(query))
+		we.AddInt("search.result_count", len(results))
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+)
+
+// dateLayout is the calendar date format accepted by date filters
+const dateLayout = "2006-01-02"
+
+// ListReadingSessions handles GET /api/reading-sessions
+// Supports ?from= and ?to= as dates (to is inclusive) or RFC 3339 timestamps
+// (to is exclusive), ?tz= naming the IANA zone dates are read in, ?doc= and ?limit=.
+// Sessions are newest first; pass the oldest startedAt as ?to= to page back.
+func (h *Handlers) ListReadingSessions(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	opts, err := parseSessionListOptions(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sessions, err := h.docService.ListReadingSessions(r.Context(), opts)
+	if err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusInternalServerError, "failed to list reading sessions")
+		return
+	}
+
+	if we != nil {
+		we.AddInt("session.count", len(sessions))
+	}
+
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+// parseSessionListOptions reads the reading session filters from a query string
+func parseSessionListOptions(query url.Values) (documents.SessionListOptions, error) {
+	var opts documents.SessionListOptions
+
+	loc := time.UTC
+	if tz := query.Get("tz"); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return opts, errors.New("invalid tz")
+		}
+		loc = l
+	}
+
+	if v := query.Get("from"); v != "" {
+		from, _, err := parseDateParam(v, loc)
+		if err != nil {
+			return opts, errors.New("invalid from")
+		}
+		opts.From = &from
+	}
+	if v := query.Get("to"); v != "" {
+		to, isDate, err := parseDateParam(v, loc)
+		if err != nil {
+			return opts, errors.New("invalid to")
+		}
+		if isDate {
+			// A date includes the whole day
+			to = to.AddDate(0, 0, 1)
+		}
+		opts.To = &to
+	}
+	if opts.From != nil && opts.To != nil && !opts.From.Before(*opts.To) {
+		return opts, errors.New("from must be before to")
+	}
+
+	if v := query.Get("doc"); v != "" {
+		docID, err := uuid.Parse(v)
+		if err != nil {
+			return opts, errors.New("invalid doc")
+		}
+		opts.DocID = &docID
+	}
+
+	opts.Limit = documents.DefaultSessionLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return opts, errors.New("invalid limit")
+		}
+		opts.Limit = min(n, documents.MaxSessionLimit)
+	}
+
+	return opts, nil
+}
+
+// parseDateParam parses a calendar date at midnight in loc, or an RFC 3339
+// timestamp, reporting whether the value was a date
+func parseDateParam(v string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation(dateLayout, v, loc); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+)
+
+// GetSyncChanges handles GET /api/sync/changes?since=cursor
+// Returns the documents created, updated and deleted and the reading positions
+// saved since the cursor from a previous sync. Without since, returns the
+// whole library. A cursor too old to sync from gets 410 Gone, after which the
+// client starts over without one.
+func (h *Handlers) GetSyncChanges(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	since := r.URL.Query().Get("since")
+	changes, err := h.docService.GetChanges(r.Context(), since)
+	if err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		switch {
+		case errors.Is(err, documents.ErrInvalidSyncCursor):
+			writeError(w, http.StatusBadRequest, "invalid since cursor")
+		case errors.Is(err, documents.ErrSyncCursorExpired):
+			writeError(w, http.StatusGone, "since cursor expired, sync again without it")
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to get changes")
+		}
+		return
+	}
+
+	if we != nil {
+		we.AddBool("sync.full", since == "")
+		we.AddInt("sync.created", len(changes.Created))
+		we.AddInt("sync.updated", len(changes.Updated))
+		we.AddInt("sync.deleted", len(changes.Deleted))
+		we.AddInt("sync.progress", len(changes.Progress))
+	}
+
+	writeJSON(w, http.StatusOK, changes)
+}
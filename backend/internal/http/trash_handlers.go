@@ -0,0 +1,103 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+)
+
+// EmptyTrashResponse reports how many documents were permanently deleted
+type EmptyTrashResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// ListTrash handles GET /api/trash
+func (h *Handlers) ListTrash(w http.ResponseWriter, r *http.Request) {
+	docs, err := h.docService.ListTrash(r.Context())
+	if err != nil {
+		if we := logging.WideEventFromContext(r.Context()); we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusInternalServerError, "failed to list trash")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, docs)
+}
+
+// RestoreFromTrash handles POST /api/trash/:id/restore
+func (h *Handlers) RestoreFromTrash(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid document ID")
+		return
+	}
+
+	if err := h.docService.RestoreDocument(r.Context(), id); err != nil {
+		writeTrashError(w, r, err, "failed to restore document")
+		return
+	}
+
+	doc, err := h.docService.GetDocument(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "document not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// DeleteFromTrash handles DELETE /api/trash/:id
+func (h *Handlers) DeleteFromTrash(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid document ID")
+		return
+	}
+
+	if err := h.docService.DeleteFromTrash(r.Context(), id); err != nil {
+		writeTrashError(w, r, err, "failed to delete document")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EmptyTrash handles DELETE /api/trash
+func (h *Handlers) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	deleted, err := h.docService.EmptyTrash(r.Context())
+	if we != nil {
+		we.AddInt("trash.deleted_count", deleted)
+	}
+	if err != nil && deleted == 0 {
+		if we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusInternalServerError, "failed to empty trash")
+		return
+	}
+	if err != nil && we != nil {
+		// The documents are gone; only some chunk files were left behind
+		we.AddError(err)
+	}
+
+	writeJSON(w, http.StatusOK, EmptyTrashResponse{Deleted: deleted})
+}
+
+// writeTrashError maps trash errors to responses
+func writeTrashError(w http.ResponseWriter, r *http.Request, err error, internalMessage string) {
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddError(err)
+	}
+	if errors.Is(err, documents.ErrNotInTrash) {
+		writeError(w, http.StatusNotFound, "document not found in trash")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, internalMessage)
+}
@@ -0,0 +1,88 @@
+// Package language guesses the natural language of a text from the function
+// words it uses. It is deliberately small: it only needs to be right often
+// enough to choose stemming rules for search and reading estimates.
+package language
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// sampleWords is how many words from the start of a text are examined
+	sampleWords = 5000
+
+	// minHits is the fewest stopword matches needed before guessing at all
+	minHits = 5
+)
+
+// stopwords lists very frequent function words per ISO 639-1 code. Words that
+// are common to several languages are left out where they'd blur the signal.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "that", "it", "was", "for", "with", "he", "she", "they", "this", "have", "from", "which", "you", "are", "were", "would", "been", "what", "there"},
+	"fr": {"le", "les", "et", "des", "est", "une", "du", "dans", "qui", "pas", "pour", "sur", "que", "au", "avec", "il", "elle", "sont", "cette", "mais", "nous", "vous", "leur", "aux"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "ein", "eine", "zu", "den", "mit", "sich", "auf", "dem", "auch", "es", "ich", "wir", "sie", "wird", "nach", "oder", "aber", "wenn"},
+	"es": {"el", "los", "las", "y", "del", "que", "en", "una", "por", "con", "para", "es", "se", "su", "como", "pero", "sus", "fue", "muy", "este", "esta", "cuando", "donde", "también"},
+	"it": {"il", "di", "che", "è", "della", "per", "gli", "una", "sono", "non", "con", "del", "nel", "alla", "anche", "come", "più", "questo", "delle", "dei", "ma", "lo", "ha", "nella"},
+	"pt": {"o", "os", "e", "do", "da", "que", "em", "um", "uma", "não", "para", "com", "dos", "das", "se", "ao", "mais", "como", "mas", "foi", "ele", "ela", "são", "está"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "zijn", "voor", "met", "die", "ook", "maar", "aan", "om", "er", "bij", "wordt", "naar", "heeft", "hij"},
+	"sv": {"och", "att", "det", "som", "är", "på", "för", "med", "den", "till", "inte", "av", "har", "ett", "om", "jag", "han", "hon", "var", "men", "sig", "från", "kan", "vi"},
+	"ru": {"и", "в", "не", "на", "что", "с", "по", "это", "как", "он", "она", "но", "к", "из", "у", "за", "от", "так", "же", "для", "был", "была", "они", "его"},
+}
+
+// index maps each stopword to the languages it belongs to
+var index = func() map[string][]string {
+	idx := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, w := range words {
+			idx[w] = append(idx[w], lang)
+		}
+	}
+	return idx
+}()
+
+// Supported reports whether code is a language Detect can return
+func Supported(code string) bool {
+	_, ok := stopwords[code]
+	return ok
+}
+
+// Detect returns the ISO 639-1 code of the most likely language of text, or
+// "" when the text is too short or too ambiguous to tell
+func Detect(text string) string {
+	scores := make(map[string]int)
+	total, words := 0, 0
+
+	for _, field := range strings.FieldsFunc(text, isSeparator) {
+		if words == sampleWords {
+			break
+		}
+		words++
+
+		for _, lang := range index[strings.ToLower(field)] {
+			scores[lang]++
+			total++
+		}
+	}
+
+	best, bestScore, runnerUp := "", 0, 0
+	for lang, score := range scores {
+		switch {
+		case score > bestScore || (score == bestScore && lang < best):
+			runnerUp = bestScore
+			best, bestScore = lang, score
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+
+	// Require a clear winner: enough evidence, and well ahead of the next language
+	if bestScore < minHits || bestScore*4 < total || bestScore*2 < runnerUp*3 {
+		return ""
+	}
+	return best
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && r != '\''
+}
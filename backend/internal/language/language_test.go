@@ -0,0 +1,36 @@
+package language
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"english", "It was the best of times, it was the worst of times. They had everything before them, and the rest of the world was waiting for them with what little patience it had.", "en"},
+		{"french", "Le petit prince est une œuvre de langue française. Il est dans cette histoire avec une rose qui est pour lui le centre du monde, et elle ne le sait pas.", "fr"},
+		{"german", "Die Verwandlung ist eine Erzählung von Franz Kafka. Als er eines Morgens erwachte, fand er sich in seinem Bett zu einem Ungeziefer verwandelt, und das ist nicht auch ein Traum.", "de"},
+		{"spanish", "En un lugar de la Mancha, de cuyo nombre no quiero acordarme, vivía un hidalgo de los de lanza en astillero. Era muy pobre, pero su casa era también grande y el campo era para él.", "es"},
+		{"russian", "Все счастливые семьи похожи друг на друга, и каждая несчастливая семья несчастлива по-своему. Он знал, что это так, но она не могла понять, как это было для него.", "ru"},
+		{"too short", "Hello world", ""},
+		{"no words", "1234 5678 !!!", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSupported(t *testing.T) {
+	if !Supported("en") || !Supported("ru") {
+		t.Error("expected en and ru to be supported")
+	}
+	if Supported("xx") || Supported("") {
+		t.Error("expected unknown codes to be unsupported")
+	}
+}
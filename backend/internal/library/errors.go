@@ -0,0 +1,30 @@
+package library
+
+import "errors"
+
+var (
+	// ErrTagNotFound indicates the tag doesn't exist or belongs to another user.
+	ErrTagNotFound = errors.New("tag not found")
+
+	// ErrCollectionNotFound indicates the collection doesn't exist or belongs to another user.
+	ErrCollectionNotFound = errors.New("collection not found")
+
+	// ErrDocumentNotFound indicates a referenced document doesn't exist or belongs to another user.
+	ErrDocumentNotFound = errors.New("document not found")
+
+	// ErrDuplicateName indicates the user already has a tag or collection with that name.
+	ErrDuplicateName = errors.New("name already in use")
+)
+
+// ValidationError represents an invalid tag or collection payload.
+type ValidationError struct {
+	message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.message
+}
+
+func newValidationError(message string) *ValidationError {
+	return &ValidationError{message: message}
+}
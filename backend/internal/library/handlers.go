@@ -0,0 +1,370 @@
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+	"golang.org/x/exp/slog"
+)
+
+// Handlers contains HTTP handlers for tags and collections
+type Handlers struct {
+	service   *Service
+	logger    *slog.Logger
+	sanitizer *logging.Sanitizer
+}
+
+// NewHandlers creates a new library Handlers instance
+func NewHandlers(service *Service, logger *slog.Logger, sanitizer *logging.Sanitizer) *Handlers {
+	return &Handlers{
+		service:   service,
+		logger:    logger,
+		sanitizer: sanitizer,
+	}
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
+
+func mapLibraryError(err error, internalMessage string) (status int, message string) {
+	switch {
+	case errors.Is(err, ErrTagNotFound):
+		return http.StatusNotFound, "tag not found"
+	case errors.Is(err, ErrCollectionNotFound):
+		return http.StatusNotFound, "collection not found"
+	case errors.Is(err, ErrDocumentNotFound):
+		return http.StatusNotFound, "document not found"
+	case errors.Is(err, ErrDuplicateName):
+		return http.StatusConflict, "name already in use"
+	}
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest, validationErr.Error()
+	}
+
+	return http.StatusInternalServerError, internalMessage
+}
+
+// userFromRequest returns the authenticated user, writing a 401 if there isn't one
+func (h *Handlers) userFromRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "not authenticated")
+		return uuid.Nil, false
+	}
+
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddString("user.id", h.sanitizer.UserID(userID.String()))
+	}
+
+	return userID, true
+}
+
+// parseID reads a UUID URL parameter, writing a 400 if it's invalid
+func parseID(w http.ResponseWriter, r *http.Request, param, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, param))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, message)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// decode reads a JSON request body, writing a 400 if it's malformed
+func decode(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		if we := logging.WideEventFromContext(r.Context()); we != nil {
+			we.AddError(err)
+		}
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return false
+	}
+	return true
+}
+
+// fail logs err on the wide event and writes the mapped error response
+func fail(w http.ResponseWriter, r *http.Request, err error, internalMessage string) {
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddError(err)
+	}
+	status, message := mapLibraryError(err, internalMessage)
+	writeError(w, status, message)
+}
+
+// ListTags handles GET /api/tags
+func (h *Handlers) ListTags(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	tags, err := h.service.ListTags(r.Context(), userID)
+	if err != nil {
+		fail(w, r, err, "failed to list tags")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tags)
+}
+
+// CreateTag handles POST /api/tags
+func (h *Handlers) CreateTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req TagRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	tag, err := h.service.CreateTag(r.Context(), userID, &req)
+	if err != nil {
+		fail(w, r, err, "failed to create tag")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tag)
+}
+
+// UpdateTag handles PUT /api/tags/:id
+func (h *Handlers) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+	tagID, ok := parseID(w, r, "id", "invalid tag ID")
+	if !ok {
+		return
+	}
+
+	var req TagRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	tag, err := h.service.UpdateTag(r.Context(), userID, tagID, &req)
+	if err != nil {
+		fail(w, r, err, "failed to update tag")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tag)
+}
+
+// DeleteTag handles DELETE /api/tags/:id
+func (h *Handlers) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+	tagID, ok := parseID(w, r, "id", "invalid tag ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTag(r.Context(), userID, tagID); err != nil {
+		fail(w, r, err, "failed to delete tag")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BulkTag handles POST /api/tags/bulk
+func (h *Handlers) BulkTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req BulkTagRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if we := logging.WideEventFromContext(r.Context()); we != nil {
+		we.AddInt("bulk.document_count", len(req.DocumentIDs))
+		we.AddInt("bulk.add_count", len(req.Add))
+		we.AddInt("bulk.remove_count", len(req.Remove))
+	}
+
+	result, err := h.service.BulkTag(r.Context(), userID, &req)
+	if err != nil {
+		fail(w, r, err, "failed to update tags")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// ListCollections handles GET /api/collections
+func (h *Handlers) ListCollections(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	collections, err := h.service.ListCollections(r.Context(), userID)
+	if err != nil {
+		fail(w, r, err, "failed to list collections")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, collections)
+}
+
+// GetCollection handles GET /api/collections/:id
+// The collection's documents are listed with GET /api/documents?collection=:id
+func (h *Handlers) GetCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+	collectionID, ok := parseID(w, r, "id", "invalid collection ID")
+	if !ok {
+		return
+	}
+
+	c, err := h.service.GetCollection(r.Context(), userID, collectionID)
+	if err != nil {
+		fail(w, r, err, "failed to get collection")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+// CreateCollection handles POST /api/collections
+func (h *Handlers) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req CollectionRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	c, err := h.service.CreateCollection(r.Context(), userID, &req)
+	if err != nil {
+		fail(w, r, err, "failed to create collection")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, c)
+}
+
+// UpdateCollection handles PUT /api/collections/:id
+func (h *Handlers) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+	collectionID, ok := parseID(w, r, "id", "invalid collection ID")
+	if !ok {
+		return
+	}
+
+	var req CollectionRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	c, err := h.service.UpdateCollection(r.Context(), userID, collectionID, &req)
+	if err != nil {
+		fail(w, r, err, "failed to update collection")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+// DeleteCollection handles DELETE /api/collections/:id
+func (h *Handlers) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+	collectionID, ok := parseID(w, r, "id", "invalid collection ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteCollection(r.Context(), userID, collectionID); err != nil {
+		fail(w, r, err, "failed to delete collection")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddToCollection handles POST /api/collections/:id/documents
+func (h *Handlers) AddToCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+	collectionID, ok := parseID(w, r, "id", "invalid collection ID")
+	if !ok {
+		return
+	}
+
+	var req CollectionDocumentsRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	result, err := h.service.AddToCollection(r.Context(), userID, collectionID, &req)
+	if err != nil {
+		fail(w, r, err, "failed to add to collection")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// RemoveFromCollection handles DELETE /api/collections/:id/documents/:docId
+func (h *Handlers) RemoveFromCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userFromRequest(w, r)
+	if !ok {
+		return
+	}
+	collectionID, ok := parseID(w, r, "id", "invalid collection ID")
+	if !ok {
+		return
+	}
+	docID, ok := parseID(w, r, "docId", "invalid document ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveFromCollection(r.Context(), userID, collectionID, docID); err != nil {
+		fail(w, r, err, "failed to remove from collection")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
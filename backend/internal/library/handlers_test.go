@@ -0,0 +1,28 @@
+package library
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMapLibraryError(t *testing.T) {
+	tests := []struct {
+		err     error
+		status  int
+		message string
+	}{
+		{fmt.Errorf("lookup: %w", ErrTagNotFound), 404, "tag not found"},
+		{ErrCollectionNotFound, 404, "collection not found"},
+		{ErrDocumentNotFound, 404, "document not found"},
+		{ErrDuplicateName, 409, "name already in use"},
+		{newValidationError("name is too long"), 400, "name is too long"},
+		{fmt.Errorf("boom"), 500, "fallback"},
+	}
+
+	for _, tt := range tests {
+		status, message := mapLibraryError(tt.err, "fallback")
+		if status != tt.status || message != tt.message {
+			t.Errorf("mapLibraryError(%v) = %d %q, want %d %q", tt.err, status, message, tt.status, tt.message)
+		}
+	}
+}
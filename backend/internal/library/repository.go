@@ -0,0 +1,477 @@
+package library
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Repository handles database operations for tags and collections
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a new library repository
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// nullIfEmpty stores empty optional strings as NULL
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// ListTags returns a user's tags in name order with how many documents carry each
+func (r *Repository) ListTags(ctx context.Context, userID uuid.UUID) ([]Tag, error) {
+	query := `
+		SELECT t.id, t.name, COALESCE(t.color, ''), t.created_at, COUNT(d.id)
+		FROM tags t
+		LEFT JOIN document_tags dt ON dt.tag_id = t.id
+		LEFT JOIN documents d ON d.id = dt.doc_id AND d.deleted_at IS NULL
+		WHERE t.user_id = $1
+		GROUP BY t.id
+		ORDER BY LOWER(t.name)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color, &tag.CreatedAt, &tag.DocumentCount); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// GetTag returns one of a user's tags
+func (r *Repository) GetTag(ctx context.Context, userID, tagID uuid.UUID) (*Tag, error) {
+	query := `
+		SELECT t.id, t.name, COALESCE(t.color, ''), t.created_at,
+			   (SELECT COUNT(*) FROM document_tags dt JOIN documents d ON d.id = dt.doc_id
+				WHERE dt.tag_id = t.id AND d.deleted_at IS NULL)
+		FROM tags t
+		WHERE t.id = $1 AND t.user_id = $2
+	`
+
+	tag := &Tag{}
+	err := r.db.QueryRowContext(ctx, query, tagID, userID).Scan(&tag.ID, &tag.Name, &tag.Color, &tag.CreatedAt, &tag.DocumentCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// CreateTag inserts a new tag for a user
+func (r *Repository) CreateTag(ctx context.Context, userID uuid.UUID, name, color string) (*Tag, error) {
+	query := `
+		INSERT INTO tags (user_id, name, color, created_at)
+		VALUES ($1, $2, $3, NOW())
+		RETURNING id, created_at
+	`
+
+	tag := &Tag{Name: name, Color: color}
+	if err := r.db.QueryRowContext(ctx, query, userID, name, nullIfEmpty(color)).Scan(&tag.ID, &tag.CreatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrDuplicateName
+		}
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// UpdateTag saves a tag's name and color
+func (r *Repository) UpdateTag(ctx context.Context, userID uuid.UUID, tag *Tag) error {
+	query := `UPDATE tags SET name = $3, color = $4 WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, tag.ID, userID, tag.Name, nullIfEmpty(tag.Color))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateName
+		}
+		return fmt.Errorf("failed to update tag: %w", err)
+	}
+
+	return requireRow(result, ErrTagNotFound)
+}
+
+// DeleteTag removes a tag from a user's library and from every document
+func (r *Repository) DeleteTag(ctx context.Context, userID, tagID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Touch the tagged documents first, as deleting the tag drops their links
+	touch := `
+		UPDATE documents SET updated_at = NOW()
+		WHERE id IN (
+			SELECT dt.doc_id FROM document_tags dt
+			JOIN tags t ON t.id = dt.tag_id
+			WHERE t.id = $1 AND t.user_id = $2
+		)
+	`
+	if _, err := tx.ExecContext(ctx, touch, tagID, userID); err != nil {
+		return fmt.Errorf("failed to touch tagged documents: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = $1 AND user_id = $2`, tagID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	if err := requireRow(result, ErrTagNotFound); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tag deletion: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyTags adds and removes tags across documents in one transaction. Every
+// document and tag must belong to the user, otherwise nothing changes.
+func (r *Repository) ApplyTags(ctx context.Context, userID uuid.UUID, docIDs, add, remove []uuid.UUID) (*BulkResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := requireOwned(ctx, tx, "documents", docIDs, userID, ErrDocumentNotFound); err != nil {
+		return nil, err
+	}
+	tagIDs := append(append([]uuid.UUID{}, add...), remove...)
+	if err := requireOwned(ctx, tx, "tags", tagIDs, userID, ErrTagNotFound); err != nil {
+		return nil, err
+	}
+
+	res := &BulkResult{}
+	if len(add) > 0 {
+		insert := `
+			INSERT INTO document_tags (doc_id, tag_id, created_at)
+			SELECT d, t, NOW()
+			FROM UNNEST($1::uuid[]) AS d, UNNEST($2::uuid[]) AS t
+			ON CONFLICT DO NOTHING
+		`
+		result, err := tx.ExecContext(ctx, insert, uuidArray(docIDs), uuidArray(add))
+		if err != nil {
+			return nil, fmt.Errorf("failed to add tags: %w", err)
+		}
+		n, _ := result.RowsAffected()
+		res.Added = int(n)
+	}
+
+	if len(remove) > 0 {
+		result, err := tx.ExecContext(ctx, `DELETE FROM document_tags WHERE doc_id = ANY($1::uuid[]) AND tag_id = ANY($2::uuid[])`,
+			uuidArray(docIDs), uuidArray(remove))
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove tags: %w", err)
+		}
+		n, _ := result.RowsAffected()
+		res.Removed = int(n)
+	}
+
+	if res.Added+res.Removed > 0 {
+		if err := touchDocuments(ctx, tx, docIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tag changes: %w", err)
+	}
+
+	return res, nil
+}
+
+// ListCollections returns a user's collections in name order with their sizes
+func (r *Repository) ListCollections(ctx context.Context, userID uuid.UUID) ([]Collection, error) {
+	query := `
+		SELECT c.id, c.name, COALESCE(c.description, ''), c.created_at, c.updated_at, COUNT(d.id)
+		FROM collections c
+		LEFT JOIN collection_documents cd ON cd.collection_id = c.id
+		LEFT JOIN documents d ON d.id = cd.doc_id AND d.deleted_at IS NULL
+		WHERE c.user_id = $1
+		GROUP BY c.id
+		ORDER BY LOWER(c.name)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := []Collection{}
+	for rows.Next() {
+		var c Collection
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt, &c.DocumentCount); err != nil {
+			return nil, fmt.Errorf("failed to scan collection: %w", err)
+		}
+		collections = append(collections, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+// GetCollection returns one of a user's collections
+func (r *Repository) GetCollection(ctx context.Context, userID, collectionID uuid.UUID) (*Collection, error) {
+	query := `
+		SELECT c.id, c.name, COALESCE(c.description, ''), c.created_at, c.updated_at,
+			   (SELECT COUNT(*) FROM collection_documents cd JOIN documents d ON d.id = cd.doc_id
+				WHERE cd.collection_id = c.id AND d.deleted_at IS NULL)
+		FROM collections c
+		WHERE c.id = $1 AND c.user_id = $2
+	`
+
+	c := &Collection{}
+	err := r.db.QueryRowContext(ctx, query, collectionID, userID).Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt, &c.DocumentCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	return c, nil
+}
+
+// CreateCollection inserts a new collection for a user
+func (r *Repository) CreateCollection(ctx context.Context, userID uuid.UUID, name, description string) (*Collection, error) {
+	query := `
+		INSERT INTO collections (user_id, name, description, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+
+	c := &Collection{Name: name, Description: description}
+	if err := r.db.QueryRowContext(ctx, query, userID, name, nullIfEmpty(description)).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrDuplicateName
+		}
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	return c, nil
+}
+
+// UpdateCollection saves a collection's name and description
+func (r *Repository) UpdateCollection(ctx context.Context, userID uuid.UUID, c *Collection) error {
+	query := `
+		UPDATE collections SET name = $3, description = $4, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, c.ID, userID, c.Name, nullIfEmpty(c.Description)).Scan(&c.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrCollectionNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrDuplicateName
+		}
+		return fmt.Errorf("failed to update collection: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCollection removes a collection; its documents are kept
+func (r *Repository) DeleteCollection(ctx context.Context, userID, collectionID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Touch the collected documents first, as deleting the collection drops their links
+	touch := `
+		UPDATE documents SET updated_at = NOW()
+		WHERE id IN (
+			SELECT cd.doc_id FROM collection_documents cd
+			JOIN collections c ON c.id = cd.collection_id
+			WHERE c.id = $1 AND c.user_id = $2
+		)
+	`
+	if _, err := tx.ExecContext(ctx, touch, collectionID, userID); err != nil {
+		return fmt.Errorf("failed to touch collected documents: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM collections WHERE id = $1 AND user_id = $2`, collectionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	if err := requireRow(result, ErrCollectionNotFound); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit collection deletion: %w", err)
+	}
+
+	return nil
+}
+
+// AddToCollection adds documents to a collection, ignoring ones already in it
+func (r *Repository) AddToCollection(ctx context.Context, userID, collectionID uuid.UUID, docIDs []uuid.UUID) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := requireOwned(ctx, tx, "collections", []uuid.UUID{collectionID}, userID, ErrCollectionNotFound); err != nil {
+		return 0, err
+	}
+	if err := requireOwned(ctx, tx, "documents", docIDs, userID, ErrDocumentNotFound); err != nil {
+		return 0, err
+	}
+
+	insert := `
+		INSERT INTO collection_documents (collection_id, doc_id, added_at)
+		SELECT $1, d, NOW() FROM UNNEST($2::uuid[]) AS d
+		ON CONFLICT DO NOTHING
+	`
+	result, err := tx.ExecContext(ctx, insert, collectionID, uuidArray(docIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to add to collection: %w", err)
+	}
+	added, _ := result.RowsAffected()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE collections SET updated_at = NOW() WHERE id = $1`, collectionID); err != nil {
+		return 0, fmt.Errorf("failed to touch collection: %w", err)
+	}
+	if added > 0 {
+		if err := touchDocuments(ctx, tx, docIDs); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit collection changes: %w", err)
+	}
+
+	return int(added), nil
+}
+
+// RemoveFromCollection takes a document out of a collection
+func (r *Repository) RemoveFromCollection(ctx context.Context, userID, collectionID, docID uuid.UUID) error {
+	query := `
+		DELETE FROM collection_documents cd
+		USING collections c
+		WHERE cd.collection_id = c.id AND c.id = $1 AND c.user_id = $2 AND cd.doc_id = $3
+	`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, collectionID, userID, docID)
+	if err != nil {
+		return fmt.Errorf("failed to remove from collection: %w", err)
+	}
+	if err := requireRow(result, ErrDocumentNotFound); err != nil {
+		return err
+	}
+
+	if err := touchDocuments(ctx, tx, []uuid.UUID{docID}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit collection changes: %w", err)
+	}
+
+	return nil
+}
+
+// touchDocuments marks documents as changed, so clients syncing their
+// library pick up the new tags or collections
+func touchDocuments(ctx context.Context, tx *sql.Tx, docIDs []uuid.UUID) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE documents SET updated_at = NOW() WHERE id = ANY($1::uuid[])`, uuidArray(docIDs)); err != nil {
+		return fmt.Errorf("failed to touch documents: %w", err)
+	}
+	return nil
+}
+
+// requireRow maps an update or delete that matched nothing to notFound
+func requireRow(result sql.Result, notFound error) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// requireOwned checks that every ID names a row of table owned by the user.
+// IDs must already be de-duplicated; table is always a constant.
+func requireOwned(ctx context.Context, tx *sql.Tx, table string, ids []uuid.UUID, userID uuid.UUID, notFound error) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM ` + table + ` WHERE id = ANY($1::uuid[]) AND user_id = $2`
+	if table == "documents" {
+		// Documents in the trash can't be tagged or collected
+		query += ` AND deleted_at IS NULL`
+	}
+	if err := tx.QueryRowContext(ctx, query, uuidArray(ids), userID).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check %s ownership: %w", table, err)
+	}
+	if count != len(ids) {
+		return notFound
+	}
+	return nil
+}
+
+// uuidArray converts IDs to a Postgres array parameter
+func uuidArray(ids []uuid.UUID) interface{} {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = id.String()
+	}
+	return pq.Array(s)
+}
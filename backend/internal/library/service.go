@@ -0,0 +1,228 @@
+package library
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// colorPattern accepts CSS hex colors like #3b82f6
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Service handles business logic for tags and collections
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new library service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// ListTags returns the user's tags
+func (s *Service) ListTags(ctx context.Context, userID uuid.UUID) ([]Tag, error) {
+	return s.repo.ListTags(ctx, userID)
+}
+
+// CreateTag adds a tag to the user's library
+func (s *Service) CreateTag(ctx context.Context, userID uuid.UUID, req *TagRequest) (*Tag, error) {
+	if req.Name == nil {
+		return nil, newValidationError("name is required")
+	}
+
+	tag := &Tag{}
+	if err := applyTagRequest(tag, req); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateTag(ctx, userID, tag.Name, tag.Color)
+}
+
+// UpdateTag renames or recolors a tag
+func (s *Service) UpdateTag(ctx context.Context, userID, tagID uuid.UUID, req *TagRequest) (*Tag, error) {
+	tag, err := s.repo.GetTag(ctx, userID, tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyTagRequest(tag, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTag(ctx, userID, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// DeleteTag removes a tag; documents that carried it are kept
+func (s *Service) DeleteTag(ctx context.Context, userID, tagID uuid.UUID) error {
+	return s.repo.DeleteTag(ctx, userID, tagID)
+}
+
+// BulkTag adds and removes tags across several documents at once
+func (s *Service) BulkTag(ctx context.Context, userID uuid.UUID, req *BulkTagRequest) (*BulkResult, error) {
+	docIDs := uniqueIDs(req.DocumentIDs)
+	add := uniqueIDs(req.Add)
+	remove := uniqueIDs(req.Remove)
+
+	if len(docIDs) == 0 {
+		return nil, newValidationError("documentIds is required")
+	}
+	if len(docIDs) > MaxBulkDocuments {
+		return nil, newValidationError("too many documents in one request")
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, newValidationError("add or remove is required")
+	}
+	if len(add) > MaxBulkTags || len(remove) > MaxBulkTags {
+		return nil, newValidationError("too many tags in one request")
+	}
+	for _, id := range add {
+		for _, other := range remove {
+			if id == other {
+				return nil, newValidationError("a tag can't be both added and removed")
+			}
+		}
+	}
+
+	return s.repo.ApplyTags(ctx, userID, docIDs, add, remove)
+}
+
+// ListCollections returns the user's collections
+func (s *Service) ListCollections(ctx context.Context, userID uuid.UUID) ([]Collection, error) {
+	return s.repo.ListCollections(ctx, userID)
+}
+
+// GetCollection returns one of the user's collections
+func (s *Service) GetCollection(ctx context.Context, userID, collectionID uuid.UUID) (*Collection, error) {
+	return s.repo.GetCollection(ctx, userID, collectionID)
+}
+
+// CreateCollection adds a collection to the user's library
+func (s *Service) CreateCollection(ctx context.Context, userID uuid.UUID, req *CollectionRequest) (*Collection, error) {
+	if req.Name == nil {
+		return nil, newValidationError("name is required")
+	}
+
+	c := &Collection{}
+	if err := applyCollectionRequest(c, req); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateCollection(ctx, userID, c.Name, c.Description)
+}
+
+// UpdateCollection renames a collection or changes its description
+func (s *Service) UpdateCollection(ctx context.Context, userID, collectionID uuid.UUID, req *CollectionRequest) (*Collection, error) {
+	c, err := s.repo.GetCollection(ctx, userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyCollectionRequest(c, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateCollection(ctx, userID, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DeleteCollection removes a collection; its documents are kept
+func (s *Service) DeleteCollection(ctx context.Context, userID, collectionID uuid.UUID) error {
+	return s.repo.DeleteCollection(ctx, userID, collectionID)
+}
+
+// AddToCollection adds documents to a collection
+func (s *Service) AddToCollection(ctx context.Context, userID, collectionID uuid.UUID, req *CollectionDocumentsRequest) (*BulkResult, error) {
+	docIDs := uniqueIDs(req.DocumentIDs)
+	if len(docIDs) == 0 {
+		return nil, newValidationError("documentIds is required")
+	}
+	if len(docIDs) > MaxBulkDocuments {
+		return nil, newValidationError("too many documents in one request")
+	}
+
+	added, err := s.repo.AddToCollection(ctx, userID, collectionID, docIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &BulkResult{Added: added}, nil
+}
+
+// RemoveFromCollection takes a document out of a collection
+func (s *Service) RemoveFromCollection(ctx context.Context, userID, collectionID, docID uuid.UUID) error {
+	return s.repo.RemoveFromCollection(ctx, userID, collectionID, docID)
+}
+
+// applyTagRequest validates a tag request and copies the provided fields onto tag
+func applyTagRequest(tag *Tag, req *TagRequest) error {
+	if req.Name != nil {
+		name, err := validateName(*req.Name, MaxTagNameLength)
+		if err != nil {
+			return err
+		}
+		tag.Name = name
+	}
+
+	if req.Color != nil {
+		color := strings.TrimSpace(*req.Color)
+		if color != "" && !colorPattern.MatchString(color) {
+			return newValidationError("color must be a hex color like #3b82f6")
+		}
+		tag.Color = strings.ToLower(color)
+	}
+
+	return nil
+}
+
+// applyCollectionRequest validates a collection request and copies the provided fields onto c
+func applyCollectionRequest(c *Collection, req *CollectionRequest) error {
+	if req.Name != nil {
+		name, err := validateName(*req.Name, MaxCollectionNameLength)
+		if err != nil {
+			return err
+		}
+		c.Name = name
+	}
+
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		if utf8.RuneCountInString(description) > MaxDescriptionLength {
+			return newValidationError("description is too long")
+		}
+		c.Description = description
+	}
+
+	return nil
+}
+
+// validateName trims a tag or collection name and checks its length
+func validateName(name string, maxLength int) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", newValidationError("name cannot be empty")
+	}
+	if utf8.RuneCountInString(name) > maxLength {
+		return "", newValidationError("name is too long")
+	}
+	return name, nil
+}
+
+// uniqueIDs drops duplicate IDs, keeping the first occurrence of each
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,92 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestApplyTagRequest(t *testing.T) {
+	tag := &Tag{Name: "old", Color: "#000000"}
+
+	err := applyTagRequest(tag, &TagRequest{Name: strPtr("  Science   Fiction ")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tag.Name != "Science Fiction" || tag.Color != "#000000" {
+		t.Errorf("expected name normalized and color kept, got %+v", tag)
+	}
+
+	if err := applyTagRequest(tag, &TagRequest{Color: strPtr("#3B82F6")}); err != nil || tag.Color != "#3b82f6" {
+		t.Errorf("expected lowercased color, got %q (%v)", tag.Color, err)
+	}
+	if err := applyTagRequest(tag, &TagRequest{Color: strPtr("")}); err != nil || tag.Color != "" {
+		t.Errorf("expected empty color to clear it, got %q (%v)", tag.Color, err)
+	}
+}
+
+func TestApplyTagRequest_Invalid(t *testing.T) {
+	tests := []*TagRequest{
+		{Name: strPtr("   ")},
+		{Name: strPtr(string(make([]rune, MaxTagNameLength+1)))},
+		{Color: strPtr("blue")},
+		{Color: strPtr("#12345")},
+	}
+
+	for _, req := range tests {
+		var validationErr *ValidationError
+		if err := applyTagRequest(&Tag{}, req); !errors.As(err, &validationErr) {
+			t.Errorf("expected ValidationError for %+v, got %v", req, err)
+		}
+	}
+}
+
+func TestApplyCollectionRequest_DescriptionTooLong(t *testing.T) {
+	long := make([]byte, MaxDescriptionLength+1)
+	for i := range long {
+		long[i] = 'a'
+	}
+
+	err := applyCollectionRequest(&Collection{}, &CollectionRequest{Description: strPtr(string(long))})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+}
+
+func TestBulkTagValidation(t *testing.T) {
+	service := &Service{}
+	doc, tag := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name string
+		req  *BulkTagRequest
+	}{
+		{"no documents", &BulkTagRequest{Add: []uuid.UUID{tag}}},
+		{"no tags", &BulkTagRequest{DocumentIDs: []uuid.UUID{doc}}},
+		{"add and remove same tag", &BulkTagRequest{DocumentIDs: []uuid.UUID{doc}, Add: []uuid.UUID{tag}, Remove: []uuid.UUID{tag}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var validationErr *ValidationError
+			if _, err := service.BulkTag(context.Background(), uuid.New(), tt.req); !errors.As(err, &validationErr) {
+				t.Errorf("expected ValidationError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUniqueIDs(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	got := uniqueIDs([]uuid.UUID{a, b, a, b, a})
+	if len(got) != 2 || got[0] != a || got[1] != b {
+		t.Errorf("uniqueIDs = %v", got)
+	}
+}
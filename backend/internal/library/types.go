@@ -0,0 +1,75 @@
+package library
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxTagNameLength is the longest tag name accepted, in characters
+	MaxTagNameLength = 50
+
+	// MaxCollectionNameLength is the longest collection name accepted, in characters
+	MaxCollectionNameLength = 100
+
+	// MaxDescriptionLength is the longest collection description accepted, in characters
+	MaxDescriptionLength = 1000
+
+	// MaxBulkDocuments caps how many documents a single bulk operation touches
+	MaxBulkDocuments = 500
+
+	// MaxBulkTags caps how many tags a single bulk operation adds or removes
+	MaxBulkTags = 20
+)
+
+// Tag is a user-defined label that can be applied to any number of documents
+type Tag struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	Color         string    `json:"color,omitempty"`
+	DocumentCount int       `json:"documentCount"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// Collection is a named group of documents, such as a reading list
+type Collection struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description,omitempty"`
+	DocumentCount int       `json:"documentCount"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// TagRequest creates or updates a tag. On update, omitted fields are left
+// unchanged and an empty color clears it.
+type TagRequest struct {
+	Name  *string `json:"name,omitempty"`
+	Color *string `json:"color,omitempty"`
+}
+
+// CollectionRequest creates or updates a collection. On update, omitted
+// fields are left unchanged.
+type CollectionRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// BulkTagRequest adds and removes tags across several documents at once
+type BulkTagRequest struct {
+	DocumentIDs []uuid.UUID `json:"documentIds"`
+	Add         []uuid.UUID `json:"add,omitempty"`
+	Remove      []uuid.UUID `json:"remove,omitempty"`
+}
+
+// CollectionDocumentsRequest lists documents to add to a collection
+type CollectionDocumentsRequest struct {
+	DocumentIDs []uuid.UUID `json:"documentIds"`
+}
+
+// BulkResult reports how many document associations were created or removed
+type BulkResult struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+	"github.com/mikepersonal/speed-reader/backend/internal/goals"
 	"github.com/mikepersonal/speed-reader/backend/internal/logging"
 	"golang.org/x/exp/slog"
 )
@@ -13,19 +14,28 @@ import (
 // Handlers contains HTTP handlers for settings
 type Handlers struct {
 	service   *Service
+	goals     *goals.Service
 	logger    *slog.Logger
 	sanitizer *logging.Sanitizer
 }
 
 // NewHandlers creates a new settings Handlers instance
-func NewHandlers(service *Service, logger *slog.Logger, sanitizer *logging.Sanitizer) *Handlers {
+func NewHandlers(service *Service, goalService *goals.Service, logger *slog.Logger, sanitizer *logging.Sanitizer) *Handlers {
 	return &Handlers{
 		service:   service,
+		goals:     goalService,
 		logger:    logger,
 		sanitizer: sanitizer,
 	}
 }
 
+// SettingsResponse is the GET /api/settings payload: the stored preferences
+// and the status of the user's reading goals
+type SettingsResponse struct {
+	*Settings
+	Goals []goals.GoalStatus `json:"goals"`
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error string `json:"error"`
@@ -57,6 +67,7 @@ func mapSettingsError(err error, internalMessage string) (status int, message st
 }
 
 // GetSettings handles GET /api/settings
+// Goal status is included so clients can show progress without a second request
 func (h *Handlers) GetSettings(w http.ResponseWriter, r *http.Request) {
 	we := logging.WideEventFromContext(r.Context())
 
@@ -80,7 +91,16 @@ func (h *Handlers) GetSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, settings)
+	// Goals are secondary here; settings are still returned if they fail
+	goalStatus, err := h.goals.GetStatus(r.Context(), userID)
+	if err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		goalStatus = []goals.GoalStatus{}
+	}
+
+	writeJSON(w, http.StatusOK, SettingsResponse{Settings: settings, Goals: goalStatus})
 }
 
 // UpdateSettings handles PUT /api/settings
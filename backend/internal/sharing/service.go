@@ -59,8 +59,8 @@ func (s *Service) GenerateShareToken(ctx context.Context, docID, userID uuid.UUI
 
 	query := `
 		UPDATE documents
-		SET share_token = $2
-		WHERE id = $1 AND user_id = $3
+		SET share_token = $2, updated_at = NOW()
+		WHERE id = $1 AND user_id = $3 AND deleted_at IS NULL
 		RETURNING visibility
 	`
 
@@ -86,8 +86,8 @@ func (s *Service) RevokeShareToken(ctx context.Context, docID, userID uuid.UUID)
 
 	query := `
 		UPDATE documents
-		SET share_token = NULL
-		WHERE id = $1 AND user_id = $2
+		SET share_token = NULL, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
 	`
 
 	result, err := s.db.ExecContext(ctx, query, docID, userID)
@@ -115,8 +115,8 @@ func (s *Service) SetVisibility(ctx context.Context, docID, userID uuid.UUID, vi
 
 	query := `
 		UPDATE documents
-		SET visibility = $2
-		WHERE id = $1 AND user_id = $3
+		SET visibility = $2, updated_at = NOW()
+		WHERE id = $1 AND user_id = $3 AND deleted_at IS NULL
 		RETURNING share_token
 	`
 
@@ -149,7 +149,7 @@ func (s *Service) GetShareInfo(ctx context.Context, docID, userID uuid.UUID) (*S
 	query := `
 		SELECT share_token, visibility
 		FROM documents
-		WHERE id = $1 AND user_id = $2
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
 	`
 
 	var shareToken sql.NullString
@@ -180,7 +180,7 @@ func (s *Service) CanAccessDocument(ctx context.Context, docID uuid.UUID, userID
 	query := `
 		SELECT user_id, visibility
 		FROM documents
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var ownerID sql.NullString
@@ -217,7 +217,7 @@ func (s *Service) GetDocumentByShareToken(ctx context.Context, shareToken uuid.U
 		SELECT d.id, d.title, d.token_count, d.chunk_count, d.created_at, u.name
 		FROM documents d
 		JOIN users u ON d.user_id = u.id
-		WHERE d.share_token = $1 AND d.status = 'ready'
+		WHERE d.share_token = $1 AND d.status = 'ready' AND d.deleted_at IS NULL
 	`
 
 	doc := &SharedDocument{}
@@ -236,7 +236,7 @@ func (s *Service) GetDocumentByShareToken(ctx context.Context, shareToken uuid.U
 
 // isOwner checks if a user owns a document
 func (s *Service) isOwner(ctx context.Context, docID, userID uuid.UUID) bool {
-	query := `SELECT 1 FROM documents WHERE id = $1 AND user_id = $2`
+	query := `SELECT 1 FROM documents WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
 	var exists int
 	err := s.db.QueryRowContext(ctx, query, docID, userID).Scan(&exists)
 	return err == nil
@@ -0,0 +1,14 @@
+package stats
+
+// ValidationError represents an invalid stats query.
+type ValidationError struct {
+	message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.message
+}
+
+func newValidationError(message string) *ValidationError {
+	return &ValidationError{message: message}
+}
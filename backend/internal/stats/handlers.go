@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mikepersonal/speed-reader/backend/internal/auth"
+	"github.com/mikepersonal/speed-reader/backend/internal/logging"
+	"golang.org/x/exp/slog"
+)
+
+// Handlers contains HTTP handlers for reading statistics
+type Handlers struct {
+	service   *Service
+	logger    *slog.Logger
+	sanitizer *logging.Sanitizer
+}
+
+// NewHandlers creates a new stats Handlers instance
+func NewHandlers(service *Service, logger *slog.Logger, sanitizer *logging.Sanitizer) *Handlers {
+	return &Handlers{
+		service:   service,
+		logger:    logger,
+		sanitizer: sanitizer,
+	}
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
+
+func mapStatsError(err error, internalMessage string) (status int, message string) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest, validationErr.Error()
+	}
+
+	return http.StatusInternalServerError, internalMessage
+}
+
+// GetStats handles GET /api/stats?range=&tz=
+// range is week, month, year or a number of days like 14d (default 30d);
+// tz is the IANA timezone days are bucketed in (default UTC)
+func (h *Handlers) GetStats(w http.ResponseWriter, r *http.Request) {
+	we := logging.WideEventFromContext(r.Context())
+
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+
+	if we != nil {
+		we.AddString("user.id", h.sanitizer.UserID(userID.String()))
+	}
+
+	query := r.URL.Query()
+	stats, err := h.service.GetStats(r.Context(), userID, query.Get("range"), query.Get("tz"))
+	if err != nil {
+		if we != nil {
+			we.AddError(err)
+		}
+		status, message := mapStatsError(err, "failed to get stats")
+		writeError(w, status, message)
+		return
+	}
+
+	if we != nil {
+		we.AddInt("stats.days", len(stats.Daily))
+		we.AddInt("stats.active_days", stats.Summary.ActiveDays)
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
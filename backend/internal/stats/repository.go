@@ -0,0 +1,133 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikepersonal/speed-reader/backend/internal/documents"
+)
+
+// Repository aggregates reading sessions and reading state
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a new stats repository
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Window is the span of a stats query: [from, to) bucketed by day in timezone
+type Window struct {
+	from, to time.Time
+	timezone string // IANA name, understood by Postgres' AT TIME ZONE
+}
+
+// DailyReading sums a user's sessions per local day, keyed by YYYY-MM-DD
+func (r *Repository) DailyReading(ctx context.Context, userID uuid.UUID, w Window) (map[string]DayStats, error) {
+	query := `
+		SELECT to_char(started_at AT TIME ZONE $2, 'YYYY-MM-DD') AS day,
+			   SUM(words_read), SUM(EXTRACT(EPOCH FROM ended_at - started_at))::bigint, COUNT(*)
+		FROM reading_sessions
+		WHERE user_id = $1 AND started_at >= $3 AND started_at < $4
+		GROUP BY day
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, w.timezone, w.from, w.to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate reading sessions: %w", err)
+	}
+	defer rows.Close()
+
+	days := make(map[string]DayStats)
+	for rows.Next() {
+		var d DayStats
+		if err := rows.Scan(&d.Date, &d.WordsRead, &d.SecondsRead, &d.Sessions); err != nil {
+			return nil, fmt.Errorf("failed to scan daily reading: %w", err)
+		}
+		days[d.Date] = d
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily reading: %w", err)
+	}
+
+	return days, nil
+}
+
+// DailyFinished counts documents finished per local day. A document's finish
+// day is when its reading state last moved, since that's when the reader
+// reached the end.
+func (r *Repository) DailyFinished(ctx context.Context, userID uuid.UUID, w Window) (map[string]int, error) {
+	query := `
+		SELECT to_char(rs.updated_at AT TIME ZONE $2, 'YYYY-MM-DD') AS day, COUNT(*)
+		FROM reading_state rs
+		JOIN documents d ON d.id = rs.doc_id
+		WHERE rs.user_id = $1 AND rs.updated_at >= $3 AND rs.updated_at < $4
+		  AND d.deleted_at IS NULL AND d.token_count > 0
+		  AND rs.token_index::float8 / d.token_count >= $5
+		GROUP BY day
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, w.timezone, w.from, w.to, documents.FinishedThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count finished documents: %w", err)
+	}
+	defer rows.Close()
+
+	finished := make(map[string]int)
+	for rows.Next() {
+		var day string
+		var count int
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan finished documents: %w", err)
+		}
+		finished[day] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating finished documents: %w", err)
+	}
+
+	return finished, nil
+}
+
+// DocumentReading breaks the range down by document, most recently read first
+func (r *Repository) DocumentReading(ctx context.Context, userID uuid.UUID, w Window, limit int) ([]DocumentStats, error) {
+	query := `
+		SELECT d.id, d.title, SUM(s.words_read), SUM(EXTRACT(EPOCH FROM s.ended_at - s.started_at))::bigint,
+			   CASE WHEN d.token_count > 0 THEN LEAST(COALESCE(MAX(rs.token_index), 0)::float8 / d.token_count, 1) ELSE 0 END
+		FROM reading_sessions s
+		JOIN documents d ON d.id = s.doc_id
+		LEFT JOIN reading_state rs ON rs.doc_id = s.doc_id AND rs.user_id = s.user_id
+		WHERE s.user_id = $1 AND s.started_at >= $2 AND s.started_at < $3 AND d.deleted_at IS NULL
+		GROUP BY d.id
+		ORDER BY MAX(s.ended_at) DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, w.from, w.to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate document reading: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []DocumentStats{}
+	for rows.Next() {
+		var d DocumentStats
+		if err := rows.Scan(&d.DocID, &d.Title, &d.WordsRead, &d.SecondsRead, &d.Progress); err != nil {
+			return nil, fmt.Errorf("failed to scan document reading: %w", err)
+		}
+		d.Finished = d.Progress >= documents.FinishedThreshold
+		docs = append(docs, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document reading: %w", err)
+	}
+
+	return docs, nil
+}
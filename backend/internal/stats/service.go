@@ -0,0 +1,141 @@
+package stats
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// dateLayout formats the calendar days stats are bucketed by
+const dateLayout = "2006-01-02"
+
+// Service computes reading statistics
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new stats service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// GetStats returns a user's reading over the last rangeParam days, ending
+// today, with daily buckets in the IANA timezone tz (UTC when empty)
+func (s *Service) GetStats(ctx context.Context, userID uuid.UUID, rangeParam, tz string) (*Stats, error) {
+	days, err := ParseRange(rangeParam)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := LoadTimezone(tz)
+	if err != nil {
+		return nil, err
+	}
+
+	w := NewWindow(time.Now(), days, loc)
+
+	daily, err := s.repo.DailyReading(ctx, userID, w)
+	if err != nil {
+		return nil, err
+	}
+	finished, err := s.repo.DailyFinished(ctx, userID, w)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.repo.DocumentReading(ctx, userID, w, maxDocumentStats)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := buildStats(w, daily, finished)
+	stats.Documents = docs
+	return stats, nil
+}
+
+// LoadTimezone resolves an IANA timezone name, defaulting to UTC
+func LoadTimezone(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.UTC, nil
+	}
+	// "Local" is the server's zone, which means nothing to the client
+	if tz == "Local" {
+		return nil, newValidationError("invalid timezone")
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, newValidationError("invalid timezone")
+	}
+	return loc, nil
+}
+
+// NewWindow spans the last days calendar days in loc, today included
+func NewWindow(now time.Time, days int, loc *time.Location) Window {
+	y, m, d := now.In(loc).Date()
+	return Window{
+		from:     time.Date(y, m, d-days+1, 0, 0, 0, 0, loc),
+		to:       time.Date(y, m, d+1, 0, 0, 0, 0, loc),
+		timezone: loc.String(),
+	}
+}
+
+// buildStats lays the aggregated days out as a continuous series, filling
+// days without reading with zeros, and summarizes it
+func buildStats(w Window, daily map[string]DayStats, finished map[string]int) *Stats {
+	stats := &Stats{
+		From:      w.from.Format(dateLayout),
+		To:        w.to.AddDate(0, 0, -1).Format(dateLayout),
+		Timezone:  w.timezone,
+		Daily:     []DayStats{},
+		Documents: []DocumentStats{},
+	}
+
+	var trendX, trendY []float64
+	for day := w.from; day.Before(w.to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		d := daily[date]
+		d.Date = date
+		d.DocumentsFinished = finished[date]
+		if d.SecondsRead > 0 {
+			d.EffectiveWPM = int(float64(d.WordsRead) * 60 / float64(d.SecondsRead))
+			trendX = append(trendX, float64(len(stats.Daily)))
+			trendY = append(trendY, float64(d.EffectiveWPM))
+			stats.Summary.ActiveDays++
+		}
+
+		stats.Summary.WordsRead += d.WordsRead
+		stats.Summary.SecondsRead += d.SecondsRead
+		stats.Summary.Sessions += d.Sessions
+		stats.Summary.DocumentsFinished += d.DocumentsFinished
+		stats.Daily = append(stats.Daily, d)
+	}
+
+	if stats.Summary.SecondsRead > 0 {
+		stats.Summary.AverageWPM = int(float64(stats.Summary.WordsRead) * 60 / float64(stats.Summary.SecondsRead))
+	}
+	stats.Summary.WPMTrend = math.Round(slope(trendX, trendY)*10) / 10
+
+	return stats
+}
+
+// slope is the least-squares slope of y over x, 0 with fewer than two points
+func slope(x, y []float64) float64 {
+	n := float64(len(x))
+	if len(x) < 2 {
+		return 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+		sumXY += x[i] * y[i]
+		sumXX += x[i] * x[i]
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := map[string]int{
+		"":      DefaultRangeDays,
+		"week":  7,
+		"Month": 30,
+		"year":  365,
+		"14d":   14,
+		"365d":  365,
+	}
+
+	for input, want := range tests {
+		got, err := ParseRange(input)
+		if err != nil {
+			t.Errorf("ParseRange(%q): unexpected error %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseRange(%q) = %d, want %d", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"0d", "366d", "14", "fortnight", "-3d"} {
+		if _, err := ParseRange(input); err == nil {
+			t.Errorf("ParseRange(%q): expected error", input)
+		}
+	}
+}
+
+func TestLoadTimezone(t *testing.T) {
+	if loc, err := LoadTimezone(""); err != nil || loc != time.UTC {
+		t.Errorf("expected UTC by default, got %v, %v", loc, err)
+	}
+	if loc, err := LoadTimezone("Europe/Berlin"); err != nil || loc.String() != "Europe/Berlin" {
+		t.Errorf("expected Europe/Berlin, got %v, %v", loc, err)
+	}
+	for _, tz := range []string{"Local", "Mars/Olympus"} {
+		if _, err := LoadTimezone(tz); err == nil {
+			t.Errorf("expected error for %q", tz)
+		}
+	}
+}
+
+func TestNewWindow_UsesLocalDays(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	// 20:00 UTC on March 1st is already March 2nd in Tokyo
+	now := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+
+	w := NewWindow(now, 7, tokyo)
+	if want := time.Date(2026, 2, 24, 0, 0, 0, 0, tokyo); !w.from.Equal(want) {
+		t.Errorf("expected from %v, got %v", want, w.from)
+	}
+	if want := time.Date(2026, 3, 3, 0, 0, 0, 0, tokyo); !w.to.Equal(want) {
+		t.Errorf("expected to %v, got %v", want, w.to)
+	}
+	if w.timezone != "Asia/Tokyo" {
+		t.Errorf("expected timezone Asia/Tokyo, got %q", w.timezone)
+	}
+}
+
+func TestBuildStats(t *testing.T) {
+	now := time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC)
+	w := NewWindow(now, 5, time.UTC)
+
+	daily := map[string]DayStats{
+		"2026-03-01": {Date: "2026-03-01", WordsRead: 3000, SecondsRead: 900, Sessions: 2},
+		"2026-03-03": {Date: "2026-03-03", WordsRead: 1750, SecondsRead: 300, Sessions: 1},
+		"2026-03-05": {Date: "2026-03-05", WordsRead: 4000, SecondsRead: 600, Sessions: 1},
+	}
+	finished := map[string]int{"2026-03-03": 1}
+
+	stats := buildStats(w, daily, finished)
+
+	if stats.From != "2026-03-01" || stats.To != "2026-03-05" {
+		t.Errorf("expected 2026-03-01 to 2026-03-05, got %s to %s", stats.From, stats.To)
+	}
+	if len(stats.Daily) != 5 {
+		t.Fatalf("expected 5 days, got %d", len(stats.Daily))
+	}
+	if d := stats.Daily[1]; d.Date != "2026-03-02" || d.WordsRead != 0 || d.EffectiveWPM != 0 {
+		t.Errorf("expected an empty day for 2026-03-02, got %+v", d)
+	}
+	if d := stats.Daily[2]; d.EffectiveWPM != 350 || d.DocumentsFinished != 1 {
+		t.Errorf("unexpected 2026-03-03: %+v", d)
+	}
+
+	s := stats.Summary
+	if s.WordsRead != 8750 || s.SecondsRead != 1800 || s.Sessions != 4 || s.DocumentsFinished != 1 || s.ActiveDays != 3 {
+		t.Errorf("unexpected summary: %+v", s)
+	}
+	if s.AverageWPM != 291 {
+		t.Errorf("expected average 291 wpm, got %d", s.AverageWPM)
+	}
+	// 200, 350 and 400 wpm on days 0, 2 and 4
+	if s.WPMTrend != 50 {
+		t.Errorf("expected a trend of +50 wpm/day, got %v", s.WPMTrend)
+	}
+}
+
+func TestSlope_TooFewPoints(t *testing.T) {
+	if got := slope([]float64{1}, []float64{300}); got != 0 {
+		t.Errorf("expected 0 for a single point, got %v", got)
+	}
+}
@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultRangeDays is the window covered when no range is given
+	DefaultRangeDays = 30
+
+	// MaxRangeDays bounds the window of a single stats query
+	MaxRangeDays = 365
+
+	// maxDocumentStats caps the per-document breakdown
+	maxDocumentStats = 50
+)
+
+// namedRanges are the range names accepted besides "<n>d"
+var namedRanges = map[string]int{
+	"week":  7,
+	"month": 30,
+	"year":  365,
+}
+
+// ParseRange converts a range parameter into a number of days: week, month,
+// year, or a count of days like "14d". An empty range is DefaultRangeDays.
+func ParseRange(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return DefaultRangeDays, nil
+	}
+	if days, ok := namedRanges[s]; ok {
+		return days, nil
+	}
+
+	days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+	if err != nil || !strings.HasSuffix(s, "d") || days < 1 || days > MaxRangeDays {
+		return 0, newValidationError("range must be week, month, year or a number of days like 14d, up to 365d")
+	}
+	return days, nil
+}
+
+// DayStats is the reading done on one calendar day in the requested timezone.
+// Sessions count towards the day they started on.
+type DayStats struct {
+	Date              string `json:"date"` // YYYY-MM-DD
+	WordsRead         int    `json:"wordsRead"`
+	SecondsRead       int    `json:"secondsRead"`
+	Sessions          int    `json:"sessions"`
+	DocumentsFinished int    `json:"documentsFinished"`
+	EffectiveWPM      int    `json:"effectiveWpm"` // 0 on days without reading
+}
+
+// DocumentStats is the reading done in one document during the range, along
+// with how much of it has been read overall
+type DocumentStats struct {
+	DocID       uuid.UUID `json:"docId"`
+	Title       string    `json:"title"`
+	WordsRead   int       `json:"wordsRead"`
+	SecondsRead int       `json:"secondsRead"`
+	Progress    float64   `json:"progress"` // 0 to 1
+	Finished    bool      `json:"finished"`
+}
+
+// Summary totals the range
+type Summary struct {
+	WordsRead         int     `json:"wordsRead"`
+	SecondsRead       int     `json:"secondsRead"`
+	Sessions          int     `json:"sessions"`
+	DocumentsFinished int     `json:"documentsFinished"`
+	AverageWPM        int     `json:"averageWpm"`
+	WPMTrend          float64 `json:"wpmTrend"` // change in effective WPM per day
+	ActiveDays        int     `json:"activeDays"`
+}
+
+// Stats is the response of GET /api/stats
+type Stats struct {
+	From      string          `json:"from"` // first day, YYYY-MM-DD
+	To        string          `json:"to"`   // last day, inclusive
+	Timezone  string          `json:"timezone"`
+	Summary   Summary         `json:"summary"`
+	Daily     []DayStats      `json:"daily"`
+	Documents []DocumentStats `json:"documents"`
+}
@@ -24,6 +24,18 @@ func (s *ChunkStore) docPath(docID uuid.UUID) string {
 	return filepath.Join(s.basePath, fmt.Sprintf("doc_%s", docID.String()))
 }
 
+// stagePath returns the directory a document's new chunks are written to
+// before they replace its current ones
+func (s *ChunkStore) stagePath(docID uuid.UUID) string {
+	return filepath.Join(s.basePath, fmt.Sprintf("staged_%s", docID.String()))
+}
+
+// replacedPath returns the directory a document's current chunks are moved
+// to while staged chunks take their place
+func (s *ChunkStore) replacedPath(docID uuid.UUID) string {
+	return filepath.Join(s.basePath, fmt.Sprintf("replaced_%s", docID.String()))
+}
+
 // chunkPath returns the file path for a specific chunk
 func (s *ChunkStore) chunkPath(docID uuid.UUID, chunkIndex int) string {
 	return filepath.Join(s.docPath(docID), fmt.Sprintf("chunk_%d.json", chunkIndex))
@@ -31,7 +43,17 @@ func (s *ChunkStore) chunkPath(docID uuid.UUID, chunkIndex int) string {
 
 // WriteChunk writes a chunk of tokens to disk
 func (s *ChunkStore) WriteChunk(docID uuid.UUID, chunkIndex int, tokens []Token) error {
-	docDir := s.docPath(docID)
+	return writeChunk(s.docPath(docID), chunkIndex, tokens)
+}
+
+// WriteStagedChunk writes a chunk of tokens to a document's staged chunks,
+// which readers don't see until PromoteStaged swaps them in
+func (s *ChunkStore) WriteStagedChunk(docID uuid.UUID, chunkIndex int, tokens []Token) error {
+	return writeChunk(s.stagePath(docID), chunkIndex, tokens)
+}
+
+// writeChunk writes a chunk of tokens to a file in docDir
+func writeChunk(docDir string, chunkIndex int, tokens []Token) error {
 	if err := os.MkdirAll(docDir, 0755); err != nil {
 		return fmt.Errorf("failed to create doc directory: %w", err)
 	}
@@ -46,7 +68,7 @@ func (s *ChunkStore) WriteChunk(docID uuid.UUID, chunkIndex int, tokens []Token)
 		return fmt.Errorf("failed to marshal chunk: %w", err)
 	}
 
-	chunkFile := s.chunkPath(docID, chunkIndex)
+	chunkFile := filepath.Join(docDir, fmt.Sprintf("chunk_%d.json", chunkIndex))
 	if err := os.WriteFile(chunkFile, data, 0644); err != nil {
 		return fmt.Errorf("failed to write chunk file: %w", err)
 	}
@@ -74,11 +96,50 @@ func (s *ChunkStore) ReadChunk(docID uuid.UUID, chunkIndex int) (*Chunk, error)
 	return &chunk, nil
 }
 
-// DeleteDocument removes all chunks for a document
+// PromoteStaged replaces a document's chunks with its staged ones. It does
+// nothing if none are staged, so it is safe to repeat.
+func (s *ChunkStore) PromoteStaged(docID uuid.UUID) error {
+	staged := s.stagePath(docID)
+	if _, err := os.Stat(staged); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to check staged chunks: %w", err)
+	}
+
+	// Move the current chunks aside rather than deleting them first, so
+	// readers only miss them for as long as the second rename takes
+	replaced := s.replacedPath(docID)
+	if err := os.RemoveAll(replaced); err != nil {
+		return fmt.Errorf("failed to delete replaced chunks: %w", err)
+	}
+	if err := os.Rename(s.docPath(docID), replaced); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to move current chunks: %w", err)
+	}
+	if err := os.Rename(staged, s.docPath(docID)); err != nil {
+		return fmt.Errorf("failed to promote staged chunks: %w", err)
+	}
+	if err := os.RemoveAll(replaced); err != nil {
+		return fmt.Errorf("failed to delete replaced chunks: %w", err)
+	}
+
+	return nil
+}
+
+// DiscardStaged removes a document's staged chunks, if any
+func (s *ChunkStore) DiscardStaged(docID uuid.UUID) error {
+	if err := os.RemoveAll(s.stagePath(docID)); err != nil {
+		return fmt.Errorf("failed to delete staged chunks: %w", err)
+	}
+	return nil
+}
+
+// DeleteDocument removes all chunks for a document, including staged ones
 func (s *ChunkStore) DeleteDocument(docID uuid.UUID) error {
-	docDir := s.docPath(docID)
-	if err := os.RemoveAll(docDir); err != nil {
-		return fmt.Errorf("failed to delete document directory: %w", err)
+	for _, dir := range []string{s.docPath(docID), s.stagePath(docID), s.replacedPath(docID)} {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to delete document directory: %w", err)
+		}
 	}
 	return nil
 }
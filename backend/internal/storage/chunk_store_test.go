@@ -137,3 +137,88 @@ func TestChunkStore_DeleteDocument(t *testing.T) {
 		t.Error("document directory should be deleted")
 	}
 }
+
+func TestChunkStore_PromoteStaged(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "chunkstore_test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	store := NewChunkStore(tmpDir)
+	docID := uuid.New()
+
+	if err := store.WriteChunk(docID, 0, []Token{{Text: "old"}}); err != nil {
+		t.Fatalf("WriteChunk failed: %v", err)
+	}
+	if err := store.WriteChunk(docID, 1, []Token{{Text: "older"}}); err != nil {
+		t.Fatalf("WriteChunk failed: %v", err)
+	}
+	if err := store.WriteStagedChunk(docID, 0, []Token{{Text: "new"}}); err != nil {
+		t.Fatalf("WriteStagedChunk failed: %v", err)
+	}
+
+	// Staged chunks are invisible until promoted
+	chunk, err := store.ReadChunk(docID, 0)
+	if err != nil {
+		t.Fatalf("ReadChunk failed: %v", err)
+	}
+	if chunk.Tokens[0].Text != "old" {
+		t.Errorf("expected the current chunk before promoting, got %q", chunk.Tokens[0].Text)
+	}
+
+	if err := store.PromoteStaged(docID); err != nil {
+		t.Fatalf("PromoteStaged failed: %v", err)
+	}
+	chunk, err = store.ReadChunk(docID, 0)
+	if err != nil {
+		t.Fatalf("ReadChunk failed: %v", err)
+	}
+	if chunk.Tokens[0].Text != "new" {
+		t.Errorf("expected the staged chunk after promoting, got %q", chunk.Tokens[0].Text)
+	}
+	if _, err := store.ReadChunk(docID, 1); err == nil {
+		t.Error("expected chunks of the replaced version to be gone")
+	}
+
+	// Promoting again with nothing staged keeps the current chunks
+	if err := store.PromoteStaged(docID); err != nil {
+		t.Fatalf("second PromoteStaged failed: %v", err)
+	}
+	if _, err := store.ReadChunk(docID, 0); err != nil {
+		t.Errorf("expected the promoted chunk to remain, got %v", err)
+	}
+}
+
+func TestChunkStore_DiscardStaged(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "chunkstore_test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	store := NewChunkStore(tmpDir)
+	docID := uuid.New()
+
+	if err := store.WriteChunk(docID, 0, []Token{{Text: "old"}}); err != nil {
+		t.Fatalf("WriteChunk failed: %v", err)
+	}
+	if err := store.WriteStagedChunk(docID, 0, []Token{{Text: "new"}}); err != nil {
+		t.Fatalf("WriteStagedChunk failed: %v", err)
+	}
+
+	if err := store.DiscardStaged(docID); err != nil {
+		t.Fatalf("DiscardStaged failed: %v", err)
+	}
+	if err := store.PromoteStaged(docID); err != nil {
+		t.Fatalf("PromoteStaged failed: %v", err)
+	}
+
+	chunk, err := store.ReadChunk(docID, 0)
+	if err != nil {
+		t.Fatalf("ReadChunk failed: %v", err)
+	}
+	if chunk.Tokens[0].Text != "old" {
+		t.Errorf("expected discarded chunks never to be promoted, got %q", chunk.Tokens[0].Text)
+	}
+}
@@ -0,0 +1,262 @@
+// Package webfetch retrieves remote pages on behalf of users while guarding
+// against server-side request forgery.
+package webfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds the whole fetch, including redirects and reading the body
+	DefaultTimeout = 15 * time.Second
+
+	// DefaultMaxBytes is the largest response body accepted (10MB)
+	DefaultMaxBytes = 10 * 1024 * 1024
+
+	// DefaultMaxRedirects is how many redirects are followed before giving up
+	DefaultMaxRedirects = 5
+
+	// DefaultUserAgent identifies the importer to remote servers
+	DefaultUserAgent = "SpeedReader/1.0 (+https://github.com/mikepersonal/speed-reader)"
+)
+
+var (
+	// ErrInvalidURL indicates a URL that isn't an absolute http(s) URL
+	ErrInvalidURL = errors.New("invalid URL")
+
+	// ErrBlockedAddress indicates the URL resolved to a private, loopback or otherwise internal address
+	ErrBlockedAddress = errors.New("address is not allowed")
+
+	// ErrTooManyRedirects indicates the redirect cap was exceeded
+	ErrTooManyRedirects = errors.New("too many redirects")
+
+	// ErrTooLarge indicates the response body exceeded the size limit
+	ErrTooLarge = errors.New("response too large")
+
+	// ErrUnsupportedContent indicates a response that isn't a web page or PDF
+	ErrUnsupportedContent = errors.New("unsupported content type")
+)
+
+// StatusError reports a non-success response from the remote server
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("remote server returned status %d", e.StatusCode)
+}
+
+// supportedMediaTypes lists the content types that can be imported
+var supportedMediaTypes = map[string]bool{
+	"text/html":             true,
+	"application/xhtml+xml": true,
+	"application/pdf":       true,
+}
+
+// blockedPrefixes are special-purpose ranges not covered by the netip predicates
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, can reach IPv4 internals
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4, can embed internal IPv4
+}
+
+// Config controls fetch limits
+type Config struct {
+	Timeout      time.Duration
+	MaxBytes     int64
+	MaxRedirects int
+	UserAgent    string
+
+	// AllowPrivateNetworks disables the address blocklist. It exists for tests
+	// against local servers and must never be enabled in production.
+	AllowPrivateNetworks bool
+}
+
+// Page is a fetched response body
+type Page struct {
+	URL         string // final URL after redirects
+	ContentType string
+	MediaType   string // ContentType without parameters
+	Body        []byte
+}
+
+// Fetcher retrieves pages with SSRF protections applied to every connection
+type Fetcher struct {
+	client *http.Client
+	config Config
+}
+
+// New creates a Fetcher, filling unset limits with defaults
+func New(config Config) *Fetcher {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = DefaultMaxBytes
+	}
+	if config.MaxRedirects <= 0 {
+		config.MaxRedirects = DefaultMaxRedirects
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = DefaultUserAgent
+	}
+
+	// Addresses are checked in the dialer's Control hook, after DNS resolution
+	// and immediately before connecting, so a hostname can't pass validation
+	// and then rebind to an internal address.
+	dialer := &net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if config.AllowPrivateNetworks {
+				return nil
+			}
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+			}
+			if IsBlockedAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // an environment proxy would bypass the address checks
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > config.MaxRedirects {
+				return ErrTooManyRedirects
+			}
+			return validateURL(req.URL)
+		},
+	}
+
+	return &Fetcher{client: client, config: config}
+}
+
+// Fetch retrieves a web page or PDF. The body is read up to the size limit and
+// the URL is re-validated on every redirect.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	u, err := ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	req.Header.Set("User-Agent", f.config.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/pdf;q=0.9,*/*;q=0.1")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", u.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+	if resp.ContentLength > f.config.MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.config.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %s: %w", u.Host, err)
+	}
+	if int64(len(body)) > f.config.MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !supportedMediaTypes[mediaType] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContent, contentType)
+	}
+
+	return &Page{
+		URL:         resp.Request.URL.String(),
+		ContentType: contentType,
+		MediaType:   mediaType,
+		Body:        body,
+	}, nil
+}
+
+// ParseURL validates a user-supplied URL, accepting only absolute http(s)
+// URLs without embedded credentials
+func ParseURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if err := validateURL(u); err != nil {
+		return nil, err
+	}
+	u.Fragment = ""
+	return u, nil
+}
+
+func validateURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", ErrInvalidURL)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("%w: missing host", ErrInvalidURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials are not allowed", ErrInvalidURL)
+	}
+	return nil
+}
+
+// IsBlockedAddr reports whether an address is loopback, private, link-local,
+// multicast or otherwise not a public unicast address
+func IsBlockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}